@@ -1,6 +1,8 @@
 // Package interfaces provides interfaces for database connections and operations
 package interfaces
 
+import "context"
+
 // Database is a common interface for all database connections
 type Database interface {
 	// Connect establishes a connection to the database
@@ -9,25 +11,48 @@ type Database interface {
 	// Disconnect closes the connection to the database
 	Disconnect() error
 
-	// Query executes a query and returns results
+	// Query executes a query and returns the rows of its first result set
+	// ctx: Context that cancels the query when done
 	// query: SQL query to execute
 	// params: Parameters for the query
-	Query(query string, params ...any) ([]map[string]any, error)
+	Query(ctx context.Context, query string, params ...any) ([]map[string]any, error)
+
+	// QueryResultSets executes a query, such as a batch of several SELECT
+	// statements, and returns the rows of every result set it produces
+	// ctx: Context that cancels the query when done
+	// query: SQL query to execute
+	// params: Parameters for the query
+	QueryResultSets(ctx context.Context, query string, params ...any) ([][]map[string]any, error)
 
 	// Execute runs a query that doesn't return results (INSERT, UPDATE, DELETE)
+	// ctx: Context that cancels the statement when done
 	// query: SQL query to execute
 	// params: Parameters for the query
-	Execute(query string, params ...any) error
+	Execute(ctx context.Context, query string, params ...any) error
 
 	// GetSchema returns database schema information
-	GetSchema() (SchemaInfo, error)
+	GetSchema(ctx context.Context) (SchemaInfo, error)
 
 	// GetTables returns all table names
-	GetTables() ([]string, error)
+	GetTables(ctx context.Context) ([]string, error)
 
-	// GetTableSchema returns column information for a specific table
+	// GetTableSchema returns the columns, keys, indexes and constraints of
+	// a specific table
+	// ctx: Context that cancels the lookup when done
 	// tableName: Name of the table
-	GetTableSchema(tableName string) (TableSchema, error)
+	GetTableSchema(ctx context.Context, tableName string) (TableSchema, error)
+
+	// GetViews returns all view names
+	GetViews(ctx context.Context) ([]string, error)
+
+	// GetRoutines returns all stored procedures and functions
+	GetRoutines(ctx context.Context) ([]RoutineInfo, error)
+
+	// GetObjectDefinition returns the source and parameters of a view,
+	// stored procedure or function
+	// ctx: Context that cancels the lookup when done
+	// name: Name of the object, optionally schema-qualified
+	GetObjectDefinition(ctx context.Context, name string) (ObjectDefinition, error)
 }
 
 // SchemaInfo contains database schema information
@@ -41,11 +66,104 @@ type SchemaInfo struct {
 
 // TableSchema contains table schema information
 type TableSchema struct {
-	// TableName is the name of the table
+	// Schema is the schema the table belongs to (the database on MySQL,
+	// the attached database on SQLite)
+	Schema string
+
+	// TableName is the name of the table, without its schema
 	TableName string
 
 	// Columns contains information about all columns in the table
 	Columns []ColumnInfo
+
+	// PrimaryKey is the table's primary key, or nil if it has none
+	PrimaryKey *KeyInfo
+
+	// ForeignKeys contains the foreign keys declared on the table
+	ForeignKeys []ForeignKeyInfo
+
+	// Indexes contains the indexes of the table, including those backing
+	// the primary key and unique constraints
+	Indexes []IndexInfo
+
+	// Constraints contains the unique and check constraints of the table
+	Constraints []ConstraintInfo
+}
+
+// QualifiedName returns the table name qualified with its schema, if any
+func (t TableSchema) QualifiedName() string {
+	if t.Schema == "" {
+		return t.TableName
+	}
+	return t.Schema + "." + t.TableName
+}
+
+// KeyInfo describes a primary key
+type KeyInfo struct {
+	// Name is the constraint name
+	Name string
+
+	// Columns lists the key columns in key order
+	Columns []string
+}
+
+// ForeignKeyInfo describes a foreign key
+type ForeignKeyInfo struct {
+	// Name is the constraint name
+	Name string
+
+	// Columns lists the referencing columns in key order
+	Columns []string
+
+	// ReferencedTable is the name of the referenced table
+	ReferencedTable string
+
+	// ReferencedColumns lists the referenced columns, matching Columns
+	ReferencedColumns []string
+
+	// OnDelete and OnUpdate are the referential actions, such as CASCADE
+	// or NO ACTION
+	OnDelete string
+	OnUpdate string
+}
+
+// IndexInfo describes an index
+type IndexInfo struct {
+	// Name is the index name
+	Name string
+
+	// Type is the kind of index, such as CLUSTERED or btree
+	Type string
+
+	// Columns lists the key columns or expressions in key order
+	Columns []string
+
+	// IncludedColumns lists the non-key columns stored in the index
+	IncludedColumns []string
+
+	// Unique indicates whether the index enforces unique values
+	Unique bool
+
+	// Primary indicates whether the index backs the primary key
+	Primary bool
+
+	// Filter is the predicate of a filtered or partial index
+	Filter string
+}
+
+// ConstraintInfo describes a unique or check constraint
+type ConstraintInfo struct {
+	// Name is the constraint name
+	Name string
+
+	// Type is UNIQUE or CHECK
+	Type string
+
+	// Columns lists the columns the constraint applies to, if known
+	Columns []string
+
+	// Definition is the check expression of a CHECK constraint
+	Definition string
 }
 
 // ColumnInfo contains information about a database column
@@ -64,4 +182,54 @@ type ColumnInfo struct {
 
 	// DefaultValue is the default value for the column (if any)
 	DefaultValue interface{}
-} 
\ No newline at end of file
+}
+
+// RoutineInfo describes a stored procedure or function
+type RoutineInfo struct {
+	// Schema is the schema the routine belongs to
+	Schema string
+
+	// Name is the routine name
+	Name string
+
+	// Type is PROCEDURE or FUNCTION
+	Type string
+
+	// ReturnType is the result type of a function, or TABLE for a
+	// table-valued function; it is empty for procedures
+	ReturnType string
+}
+
+// ObjectDefinition contains the source of a view, procedure or function
+type ObjectDefinition struct {
+	// Schema is the schema the object belongs to
+	Schema string
+
+	// Name is the object name
+	Name string
+
+	// Type is VIEW, PROCEDURE or FUNCTION
+	Type string
+
+	// ReturnType is the result type of a function
+	ReturnType string
+
+	// Definition is the object's source text, empty when the server does
+	// not disclose it (for example for encrypted modules)
+	Definition string
+
+	// Parameters lists the parameters of a procedure or function in order
+	Parameters []ParameterInfo
+}
+
+// ParameterInfo describes a procedure or function parameter
+type ParameterInfo struct {
+	// Name is the parameter name, empty for unnamed parameters
+	Name string
+
+	// Type is the parameter data type
+	Type string
+
+	// Mode is IN, OUT, INOUT or VARIADIC
+	Mode string
+}
@@ -0,0 +1,407 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Database engines a connection can use
+const (
+	engineSQLServer = "sqlserver"
+	enginePostgres  = "postgres"
+	engineMySQL     = "mysql"
+	engineSQLite    = "sqlite"
+)
+
+// defaultConnectionName names the connection built from the legacy
+// single-database variables (SQL_SERVER, PG_HOST, ...)
+const defaultConnectionName = "default"
+
+// connectionConfig describes how to reach one database
+type connectionConfig struct {
+	// Name identifies the connection in tool arguments
+	Name string
+
+	// Engine is one of the engine* constants
+	Engine string
+
+	// Host, Port, User and Password locate and authenticate against the server
+	Host     string
+	Port     string
+	User     string
+	Password string
+
+	// Database is the database name, or the file path for SQLite
+	Database string
+
+	// SSLMode is the PostgreSQL sslmode or the MySQL tls setting
+	SSLMode string
+
+	// ReadOnly rejects statements that could modify data. Connections are
+	// read-only unless configured otherwise.
+	ReadOnly bool
+
+	// MaxAffectedRows aborts write statements affecting more rows; 0
+	// disables the limit
+	MaxAffectedRows int64
+
+	// MaxRows is the default page size of query results and MaxResultBytes
+	// the size of formatted results after which a page is cut short; 0
+	// disables either limit
+	MaxRows        int64
+	MaxResultBytes int64
+
+	// StatementTimeout cancels statements running longer; 0 disables it
+	StatementTimeout time.Duration
+
+	// BinaryEncoding is how SQL Server binary values are rendered, one of
+	// the binaryEncoding* constants
+	BinaryEncoding string
+
+	// ProcedureAllowList names the stored procedures that may be called,
+	// as "schema.procedure" or "schema.*"; nothing is callable when empty
+	ProcedureAllowList []string
+
+	// ProfileTimeBudget bounds the time the table profiling tool spends on
+	// one table; columns it has not reached by then are left out
+	ProfileTimeBudget time.Duration
+
+	// SchemaPollInterval is how often the schema version is checked to
+	// invalidate the cached schema snapshot; 0 disables the checks
+	SchemaPollInterval time.Duration
+
+	// Masking is the policy for masking personal data in sampled rows and,
+	// when it says so, in query results
+	Masking maskingConfig
+}
+
+// namedConnection is an open database together with the configuration it
+// was opened from
+type namedConnection struct {
+	config connectionConfig
+	db     interfaces.Database
+
+	// schema caches the catalog of the database for the schema search tool
+	schema *schemaCache
+}
+
+// disconnect stops polling the schema of the connection and closes the
+// database
+func (c *namedConnection) disconnect() error {
+	c.schema.close()
+	return c.db.Disconnect()
+}
+
+// connectionRegistry holds the open connections of one tool family
+type connectionRegistry struct {
+	connections map[string]*namedConnection
+	names       []string
+	defaultName string
+
+	// selectable adds a "connection" argument to the family's tools
+	selectable bool
+}
+
+// newConnectionRegistry creates an empty registry. selectable controls
+// whether the tools built on it let callers choose a connection.
+func newConnectionRegistry(selectable bool) *connectionRegistry {
+	return &connectionRegistry{
+		connections: make(map[string]*namedConnection),
+		selectable:  selectable,
+	}
+}
+
+// add registers an open connection; the first one added becomes the default
+func (r *connectionRegistry) add(config connectionConfig, db interfaces.Database) {
+	if _, exists := r.connections[config.Name]; !exists {
+		r.names = append(r.names, config.Name)
+	}
+	r.connections[config.Name] = &namedConnection{config: config, db: db, schema: newSchemaCache(config, db)}
+	if r.defaultName == "" {
+		r.defaultName = config.Name
+	}
+}
+
+// setDefault makes name the connection used when a tool call names none
+func (r *connectionRegistry) setDefault(name string) error {
+	if _, ok := r.connections[name]; !ok {
+		return fmt.Errorf("unknown connection %q", name)
+	}
+	r.defaultName = name
+	return nil
+}
+
+// get returns the named connection, or the default one for an empty name
+func (r *connectionRegistry) get(name string) (*namedConnection, error) {
+	if name == "" {
+		name = r.defaultName
+	}
+
+	conn, ok := r.connections[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection %q, available connections: %s", name, strings.Join(r.names, ", "))
+	}
+
+	return conn, nil
+}
+
+// list returns all connections in configuration order
+func (r *connectionRegistry) list() []*namedConnection {
+	result := make([]*namedConnection, 0, len(r.names))
+	for _, name := range r.names {
+		result = append(result, r.connections[name])
+	}
+	return result
+}
+
+// disconnect closes every connection, returning the first error
+func (r *connectionRegistry) disconnect() error {
+	var first error
+	for _, conn := range r.list() {
+		if err := conn.disconnect(); err != nil && first == nil {
+			first = fmt.Errorf("error closing connection %q: %w", conn.config.Name, err)
+		}
+	}
+	return first
+}
+
+// isEmpty reports whether no connection could be opened
+func (r *connectionRegistry) isEmpty() bool {
+	return len(r.connections) == 0
+}
+
+// fromRequest resolves the connection named by the optional "connection"
+// argument of a tool call
+func (r *connectionRegistry) fromRequest(request mcp.CallToolRequest) (*namedConnection, error) {
+	name, _ := request.Params.Arguments["connection"].(string)
+	return r.get(strings.TrimSpace(name))
+}
+
+// toolOptions returns the tool options that expose connection selection,
+// or nothing when the registry is not selectable
+func (r *connectionRegistry) toolOptions() []mcp.ToolOption {
+	if !r.selectable {
+		return nil
+	}
+
+	return []mcp.ToolOption{
+		mcp.WithString("connection",
+			mcp.Description(fmt.Sprintf("Name of the database connection to use (see sql_list_connections). Defaults to %q", r.defaultName)),
+		),
+	}
+}
+
+// parseEngine normalizes the engine names accepted in configuration
+func parseEngine(engine string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(engine)) {
+	case "", "sqlserver", "sql-server", "mssql":
+		return engineSQLServer, nil
+	case "postgres", "postgresql", "pg":
+		return enginePostgres, nil
+	case "mysql", "mariadb":
+		return engineMySQL, nil
+	case "sqlite", "sqlite3":
+		return engineSQLite, nil
+	default:
+		return "", fmt.Errorf("unsupported engine %q", engine)
+	}
+}
+
+// readOnlyFromEnv reads a read-only flag from the named variable, which
+// defaults to true when unset or not a boolean
+func readOnlyFromEnv(name string) bool {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return true
+	}
+
+	readOnly, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Ignoring invalid value %q for %s, keeping the connection read-only", value, name)
+		return true
+	}
+
+	return readOnly
+}
+
+// binaryEncodingFromEnv reads the encoding of binary values from the named
+// variable, using hex when it is unset or invalid
+func binaryEncodingFromEnv(name string) string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
+	switch value {
+	case "":
+		return binaryEncodingHex
+	case binaryEncodingHex, binaryEncodingBase64:
+		return value
+	}
+
+	log.Printf("Ignoring invalid value %q for %s, using %s", value, name, binaryEncodingHex)
+	return binaryEncodingHex
+}
+
+// listFromEnv reads a comma-separated list from the named variable,
+// leaving out empty entries
+func listFromEnv(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// intFromEnv reads a non-negative integer from the named variable, using
+// defaultValue when it is unset or invalid
+func intFromEnv(name string, defaultValue int64) int64 {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		log.Printf("Ignoring invalid value %q for %s, using %d", value, name, defaultValue)
+		return defaultValue
+	}
+
+	return n
+}
+
+// secondsFromEnv reads a duration given in whole seconds from the named
+// variable, using defaultValue when it is unset or invalid
+func secondsFromEnv(name string, defaultValue time.Duration) time.Duration {
+	return time.Duration(intFromEnv(name, int64(defaultValue/time.Second))) * time.Second
+}
+
+// connectionEnvPrefix returns the prefix of the variables configuring a
+// named connection, e.g. "orders-prod-ro" reads SQL_CONN_ORDERS_PROD_RO_*
+func connectionEnvPrefix(name string) string {
+	var prefix strings.Builder
+	prefix.WriteString("SQL_CONN_")
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			prefix.WriteRune(r)
+		} else {
+			prefix.WriteRune('_')
+		}
+	}
+	prefix.WriteRune('_')
+	return prefix.String()
+}
+
+// namedConnectionConfig reads the configuration of one connection listed
+// in SQL_CONNECTIONS
+func namedConnectionConfig(name string) (connectionConfig, error) {
+	prefix := connectionEnvPrefix(name)
+
+	engine, err := parseEngine(os.Getenv(prefix + "ENGINE"))
+	if err != nil {
+		return connectionConfig{}, fmt.Errorf("connection %q: %w", name, err)
+	}
+
+	return connectionConfig{
+		Name:     name,
+		Engine:   engine,
+		Host:     os.Getenv(prefix + "SERVER"),
+		Port:     os.Getenv(prefix + "PORT"),
+		User:     os.Getenv(prefix + "USER"),
+		Password: os.Getenv(prefix + "PASSWORD"),
+		Database: os.Getenv(prefix + "DATABASE"),
+		SSLMode:  os.Getenv(prefix + "SSLMODE"),
+		ReadOnly: readOnlyFromEnv(prefix + "READ_ONLY"),
+
+		MaxAffectedRows: intFromEnv(prefix+"MAX_AFFECTED_ROWS", defaultMaxAffectedRows),
+		MaxRows:         intFromEnv(prefix+"MAX_ROWS", defaultMaxRows),
+		MaxResultBytes:  intFromEnv(prefix+"MAX_RESULT_BYTES", defaultMaxResultBytes),
+
+		StatementTimeout: secondsFromEnv(prefix+"STATEMENT_TIMEOUT", defaultStatementTimeout),
+		BinaryEncoding:   binaryEncodingFromEnv(prefix + "BINARY_ENCODING"),
+
+		ProcedureAllowList: listFromEnv(prefix + "PROCEDURE_ALLOWLIST"),
+		ProfileTimeBudget:  secondsFromEnv(prefix+"PROFILE_TIME_BUDGET", defaultProfileTimeBudget),
+		SchemaPollInterval: secondsFromEnv(prefix+"SCHEMA_POLL_INTERVAL", defaultSchemaPollInterval),
+
+		Masking: maskingConfigFromEnv(prefix),
+	}, nil
+}
+
+// sqlConnectionConfigs returns the connections of the sql_* tools: every
+// name listed in SQL_CONNECTIONS, or the single SQL_SERVER connection when
+// no names are configured
+func sqlConnectionConfigs() ([]connectionConfig, error) {
+	var configs []connectionConfig
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(os.Getenv("SQL_CONNECTIONS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("connection %q is configured twice", name)
+		}
+		seen[name] = true
+
+		config, err := namedConnectionConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+
+	if len(configs) == 0 {
+		configs = append(configs, sqlServerConfigFromEnv())
+	}
+
+	return configs, nil
+}
+
+// openDatabase creates the backend for config's engine and connects it
+func openDatabase(config connectionConfig) (interfaces.Database, error) {
+	var db interfaces.Database
+	switch config.Engine {
+	case engineSQLServer:
+		db = &sqlServerImpl{config: config}
+	case enginePostgres:
+		db = &postgresImpl{config: config}
+	case engineMySQL:
+		db = &mysqlImpl{config: config}
+	case engineSQLite:
+		db = &sqliteImpl{config: config}
+	default:
+		return nil, fmt.Errorf("unsupported engine %q", config.Engine)
+	}
+
+	if err := db.Connect(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// openConnections opens every configured connection into a registry.
+// Connections that fail to open are logged and left out, so one
+// unreachable database does not disable the others.
+func openConnections(configs []connectionConfig, selectable bool) *connectionRegistry {
+	registry := newConnectionRegistry(selectable)
+
+	for _, config := range configs {
+		db, err := openDatabase(config)
+		if err != nil {
+			log.Printf("Failed to open connection %q: %v", config.Name, err)
+			continue
+		}
+		registry.add(config, db)
+	}
+
+	return registry
+}
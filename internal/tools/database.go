@@ -0,0 +1,779 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/transport"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// valueConverter turns a raw driver value into the value reported to the
+// client, using the column type to decide how bytes should be interpreted
+type valueConverter func(columnType *sql.ColumnType, value any) any
+
+// convertBytesToString is the default valueConverter; it renders []byte
+// values as strings and passes everything else through unchanged
+func convertBytesToString(columnType *sql.ColumnType, value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+// scanRows reads all remaining rows into a slice of column-name keyed maps
+func scanRows(rows *sql.Rows) ([]map[string]any, error) {
+	return scanRowsWith(rows, convertBytesToString)
+}
+
+// scanRowsWith reads all remaining rows into a slice of column-name keyed
+// maps, passing every value through convert
+func scanRowsWith(rows *sql.Rows, convert valueConverter) ([]map[string]any, error) {
+	result, err := scanRowsPage(rows, convert, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return result.maps(), nil
+}
+
+// scanStrings reads a single string column from all remaining rows
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("error scanning value: %w", err)
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return values, nil
+}
+
+// qualifyTableName joins a schema and table name into the form accepted by
+// splitTableName, quoting the parts that would otherwise be misread
+func qualifyTableName(schemaName string, relName string) string {
+	quote := func(identifier string) string {
+		if strings.ContainsAny(identifier, ".\"`[]") {
+			return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+		}
+		return identifier
+	}
+
+	if schemaName == "" {
+		return quote(relName)
+	}
+	return quote(schemaName) + "." + quote(relName)
+}
+
+// withTableArguments appends the table_name and schema arguments of the
+// tools that look up a table to a tool's options
+func withTableArguments(description string, options ...mcp.ToolOption) []mcp.ToolOption {
+	return append(options,
+		mcp.WithString("table_name",
+			mcp.Required(),
+			mcp.Description(description+`, optionally schema-qualified as "schema.table"`),
+		),
+		mcp.WithString("schema",
+			mcp.Description("The schema of the table, for an unqualified table_name"),
+		),
+	)
+}
+
+// tableNameFromRequest reads the table_name and schema arguments of a tool
+// call and returns the table name, qualified when a schema was given
+func tableNameFromRequest(request mcp.CallToolRequest) (string, error) {
+	return qualifiedNameFromRequest(request, "table_name")
+}
+
+// qualifiedNameFromRequest reads the named argument and the schema
+// argument of a tool call and returns the object name, qualified when a
+// schema was given
+func qualifiedNameFromRequest(request mcp.CallToolRequest, argument string) (string, error) {
+	name, ok := request.Params.Arguments[argument].(string)
+	if !ok || strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("%s must be a non-empty string", argument)
+	}
+
+	schemaName, _ := request.Params.Arguments["schema"].(string)
+	if schemaName = strings.TrimSpace(schemaName); schemaName == "" {
+		return name, nil
+	}
+
+	if qualifier, _ := splitTableName(name); qualifier != "" {
+		return "", fmt.Errorf("%s %s is already qualified with a schema; leave out the schema argument", argument, name)
+	}
+
+	_, objectName := splitTableName(name)
+	return qualifyTableName(schemaName, objectName), nil
+}
+
+// splitTableName splits "schema.table" into its parts, removing the quotes
+// around identifiers quoted with "double quotes", `backticks` or [brackets].
+// The schema is empty when the name is not qualified.
+func splitTableName(name string) (string, string) {
+	unquote := func(identifier string) string {
+		identifier = strings.TrimSpace(identifier)
+		if len(identifier) < 2 {
+			return identifier
+		}
+		switch first, last := identifier[0], identifier[len(identifier)-1]; {
+		case first == '"' && last == '"':
+			return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`)
+		case first == '`' && last == '`':
+			return strings.ReplaceAll(identifier[1:len(identifier)-1], "``", "`")
+		case first == '[' && last == ']':
+			return strings.ReplaceAll(identifier[1:len(identifier)-1], "]]", "]")
+		}
+		return identifier
+	}
+
+	// Find the separating dot outside of quoted identifiers
+	var closing rune
+	for i, r := range name {
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			}
+		case r == '"' || r == '`':
+			closing = r
+		case r == '[':
+			closing = ']'
+		case r == '.':
+			return unquote(name[:i]), unquote(name[i+1:])
+		}
+	}
+
+	return "", unquote(name)
+}
+
+// formatNameList renders a numbered list of names, e.g. tables or schemas
+func formatNameList(kind string, names []string) string {
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Found %d %s:\n\n", len(names), kind))
+
+	for i, name := range names {
+		resultText.WriteString(fmt.Sprintf("%d. %s\n", i+1, name))
+	}
+
+	return resultText.String()
+}
+
+// defaultStatementTimeout cancels statements of connections that do not
+// configure a timeout
+const defaultStatementTimeout = 60 * time.Second
+
+// queryer is the query methods shared by *sql.DB, *sql.Conn and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// supportsReadOnlyTransactions reports whether the engine's driver can run
+// a transaction in READ ONLY mode
+func supportsReadOnlyTransactions(engine string) bool {
+	return engine == enginePostgres || engine == engineMySQL
+}
+
+// queryDatabase runs a query for a backend's Query method and scans all
+// rows of its first result set with convert
+func queryDatabase(ctx context.Context, db *sql.DB, config connectionConfig, convert valueConverter, query string, params ...any) ([]map[string]any, error) {
+	results, err := queryDatabasePage(ctx, db, config, convert, query, 0, 0, params...)
+	if err != nil {
+		return nil, err
+	}
+	return results[0].maps(), nil
+}
+
+// queryDatabaseResultSets runs a query for a backend's QueryResultSets
+// method and scans all rows of every result set with convert
+func queryDatabaseResultSets(ctx context.Context, db *sql.DB, config connectionConfig, convert valueConverter, query string, params ...any) ([][]map[string]any, error) {
+	results, err := queryDatabasePage(ctx, db, config, convert, query, 0, 0, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([][]map[string]any, 0, len(results))
+	for _, result := range results {
+		sets = append(sets, result.maps())
+	}
+	return sets, nil
+}
+
+// queryDatabasePage runs a query and scans one page of its result sets with
+// convert, see scanResultSets. On read-only connections the statement is
+// parsed and rejected if it could write, and on engines that support it the
+// query also runs inside a read-only transaction.
+func queryDatabasePage(ctx context.Context, db *sql.DB, config connectionConfig, convert valueConverter, query string, offset int, limit int, params ...any) ([]queryResult, error) {
+	var source queryer = db
+
+	if config.Engine == engineMySQL {
+		// Pin a connection so its running query can be killed on cancellation
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error getting connection: %w", err)
+		}
+		defer conn.Close()
+		source = conn
+	}
+
+	if config.ReadOnly {
+		if err := checkReadOnlyQuery(config.Engine, query); err != nil {
+			return nil, fmt.Errorf("read-only connection %q: %w", config.Name, err)
+		}
+
+		if supportsReadOnlyTransactions(config.Engine) {
+			tx, err := beginTx(ctx, source, &sql.TxOptions{ReadOnly: true})
+			if err != nil {
+				return nil, fmt.Errorf("error starting read-only transaction: %w", err)
+			}
+			// Nothing to commit in a read-only transaction
+			defer tx.Rollback()
+			source = tx
+		}
+	}
+
+	if config.Engine == engineMySQL {
+		stop, err := killMySQLQueryOnCancel(ctx, db, source)
+		if err != nil {
+			return nil, err
+		}
+		defer stop()
+	}
+
+	rows, err := source.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanResultSets(rows, convert, offset, limit)
+}
+
+// beginTx starts a transaction on a pinned connection or on the pool
+func beginTx(ctx context.Context, source queryer, options *sql.TxOptions) (*sql.Tx, error) {
+	switch source := source.(type) {
+	case *sql.Conn:
+		return source.BeginTx(ctx, options)
+	case *sql.DB:
+		return source.BeginTx(ctx, options)
+	}
+	return nil, fmt.Errorf("cannot start a transaction on %T", source)
+}
+
+// statementContext derives the context the database work of a tool call
+// runs in. It is cancelled when the client cancels the request, and times
+// out after the timeout_seconds argument or, without one, the connection's
+// statement timeout. The argument may shorten the timeout but not extend it.
+func statementContext(ctx context.Context, config connectionConfig, request mcp.CallToolRequest) (context.Context, context.CancelFunc, error) {
+	ctx = transport.RequestContext(ctx)
+
+	timeout := config.StatementTimeout
+	if value, ok := request.Params.Arguments["timeout_seconds"].(float64); ok {
+		requested := time.Duration(value * float64(time.Second))
+		if requested <= 0 {
+			return nil, nil, fmt.Errorf("timeout_seconds must be positive")
+		}
+		if timeout > 0 && requested > timeout {
+			return nil, nil, fmt.Errorf("timeout_seconds cannot exceed the %v statement timeout of connection %q", timeout, config.Name)
+		}
+		timeout = requested
+	}
+
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+// contextError explains an error caused by the statement context ending
+func contextError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("statement timed out: %w", err)
+	case context.Canceled:
+		return fmt.Errorf("statement cancelled by the client: %w", err)
+	}
+	return err
+}
+
+// checkWritable returns an error when config is a read-only connection
+func checkWritable(config connectionConfig) error {
+	if config.ReadOnly {
+		return fmt.Errorf("connection %q is read-only", config.Name)
+	}
+	return nil
+}
+
+// pageQuerier is implemented by backends that can read a single page of a
+// query's result sets without loading the rest
+type pageQuerier interface {
+	queryPage(ctx context.Context, query string, offset int, limit int, params ...any) ([]queryResult, error)
+}
+
+// schemaLister is implemented by backends that can list the schemas (or,
+// for MySQL, databases) of a connection
+type schemaLister interface {
+	getDBSchemas(ctx context.Context) ([]string, error)
+}
+
+// tableScripter is implemented by backends that can script a table as the
+// CREATE TABLE and CREATE INDEX statements of their own dialect
+type tableScripter interface {
+	scriptTable(ctx context.Context, tableName string) (string, error)
+}
+
+// registerDatabaseTools registers the query, statement and table tools shared by every
+// interfaces.Database backend. Tool names are prefixed with prefix (for
+// example "sql" or "pg") and descriptions mention engine.
+func registerDatabaseTools(server *server.MCPServer, prefix string, engine string, connections *connectionRegistry) {
+	// withConnection appends the connection argument, if any, to a tool's options
+	withConnection := func(options ...mcp.ToolOption) []mcp.ToolOption {
+		return append(options, connections.toolOptions()...)
+	}
+
+	// Register tool for executing SQL queries
+	executeQueryTool := mcp.NewTool(prefix+"_execute_query", withConnection(
+		mcp.WithDescription(fmt.Sprintf("Execute a SQL query against the %s database. Batches returning several result sets "+
+			"return every set, each under its own header", engine)),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The SQL query to execute"),
+		),
+		mcp.WithArray("parameters",
+			arrayOrObject(),
+			mcp.Description(`Values bound to the query's parameters instead of writing them into the SQL: an array bound by position `+
+				`(@p1, @p2, ... on SQL Server, $1 on PostgreSQL, ? on MySQL and SQLite) or, on SQL Server and SQLite, an object bound by name (@name). `+
+				`A value can carry a type hint as {"type": "int|decimal|datetime|uniqueidentifier|varbinary", "value": ...}; varbinary values are base64`),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: text (tab-separated, default), json (typed values with column metadata), csv or markdown"),
+			mcp.Enum(formatText, formatJSON, formatCSV, formatMarkdown),
+		),
+		mcp.WithNumber("max_rows",
+			mcp.Description(fmt.Sprintf("Maximum number of rows to return per page (1 to %d). Defaults to the connection's limit", maxRowsCeiling)),
+		),
+		mcp.WithString("page_token",
+			mcp.Description("Token returned by a previous call with the same query to fetch the next page of results. "+
+				"Each page runs the query again, so give it an ORDER BY on unique columns for pages not to repeat or skip rows"),
+		),
+		mcp.WithBoolean("mask",
+			mcp.Description("Mask columns that hold personal data by the connection's masking rules. "+
+				"Always on for connections configured to mask query results"),
+		),
+		mcp.WithNumber("timeout_seconds",
+			mcp.Description("Cancel the statement after this many seconds. Cannot exceed the connection's statement timeout"),
+		),
+	)...)
+
+	server.AddTool(executeQueryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		query, ok := request.Params.Arguments["query"].(string)
+		if !ok {
+			return mcp.NewToolResultError("query must be a string"), nil
+		}
+
+		params, err := queryParametersFromRequest(request, conn.config.Engine)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Page tokens belong to the query together with its parameters
+		pageKey := query
+		if params != nil {
+			data, _ := json.Marshal(request.Params.Arguments["parameters"])
+			pageKey += "\n" + string(data)
+		}
+
+		format, err := parseResultFormat(request.Params.Arguments["format"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		querier, ok := conn.db.(pageQuerier)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support paged queries", conn.config.Name, conn.config.Engine)), nil
+		}
+
+		// Page size: the max_rows argument, else the connection's default
+		maxRows := int(conn.config.MaxRows)
+		if value, ok := request.Params.Arguments["max_rows"].(float64); ok {
+			if value < 1 || value > maxRowsCeiling {
+				return mcp.NewToolResultError(fmt.Sprintf("max_rows must be between 1 and %d", maxRowsCeiling)), nil
+			}
+			maxRows = int(value)
+		}
+
+		// Every page runs the query again, so only read-only queries are
+		// paged; others would repeat their writes
+		pageable := checkReadOnlyQuery(conn.config.Engine, query) == nil
+
+		offset := 0
+		if token, _ := request.Params.Arguments["page_token"].(string); token != "" {
+			if !pageable {
+				return mcp.NewToolResultError("page_token can only be used with read-only queries, since fetching a page runs the query again"), nil
+			}
+			page, err := decodePageToken(token, conn.config.Name, pageKey)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			offset = page.Offset
+			if _, ok := request.Params.Arguments["max_rows"]; !ok {
+				// The token comes from the client, so its page size must be
+				// one a max_rows argument or the connection could have set
+				if page.MaxRows != int(conn.config.MaxRows) && (page.MaxRows < 1 || page.MaxRows > maxRowsCeiling) {
+					return mcp.NewToolResultError("invalid page_token"), nil
+				}
+				maxRows = page.MaxRows
+			}
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		results, err := querier.queryPage(queryCtx, query, offset, maxRows, params...)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		// Mask personal data before the rows are rendered
+		var masked []maskedColumn
+		if mask, _ := request.Params.Arguments["mask"].(bool); mask || conn.config.Masking.QueryResults {
+			policy := conn.config.Masking.policy()
+			for i := range results {
+				for _, column := range policy.maskResult(&results[i]) {
+					if len(results) > 1 {
+						column.name = fmt.Sprintf("%s in result set %d", column.name, results[i].index+1)
+					}
+					masked = append(masked, column)
+				}
+			}
+		}
+
+		page := resultPage{
+			results:  results,
+			masked:   masked,
+			offset:   offset,
+			maxRows:  maxRows,
+			maxBytes: conn.config.MaxResultBytes,
+			nextToken: func(shown int) string {
+				if !pageable {
+					return ""
+				}
+				return encodePageToken(conn.config.Name, pageKey, offset+shown, maxRows)
+			},
+			toolName: prefix + "_execute_query",
+		}
+
+		output, err := page.render(format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	})
+
+	// Register tool for executing write statements
+	tokens := newConfirmTokens()
+
+	executeStatementTool := mcp.NewTool(prefix+"_execute_statement", withConnection(
+		mcp.WithDescription(fmt.Sprintf("Execute a statement that modifies data (INSERT, UPDATE, DELETE, ...) against the %s database. "+
+			"Without confirm_token the statement runs as a dry-run and is rolled back; pass the returned confirm_token "+
+			"with the same statement to commit it", engine)),
+		mcp.WithString("statement",
+			mcp.Required(),
+			mcp.Description("The SQL statement to execute"),
+		),
+		mcp.WithString("confirm_token",
+			mcp.Description("Token returned by the dry-run of this statement; commits the statement when given"),
+		),
+		mcp.WithNumber("timeout_seconds",
+			mcp.Description("Cancel the statement after this many seconds. Cannot exceed the connection's statement timeout"),
+		),
+	)...)
+
+	server.AddTool(executeStatementTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		statement, ok := request.Params.Arguments["statement"].(string)
+		if !ok {
+			return mcp.NewToolResultError("statement must be a string"), nil
+		}
+		confirmToken, _ := request.Params.Arguments["confirm_token"].(string)
+		confirmToken = strings.TrimSpace(confirmToken)
+
+		executor, ok := conn.db.(statementExecutor)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support executing statements", conn.config.Name, conn.config.Engine)), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		// Dry-run: execute, report and roll back
+		if confirmToken == "" {
+			rowsAffected, err := executor.executeStatement(queryCtx, statement, false)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			token, err := tokens.issue(conn.config.Name, statement, rowsAffected)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			var resultText strings.Builder
+			resultText.WriteString(fmt.Sprintf("Dry run: statement affected %d rows. The transaction was rolled back.\n\n", rowsAffected))
+			resultText.WriteString(fmt.Sprintf("To commit, call %s_execute_statement again with the same statement and confirm_token: %s\n", prefix, token))
+			resultText.WriteString(fmt.Sprintf("The token is valid for %v and can be used once.\n", confirmTokenTTL))
+
+			return mcp.NewToolResultText(resultText.String()), nil
+		}
+
+		pending, err := tokens.redeem(confirmToken, conn.config.Name, statement)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		rowsAffected, err := executor.executeStatement(queryCtx, statement, true)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		var resultText strings.Builder
+		resultText.WriteString(fmt.Sprintf("Statement committed: %d rows affected.\n", rowsAffected))
+		if rowsAffected != pending.rowsAffected {
+			resultText.WriteString(fmt.Sprintf("Note: the dry-run affected %d rows; the data changed in between.\n", pending.rowsAffected))
+		}
+
+		return mcp.NewToolResultText(resultText.String()), nil
+	})
+
+	// Register tool for getting all tables
+	getTablesTool := mcp.NewTool(prefix+"_get_tables", withConnection(
+		mcp.WithDescription(fmt.Sprintf("Get a list of all tables in the %s database", engine)),
+	)...)
+
+	server.AddTool(getTablesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		tables, err := conn.db.GetTables(queryCtx)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		return mcp.NewToolResultText(formatNameList("tables", tables)), nil
+	})
+
+	// Register tool for getting table schema
+	getTableSchemaTool := mcp.NewTool(prefix+"_get_table_schema", withConnection(withTableArguments(
+		"The name of the table to get the schema for",
+		mcp.WithDescription(fmt.Sprintf("Get the columns, primary key, foreign keys, indexes and constraints of a specific table in the %s database", engine)),
+	)...)...)
+
+	server.AddTool(getTableSchemaTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		tableName, err := tableNameFromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		schema, err := conn.db.GetTableSchema(queryCtx, tableName)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		return mcp.NewToolResultText(formatTableSchema(schema)), nil
+	})
+
+	// Register tool for scripting a table as DDL
+	getTableDDLTool := mcp.NewTool(prefix+"_get_table_ddl", withConnection(withTableArguments(
+		"The name of the table to script",
+		mcp.WithDescription(fmt.Sprintf("Script a table in the %s database as the CREATE TABLE statement of the connection's engine, "+
+			"with column types including length and precision, identity and computed columns, defaults, key, unique, check and "+
+			"foreign key constraints, followed by CREATE INDEX statements for its other indexes", engine)),
+	)...)...)
+
+	server.AddTool(getTableDDLTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		scripter, ok := conn.db.(tableScripter)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support scripting tables", conn.config.Name, conn.config.Engine)), nil
+		}
+
+		tableName, err := tableNameFromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		ddl, err := scripter.scriptTable(queryCtx, tableName)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		return mcp.NewToolResultText(ddl), nil
+	})
+
+	// Register tool for listing views
+	getViewsTool := mcp.NewTool(prefix+"_get_views", withConnection(
+		mcp.WithDescription(fmt.Sprintf("Get a list of all views in the %s database", engine)),
+	)...)
+
+	server.AddTool(getViewsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		views, err := conn.db.GetViews(queryCtx)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		return mcp.NewToolResultText(formatNameList("views", views)), nil
+	})
+
+	// Register tools for listing stored procedures and functions
+	routineTools := []struct {
+		name        string
+		kind        string
+		routineType string
+		description string
+	}{
+		{"_get_procedures", "procedures", objectProcedure, "Get a list of all stored procedures in the %s database"},
+		{"_get_functions", "functions", objectFunction, "Get a list of all functions in the %s database with their return types"},
+	}
+
+	for _, routineTool := range routineTools {
+		tool := mcp.NewTool(prefix+routineTool.name, withConnection(
+			mcp.WithDescription(fmt.Sprintf(routineTool.description, engine)),
+		)...)
+
+		server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			conn, err := connections.fromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			routines, err := conn.db.GetRoutines(queryCtx)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			return mcp.NewToolResultText(formatRoutines(routineTool.kind, routines, routineTool.routineType)), nil
+		})
+	}
+
+	// Register tool for getting the definition of a view, procedure or function
+	getObjectDefinitionTool := mcp.NewTool(prefix+"_get_object_definition", withConnection(
+		mcp.WithDescription(fmt.Sprintf("Get the definition and parameter list of a view, stored procedure or function in the %s database", engine)),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description(`The name of the view, procedure or function, optionally schema-qualified as "schema.name"`),
+		),
+		mcp.WithString("schema",
+			mcp.Description("The schema of the object, for an unqualified name"),
+		),
+	)...)
+
+	server.AddTool(getObjectDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		name, err := qualifiedNameFromRequest(request, "name")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		definition, err := conn.db.GetObjectDefinition(queryCtx, name)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		return mcp.NewToolResultText(formatObjectDefinition(definition)), nil
+	})
+}
+
+// valueOrEmpty formats a nullable value, rendering NULL as an empty string
+func valueOrEmpty(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// confirmTokenPattern finds the confirm token in a dry-run result
+var confirmTokenPattern = regexp.MustCompile(`confirm_token: (\S+)`)
+
+// toolResult is the part of a tools/call response the tests look at
+type toolResult struct {
+	Text    string
+	IsError bool
+}
+
+// newSQLiteTestServer registers the database tools of a SQLite database
+// holding an items table with five rows, returning pages of two rows
+func newSQLiteTestServer(t *testing.T, readOnly bool) *server.MCPServer {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+		INSERT INTO items (id, name) VALUES (1, 'one'), (2, 'two'), (3, 'three'), (4, 'four'), (5, 'five')`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := connectionConfig{
+		Name:             defaultConnectionName,
+		Engine:           engineSQLite,
+		Database:         path,
+		ReadOnly:         readOnly,
+		MaxAffectedRows:  defaultMaxAffectedRows,
+		MaxRows:          2,
+		MaxResultBytes:   defaultMaxResultBytes,
+		StatementTimeout: time.Minute,
+	}
+	sqlite := &sqliteImpl{config: config}
+	if err := sqlite.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	connections := newConnectionRegistry(false)
+	connections.add(config, sqlite)
+	t.Cleanup(func() { connections.disconnect() })
+	registerDatabaseTools(mcpServer, "sqlite", "SQLite", connections)
+	return mcpServer
+}
+
+// callTool calls a tool of the server and returns its text result
+func callTool(t *testing.T, mcpServer *server.MCPServer, name string, arguments map[string]any) toolResult {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": name, "arguments": arguments},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil || len(response.Result.Content) == 0 {
+		t.Fatalf("calling %s returned %s", name, data)
+	}
+	return toolResult{Text: response.Result.Content[0].Text, IsError: response.Result.IsError}
+}
+
+func TestExecuteQueryPaging(t *testing.T) {
+	mcpServer := newSQLiteTestServer(t, true)
+	query := "SELECT id FROM items ORDER BY id"
+
+	var ids []string
+	arguments := map[string]any{"query": query, "format": formatJSON}
+	for page := 0; page < 5; page++ {
+		result := callTool(t, mcpServer, "sqlite_execute_query", arguments)
+		if result.IsError {
+			t.Fatalf("page %d failed: %s", page+1, result.Text)
+		}
+
+		var document jsonResult
+		if err := json.Unmarshal([]byte(result.Text), &document); err != nil {
+			t.Fatalf("page %d is not json: %s", page+1, result.Text)
+		}
+		for _, row := range document.Rows {
+			ids = append(ids, fmt.Sprint(row[0]))
+		}
+		if document.NextPageToken == "" {
+			break
+		}
+		arguments["page_token"] = document.NextPageToken
+	}
+
+	if got := strings.Join(ids, ","); got != "1,2,3,4,5" {
+		t.Errorf("pages returned ids %s, want 1,2,3,4,5", got)
+	}
+}
+
+func TestExecuteQueryPageTokenErrors(t *testing.T) {
+	mcpServer := newSQLiteTestServer(t, false)
+	query := "SELECT id FROM items ORDER BY id"
+
+	forged := encodePageToken(defaultConnectionName, query, 0, 0)
+	if result := callTool(t, mcpServer, "sqlite_execute_query", map[string]any{"query": query, "page_token": forged}); !result.IsError {
+		t.Errorf("a page token without a row limit was accepted: %s", result.Text)
+	}
+
+	token := encodePageToken(defaultConnectionName, query, 2, 2)
+	if result := callTool(t, mcpServer, "sqlite_execute_query", map[string]any{"query": "SELECT name FROM items", "page_token": token}); !result.IsError {
+		t.Errorf("a page token of another query was accepted: %s", result.Text)
+	}
+
+	write := "UPDATE items SET name = name RETURNING id"
+	token = encodePageToken(defaultConnectionName, write, 2, 2)
+	if result := callTool(t, mcpServer, "sqlite_execute_query", map[string]any{"query": write, "page_token": token}); !result.IsError {
+		t.Errorf("a page token of a write query was accepted: %s", result.Text)
+	}
+	result := callTool(t, mcpServer, "sqlite_execute_query", map[string]any{"query": write})
+	if result.IsError || strings.Contains(result.Text, "page_token") {
+		t.Errorf("a write query returned %s, want rows without a page token", result.Text)
+	}
+}
+
+func TestExecuteQueryReadOnly(t *testing.T) {
+	mcpServer := newSQLiteTestServer(t, true)
+
+	for _, query := range []string{
+		"DELETE FROM items",
+		"SELECT 1; DROP TABLE items",
+		"UPDATE items SET name = 'x' RETURNING id",
+	} {
+		result := callTool(t, mcpServer, "sqlite_execute_query", map[string]any{"query": query})
+		if !result.IsError || !strings.Contains(result.Text, "read-only") {
+			t.Errorf("query %q on a read-only connection returned %s", query, result.Text)
+		}
+	}
+
+	result := callTool(t, mcpServer, "sqlite_execute_query", map[string]any{"query": "SELECT COUNT(*) AS n FROM items", "format": formatJSON})
+	if result.IsError || !strings.Contains(result.Text, "5") {
+		t.Errorf("counting the items returned %s", result.Text)
+	}
+}
+
+func TestExecuteStatementConfirmToken(t *testing.T) {
+	mcpServer := newSQLiteTestServer(t, false)
+	statement := "DELETE FROM items WHERE id > 3"
+	count := func() string {
+		result := callTool(t, mcpServer, "sqlite_execute_query", map[string]any{"query": "SELECT COUNT(*) FROM items", "format": formatCSV})
+		lines := strings.Split(strings.TrimSpace(result.Text), "\n")
+		return strings.TrimSpace(lines[len(lines)-1])
+	}
+
+	dryRun := callTool(t, mcpServer, "sqlite_execute_statement", map[string]any{"statement": statement})
+	match := confirmTokenPattern.FindStringSubmatch(dryRun.Text)
+	if dryRun.IsError || match == nil || !strings.Contains(dryRun.Text, "affected 2 rows") {
+		t.Fatalf("dry run returned %s", dryRun.Text)
+	}
+	if got := count(); got != "5" {
+		t.Errorf("dry run left %s items, want 5", got)
+	}
+
+	other := callTool(t, mcpServer, "sqlite_execute_statement", map[string]any{"statement": "DELETE FROM items", "confirm_token": match[1]})
+	if !other.IsError {
+		t.Errorf("the confirm token committed another statement: %s", other.Text)
+	}
+
+	// Tokens are only spent by the statement they were issued for
+	commit := callTool(t, mcpServer, "sqlite_execute_statement", map[string]any{"statement": statement, "confirm_token": match[1]})
+	if commit.IsError || !strings.Contains(commit.Text, "committed: 2 rows") {
+		t.Fatalf("commit returned %s", commit.Text)
+	}
+	if got := count(); got != "3" {
+		t.Errorf("commit left %s items, want 3", got)
+	}
+
+	replay := callTool(t, mcpServer, "sqlite_execute_statement", map[string]any{"statement": statement, "confirm_token": match[1]})
+	if !replay.IsError {
+		t.Errorf("the confirm token was redeemed twice: %s", replay.Text)
+	}
+}
@@ -0,0 +1,434 @@
+package tools
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Output formats of the execute_query tools
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatCSV      = "csv"
+	formatMarkdown = "markdown"
+)
+
+// parseResultFormat validates the format argument of a tool call
+func parseResultFormat(value any) (string, error) {
+	format, _ := value.(string)
+	switch format = strings.ToLower(strings.TrimSpace(format)); format {
+	case "":
+		return formatText, nil
+	case formatText, formatJSON, formatCSV, formatMarkdown:
+		return format, nil
+	}
+	return "", fmt.Errorf("format must be one of text, json, csv or markdown")
+}
+
+// resultColumn describes a result column in JSON output
+type resultColumn struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	DeclaredType string `json:"declared_type,omitempty"`
+	Nullable     *bool  `json:"nullable,omitempty"`
+	Length       *int64 `json:"length,omitempty"`
+	Precision    *int64 `json:"precision,omitempty"`
+	Scale        *int64 `json:"scale,omitempty"`
+}
+
+// newResultColumn reads the metadata the driver reports for a column
+func newResultColumn(columnType *sql.ColumnType) resultColumn {
+	column := resultColumn{
+		Name:         columnType.Name(),
+		Type:         columnType.DatabaseTypeName(),
+		DeclaredType: declaredType(columnType),
+	}
+	if nullable, ok := columnType.Nullable(); ok {
+		column.Nullable = &nullable
+	}
+	// Unbounded types such as text report the largest int64 as their length
+	if length, ok := columnType.Length(); ok && length != math.MaxInt64 {
+		column.Length = &length
+	}
+	if precision, scale, ok := columnType.DecimalSize(); ok {
+		column.Precision, column.Scale = &precision, &scale
+	}
+	return column
+}
+
+// maxLengthSentinels are the lengths SQL Server reports for varchar(max),
+// nvarchar(max) and varbinary(max) columns
+var maxLengthSentinels = map[int64]bool{
+	2147483645:     true,
+	2147483645 / 2: true,
+}
+
+// declaredType renders a column's type the way it would be declared, such
+// as decimal(18,2) or nvarchar(50), or returns an empty string when the
+// driver does not know the type
+func declaredType(columnType *sql.ColumnType) string {
+	name := strings.ToLower(columnType.DatabaseTypeName())
+	if name == "" {
+		return ""
+	}
+
+	switch {
+	case name == "decimal" || name == "numeric":
+		if precision, scale, ok := columnType.DecimalSize(); ok {
+			return fmt.Sprintf("%s(%d,%d)", name, precision, scale)
+		}
+	case strings.HasSuffix(name, "char") || strings.HasSuffix(name, "binary"):
+		if length, ok := columnType.Length(); ok && length != math.MaxInt64 {
+			if maxLengthSentinels[length] {
+				return name + "(max)"
+			}
+			return fmt.Sprintf("%s(%d)", name, length)
+		}
+	}
+
+	return name
+}
+
+// resultPage is the part of a query's result sets returned by one tool call
+type resultPage struct {
+	results []queryResult
+
+	// masked lists the columns whose values were masked
+	masked []maskedColumn
+
+	// offset is the number of rows skipped before the page
+	offset int
+
+	// maxRows and maxBytes are the row and size limits of the page
+	maxRows  int
+	maxBytes int64
+
+	// nextToken returns the page token for the rows after the first shown,
+	// or an empty string when the query cannot be paged
+	nextToken func(shown int) string
+
+	// toolName is the tool to call again for the next page
+	toolName string
+}
+
+// jsonResult is the document returned in json format
+type jsonResult struct {
+	Columns       []resultColumn `json:"columns"`
+	Rows          [][]any        `json:"rows"`
+	RowCount      int            `json:"row_count"`
+	Offset        int            `json:"offset"`
+	Truncated     bool           `json:"truncated"`
+	NextPageToken string         `json:"next_page_token,omitempty"`
+	MaskedColumns []string       `json:"masked_columns,omitempty"`
+}
+
+// jsonResultSet is one result set in json format, for queries and
+// procedures that return several
+type jsonResultSet struct {
+	ResultSet int            `json:"result_set"`
+	Columns   []resultColumn `json:"columns"`
+	Rows      [][]any        `json:"rows"`
+	RowCount  int            `json:"row_count"`
+	Truncated bool           `json:"truncated"`
+}
+
+// jsonResultSets is the document returned in json format for queries that
+// return several result sets
+type jsonResultSets struct {
+	ResultSets    []jsonResultSet `json:"result_sets"`
+	RowCount      int             `json:"row_count"`
+	Offset        int             `json:"offset"`
+	Truncated     bool            `json:"truncated"`
+	NextPageToken string          `json:"next_page_token,omitempty"`
+	MaskedColumns []string        `json:"masked_columns,omitempty"`
+}
+
+// resultColumns describes the columns of a result in json format
+func resultColumns(result queryResult) []resultColumn {
+	columns := make([]resultColumn, len(result.columnTypes))
+	for i, columnType := range result.columnTypes {
+		columns[i] = newResultColumn(columnType)
+	}
+	return columns
+}
+
+// render formats the page, leaving out the rows past the size limit. At
+// least one row is always included so that paging makes progress. When
+// the query returned several result sets, each is shown under its own
+// label and column header.
+func (p resultPage) render(format string) (string, error) {
+	headers := make([]string, len(p.results))
+	lines := make([][]string, len(p.results))
+	for i, result := range p.results {
+		var err error
+		headers[i], lines[i], err = renderRows(result, format)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	shownInSet, sets, truncatedBytes := fitRows(headers, lines, p.maxBytes)
+	shown := 0
+	for _, n := range shownInSet {
+		shown += n
+	}
+
+	truncated := truncatedBytes || p.results[len(p.results)-1].more
+	nextToken := ""
+	if truncated {
+		nextToken = p.nextToken(shown)
+	}
+
+	if format == formatJSON {
+		var document any
+		if len(p.results) == 1 {
+			result := p.results[0]
+			document = jsonResult{
+				Columns:       resultColumns(result),
+				Rows:          append(make([][]any, 0, shown), result.rows[:shown]...),
+				RowCount:      shown,
+				Offset:        p.offset,
+				Truncated:     truncated,
+				NextPageToken: nextToken,
+				MaskedColumns: maskedColumnNames(p.masked),
+			}
+		} else {
+			resultSets := make([]jsonResultSet, 0, sets)
+			for i, result := range p.results[:sets] {
+				resultSets = append(resultSets, jsonResultSet{
+					ResultSet: result.index + 1,
+					Columns:   resultColumns(result),
+					Rows:      append(make([][]any, 0, shownInSet[i]), result.rows[:shownInSet[i]]...),
+					RowCount:  shownInSet[i],
+					Truncated: truncated && i == sets-1,
+				})
+			}
+			document = jsonResultSets{
+				ResultSets:    resultSets,
+				RowCount:      shown,
+				Offset:        p.offset,
+				Truncated:     truncated,
+				NextPageToken: nextToken,
+				MaskedColumns: maskedColumnNames(p.masked),
+			}
+		}
+
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	var resultText strings.Builder
+	inSets := ""
+	if len(p.results) > 1 {
+		inSets = fmt.Sprintf(" in %d result sets", sets)
+	}
+	if p.offset > 0 {
+		resultText.WriteString(fmt.Sprintf("Query executed with %d results%s (rows %d to %d):\n\n", shown, inSets, p.offset+1, p.offset+shown))
+	} else {
+		resultText.WriteString(fmt.Sprintf("Query executed with %d results%s:\n\n", shown, inSets))
+	}
+
+	for i, result := range p.results[:sets] {
+		if len(p.results) > 1 {
+			if i > 0 {
+				resultText.WriteString("\n")
+			}
+			resultText.WriteString(fmt.Sprintf("Result set %d:\n\n", result.index+1))
+		}
+
+		if shownInSet[i] > 0 || format != formatText || len(p.results) > 1 {
+			resultText.WriteString(headers[i])
+		}
+		for _, line := range lines[i][:shownInSet[i]] {
+			resultText.WriteString(line)
+		}
+	}
+
+	if len(p.masked) > 0 {
+		resultText.WriteString("\n")
+		resultText.WriteString(formatMaskedColumns(p.masked))
+	}
+
+	if truncated {
+		resultText.WriteString("\n")
+		if truncatedBytes {
+			resultText.WriteString(fmt.Sprintf("Results truncated: output reached the %d byte limit after %d rows.\n", p.maxBytes, shown))
+		} else {
+			resultText.WriteString(fmt.Sprintf("Results truncated: more rows are available beyond the %d row limit.\n", p.maxRows))
+		}
+		if nextToken != "" {
+			resultText.WriteString(fmt.Sprintf("To fetch the next page, call %s again with the same query and page_token: %s\n", p.toolName, nextToken))
+		} else {
+			resultText.WriteString("The query is not read-only, so the remaining rows cannot be paged: fetching a page would run it again.\n")
+		}
+	}
+
+	return resultText.String(), nil
+}
+
+// fitRows counts the rendered rows of each result set that fit within
+// maxBytes of output, headers included, and the sets that are shown at
+// all. At least one row is always counted, and a maxBytes of 0 disables
+// the limit. truncated reports whether the limit left rows out.
+func fitRows(headers []string, lines [][]string, maxBytes int64) (shownInSet []int, sets int, truncated bool) {
+	size := int64(0)
+	shown := 0
+	shownInSet = make([]int, len(headers))
+	for i := range headers {
+		size += int64(len(headers[i]))
+		for _, line := range lines[i] {
+			size += int64(len(line))
+			if maxBytes > 0 && shown > 0 && size > maxBytes {
+				// A set cut off before its first row is not shown
+				if shownInSet[i] == 0 {
+					return shownInSet, i, true
+				}
+				return shownInSet, i + 1, true
+			}
+			shown++
+			shownInSet[i]++
+		}
+	}
+	return shownInSet, len(headers), false
+}
+
+// renderRows formats the column header and every row of a result
+func renderRows(result queryResult, format string) (string, []string, error) {
+	columns := result.columns
+	lines := make([]string, 0, len(result.rows))
+
+	switch format {
+	case formatJSON:
+		// Only the size of each row matters here; render builds the document
+		for _, row := range result.rows {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return "", nil, fmt.Errorf("error encoding results as JSON: %w", err)
+			}
+			lines = append(lines, string(data))
+		}
+		return "", lines, nil
+
+	case formatCSV:
+		encode := func(record []string) (string, error) {
+			var buf bytes.Buffer
+			writer := csv.NewWriter(&buf)
+			if err := writer.Write(record); err != nil {
+				return "", err
+			}
+			writer.Flush()
+			return buf.String(), writer.Error()
+		}
+
+		header, err := encode(columns)
+		if err != nil {
+			return "", nil, fmt.Errorf("error encoding results as CSV: %w", err)
+		}
+		for _, row := range result.rows {
+			record := make([]string, len(columns))
+			for i, value := range row {
+				record[i] = formatCell(value, "")
+			}
+			line, err := encode(record)
+			if err != nil {
+				return "", nil, fmt.Errorf("error encoding results as CSV: %w", err)
+			}
+			lines = append(lines, line)
+		}
+		return header, lines, nil
+
+	case formatMarkdown:
+		var header strings.Builder
+		header.WriteString("|")
+		for _, col := range columns {
+			header.WriteString(" " + escapeMarkdownCell(col) + " |")
+		}
+		header.WriteString("\n|")
+		for range columns {
+			header.WriteString(" --- |")
+		}
+		header.WriteString("\n")
+
+		for _, row := range result.rows {
+			var line strings.Builder
+			line.WriteString("|")
+			for _, value := range row {
+				line.WriteString(" " + escapeMarkdownCell(formatCell(value, "NULL")) + " |")
+			}
+			line.WriteString("\n")
+			lines = append(lines, line.String())
+		}
+		return header.String(), lines, nil
+	}
+
+	// Tab-separated text
+	var header strings.Builder
+
+	// Print column headers
+	for _, col := range columns {
+		header.WriteString(fmt.Sprintf("%s\t", col))
+	}
+	header.WriteString("\n")
+
+	// Print the declared column types when the driver reports them
+	types := make([]string, len(result.columnTypes))
+	known := false
+	for i, columnType := range result.columnTypes {
+		types[i] = declaredType(columnType)
+		known = known || types[i] != ""
+	}
+	if known {
+		for _, typeName := range types {
+			header.WriteString(fmt.Sprintf("%s\t", typeName))
+		}
+		header.WriteString("\n")
+	}
+
+	// Print separator
+	for range columns {
+		header.WriteString("----------\t")
+	}
+	header.WriteString("\n")
+
+	// Print data rows
+	for _, row := range result.rows {
+		var line strings.Builder
+		for _, value := range row {
+			line.WriteString(fmt.Sprintf("%v\t", value))
+		}
+		line.WriteString("\n")
+		lines = append(lines, line.String())
+	}
+
+	return header.String(), lines, nil
+}
+
+// formatCell renders a value for CSV and Markdown output, using null for
+// NULL values
+func formatCell(value any, null string) string {
+	switch v := value.(type) {
+	case nil:
+		return null
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// escapeMarkdownCell keeps a value from breaking the table it is shown in
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
@@ -0,0 +1,337 @@
+package tools
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ways masked values are rendered
+const (
+	// maskModeMask replaces every value with maskedValue
+	maskModeMask = "mask"
+
+	// maskModeHash replaces every value with a keyed hash, so equal values
+	// can still be matched up without revealing them
+	maskModeHash = "hash"
+)
+
+// maskedValue replaces the values of masked columns in mask mode
+const maskedValue = "****"
+
+// Content detectors of the masking policy
+const (
+	detectorEmail      = "email"
+	detectorPhone      = "phone"
+	detectorSSN        = "ssn"
+	detectorCreditCard = "credit_card"
+	detectorIBAN       = "iban"
+)
+
+// defaultMaskColumns are the column name patterns masked unless a
+// connection configures its own
+var defaultMaskColumns = []string{
+	"*email*", "*e_mail*", "*phone*", "*mobile*", "*fax*",
+	"*ssn*", "*social_security*", "*national_id*", "*tax_id*", "*passport*",
+	"*credit_card*", "*card_number*", "*cardnumber*", "*iban*", "*account_number*",
+	"*password*", "*secret*", "*birth*", "dob", "*street*", "*address*", "*postal*", "*zip*",
+	"first_name", "firstname", "last_name", "lastname", "full_name", "fullname",
+}
+
+// defaultMaskDetectors are the content detectors used unless a connection
+// configures its own. Phone numbers are left out since their formats also
+// match many other values.
+var defaultMaskDetectors = []string{detectorEmail, detectorSSN, detectorCreditCard, detectorIBAN}
+
+// processMaskSalt keys the hashes of connections that do not configure a
+// salt, so that hashes cannot be looked up in precomputed tables
+var processMaskSalt = func() []byte {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		log.Printf("Failed to generate a masking salt: %v", err)
+	}
+	return salt
+}()
+
+// Patterns of the content detectors
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern      = regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?|\b\d{2,4}[ .-])\d{3,4}[ .-]?\d{3,4}\b`)
+	ssnPattern        = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	creditCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	ibanPattern       = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`)
+)
+
+// maskingConfig is the PII masking policy of a connection
+type maskingConfig struct {
+	// Columns are glob patterns, matched case-insensitively against column
+	// names, of the columns that are always masked
+	Columns []string
+
+	// Detectors name the content detectors that mask a column when one of
+	// its values looks like personal data
+	Detectors []string
+
+	// Mode is one of the maskMode* constants
+	Mode string
+
+	// Salt keys the hashes of hash mode; a random salt is used when empty
+	Salt string
+
+	// QueryResults masks the results of the execute_query tool too, not
+	// only those of the sample_rows tool
+	QueryResults bool
+}
+
+// maskingConfigFromEnv reads a masking policy from the variables with the
+// given prefix, such as SQL_MASK_COLUMNS. Unset lists keep their defaults;
+// lists set to an empty value turn the rule off.
+func maskingConfigFromEnv(prefix string) maskingConfig {
+	config := maskingConfig{
+		Columns: listFromEnvOr(prefix+"MASK_COLUMNS", defaultMaskColumns),
+		Mode:    maskModeMask,
+		Salt:    os.Getenv(prefix + "MASK_SALT"),
+	}
+
+	for _, detector := range listFromEnvOr(prefix+"MASK_DETECTORS", defaultMaskDetectors) {
+		detector = strings.ToLower(detector)
+		if _, ok := maskDetectors[detector]; !ok {
+			log.Printf("Ignoring unknown detector %q in %sMASK_DETECTORS", detector, prefix)
+			continue
+		}
+		config.Detectors = append(config.Detectors, detector)
+	}
+
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv(prefix + "MASK_MODE"))); mode {
+	case "", maskModeMask:
+	case maskModeHash:
+		config.Mode = maskModeHash
+	default:
+		log.Printf("Ignoring invalid value %q for %sMASK_MODE, using %s", mode, prefix, maskModeMask)
+	}
+
+	if value := strings.TrimSpace(os.Getenv(prefix + "MASK_QUERY_RESULTS")); value != "" {
+		queryResults, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Ignoring invalid value %q for %sMASK_QUERY_RESULTS", value, prefix)
+		}
+		config.QueryResults = queryResults
+	}
+
+	return config
+}
+
+// listFromEnvOr reads a comma-separated list from the named variable like
+// listFromEnv, using defaultValues when the variable is not set at all
+func listFromEnvOr(name string, defaultValues []string) []string {
+	if _, ok := os.LookupEnv(name); !ok {
+		return defaultValues
+	}
+	return listFromEnv(name)
+}
+
+// maskDetectors match values that look like personal data
+var maskDetectors = map[string]func(value string) bool{
+	detectorEmail: emailPattern.MatchString,
+	detectorPhone: phonePattern.MatchString,
+	detectorSSN:   ssnPattern.MatchString,
+	detectorCreditCard: func(value string) bool {
+		for _, match := range creditCardPattern.FindAllString(value, -1) {
+			if luhnValid(strings.NewReplacer(" ", "", "-", "").Replace(match)) {
+				return true
+			}
+		}
+		return false
+	},
+	detectorIBAN: func(value string) bool {
+		for _, match := range ibanPattern.FindAllString(value, -1) {
+			if ibanValid(strings.ReplaceAll(match, " ", "")) {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+// luhnValid reports whether a string of digits passes the Luhn check of
+// payment card numbers
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ibanValid reports whether an IBAN without spaces passes its mod-97 check
+func ibanValid(iban string) bool {
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(fmt.Sprintf("%d", r-'A'+10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// maskedColumn is a column whose values were masked, and why
+type maskedColumn struct {
+	name   string
+	reason string
+}
+
+// String describes the masked column for the notes of tool results
+func (c maskedColumn) String() string {
+	return fmt.Sprintf("%s (%s)", c.name, c.reason)
+}
+
+// maskingPolicy is a compiled maskingConfig
+type maskingPolicy struct {
+	mode     string
+	salt     []byte
+	patterns []string
+	columns  []*regexp.Regexp
+
+	detectors []string
+}
+
+// policy compiles the masking configuration
+func (c maskingConfig) policy() *maskingPolicy {
+	policy := &maskingPolicy{
+		mode:      c.Mode,
+		salt:      []byte(c.Salt),
+		detectors: c.Detectors,
+	}
+	if policy.mode == "" {
+		policy.mode = maskModeMask
+	}
+	if len(policy.salt) == 0 {
+		policy.salt = processMaskSalt
+	}
+
+	for _, pattern := range c.Columns {
+		expression := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		policy.patterns = append(policy.patterns, pattern)
+		policy.columns = append(policy.columns, regexp.MustCompile(expression))
+	}
+
+	return policy
+}
+
+// maskResult masks the columns of result whose names match a pattern or
+// any of whose text values trip a detector, replacing their values in
+// place, and returns the masked columns
+func (p *maskingPolicy) maskResult(result *queryResult) []maskedColumn {
+	var masked []maskedColumn
+	for i, name := range result.columns {
+		reason := p.columnReason(name)
+		if reason == "" {
+			reason = p.valueReason(result, i)
+		}
+		if reason == "" {
+			continue
+		}
+
+		for _, row := range result.rows {
+			row[i] = p.maskValue(row[i])
+		}
+		masked = append(masked, maskedColumn{name: name, reason: reason})
+	}
+	return masked
+}
+
+// columnReason returns why the named column is masked by its name, or an
+// empty string
+func (p *maskingPolicy) columnReason(name string) string {
+	for i, pattern := range p.columns {
+		if pattern.MatchString(name) {
+			return fmt.Sprintf("name matches %s", p.patterns[i])
+		}
+	}
+	return ""
+}
+
+// valueReason returns why column i of result is masked by its values, or
+// an empty string
+func (p *maskingPolicy) valueReason(result *queryResult, i int) string {
+	values := make([]any, len(result.rows))
+	for j, row := range result.rows {
+		values[j] = row[i]
+	}
+	return p.valuesReason(values)
+}
+
+// valuesReason returns why a column holding values is masked by them, or
+// an empty string
+func (p *maskingPolicy) valuesReason(values []any) string {
+	for _, detector := range p.detectors {
+		match := maskDetectors[detector]
+		for _, value := range values {
+			if text, ok := value.(string); ok && match(text) {
+				return fmt.Sprintf("values look like %s", strings.ReplaceAll(detector, "_", " "))
+			}
+		}
+	}
+	return ""
+}
+
+// maskValue returns the masked form of a value. NULL stays NULL, so that
+// masked columns still show which rows have a value.
+func (p *maskingPolicy) maskValue(value any) any {
+	if value == nil {
+		return nil
+	}
+	if p.mode == maskModeHash {
+		mac := hmac.New(sha256.New, p.salt)
+		mac.Write([]byte(formatCell(value, "")))
+		return "hash:" + hex.EncodeToString(mac.Sum(nil))[:16]
+	}
+	return maskedValue
+}
+
+// formatMaskedColumns renders the note listing masked columns, or an empty
+// string when nothing was masked
+func formatMaskedColumns(masked []maskedColumn) string {
+	if len(masked) == 0 {
+		return ""
+	}
+
+	descriptions := make([]string, len(masked))
+	for i, column := range masked {
+		descriptions[i] = column.String()
+	}
+	return fmt.Sprintf("Masked columns: %s.\n", strings.Join(descriptions, ", "))
+}
+
+// maskedColumnNames lists the masked columns for json results
+func maskedColumnNames(masked []maskedColumn) []string {
+	if len(masked) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(masked))
+	for i, column := range masked {
+		names[i] = column.String()
+	}
+	return names
+}
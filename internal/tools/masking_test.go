@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		digits string
+		valid  bool
+	}{
+		{"4111111111111111", true},
+		{"5500005555555559", true},
+		{"4111111111111112", false},
+		{"1234567812345678", false},
+	}
+
+	for _, test := range tests {
+		if got := luhnValid(test.digits); got != test.valid {
+			t.Errorf("luhnValid(%q) = %v, want %v", test.digits, got, test.valid)
+		}
+	}
+}
+
+func TestIBANValid(t *testing.T) {
+	tests := []struct {
+		iban  string
+		valid bool
+	}{
+		{"GB82WEST12345698765432", true},
+		{"DE89370400440532013000", true},
+		{"GB82WEST12345698765433", false},
+		{"DE89370400440532013001", false},
+	}
+
+	for _, test := range tests {
+		if got := ibanValid(test.iban); got != test.valid {
+			t.Errorf("ibanValid(%q) = %v, want %v", test.iban, got, test.valid)
+		}
+	}
+}
+
+func TestMaskDetectors(t *testing.T) {
+	tests := []struct {
+		detector string
+		value    string
+		match    bool
+	}{
+		{detectorEmail, "contact jane.doe@example.com today", true},
+		{detectorEmail, "not an address", false},
+		{detectorSSN, "123-45-6789", true},
+		{detectorSSN, "123-456-789", false},
+		{detectorCreditCard, "card 4111 1111 1111 1111", true},
+		{detectorCreditCard, "4111-1111-1111-1112", false},
+		{detectorIBAN, "GB82 WEST 1234 5698 7654 32", true},
+		{detectorIBAN, "GB82WEST12345698765433", false},
+		{detectorPhone, "+1 555 123 4567", true},
+	}
+
+	for _, test := range tests {
+		if got := maskDetectors[test.detector](test.value); got != test.match {
+			t.Errorf("%s detector on %q = %v, want %v", test.detector, test.value, got, test.match)
+		}
+	}
+}
+
+func TestMaskResult(t *testing.T) {
+	policy := maskingConfig{Columns: []string{"*email*"}, Detectors: []string{detectorSSN}}.policy()
+	result := queryResult{
+		columns: []string{"id", "CustomerEmail", "notes"},
+		rows: [][]any{
+			{int64(1), "a@example.com", "ok"},
+			{int64(2), nil, "ssn 123-45-6789"},
+		},
+	}
+
+	masked := policy.maskResult(&result)
+	if len(masked) != 2 || masked[0].name != "CustomerEmail" || masked[1].name != "notes" {
+		t.Fatalf("maskResult masked %v, want CustomerEmail and notes", masked)
+	}
+	if result.rows[0][0] != int64(1) || result.rows[0][1] != maskedValue || result.rows[1][1] != nil || result.rows[0][2] != maskedValue {
+		t.Errorf("maskResult left rows %v", result.rows)
+	}
+}
+
+func TestMaskValueHash(t *testing.T) {
+	policy := maskingConfig{Mode: maskModeHash, Salt: "salt"}.policy()
+
+	first, second := policy.maskValue("secret"), policy.maskValue("secret")
+	if first != second {
+		t.Errorf("maskValue hashed equal values to %v and %v", first, second)
+	}
+	if text, _ := first.(string); !strings.HasPrefix(text, "hash:") || strings.Contains(text, "secret") {
+		t.Errorf("maskValue = %v, want a hash", first)
+	}
+	if policy.maskValue(nil) != nil {
+		t.Errorf("maskValue(nil) is not nil")
+	}
+}
+
+func TestMaskTableProfile(t *testing.T) {
+	policy := maskingConfig{Columns: []string{"ssn"}, Detectors: []string{detectorEmail}}.policy()
+	profile := tableProfile{columns: []columnProfile{
+		{name: "id", comparable: true, min: int64(1), max: int64(9), topValues: []valueCount{{value: int64(1), count: 1}}},
+		{name: "ssn", comparable: true, min: "111-11-1111", max: "999-99-9999", distinct: 9},
+		{name: "contact", comparable: true, min: "a", max: "z", topValues: []valueCount{{value: "jane@example.com", count: 3}}},
+	}}
+
+	profile.mask(policy)
+
+	if len(profile.masked) != 2 {
+		t.Fatalf("mask masked %v, want ssn and contact", profile.masked)
+	}
+	if profile.columns[0].max != int64(9) {
+		t.Errorf("mask changed unmasked column id")
+	}
+	ssn := profile.columns[1]
+	if ssn.min != maskedValue || ssn.max != maskedValue || ssn.distinct != 9 {
+		t.Errorf("mask left ssn as %+v", ssn)
+	}
+	contact := profile.columns[2]
+	if contact.min != maskedValue || contact.topValues[0].value != maskedValue || contact.topValues[0].count != 3 {
+		t.Errorf("mask left contact as %+v", contact)
+	}
+}
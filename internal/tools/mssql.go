@@ -4,25 +4,28 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
 
-	_ "github.com/denisenkom/go-mssqldb"
+	mssql "github.com/denisenkom/go-mssqldb"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // sqlServerImpl implements the interfaces.Database interface
 type sqlServerImpl struct {
-	db *sql.DB
+	config connectionConfig
+	db     *sql.DB
 }
 
 // Connect establishes a connection to the database
 func (s *sqlServerImpl) Connect() error {
 	var err error
-	s.db, err = createConnection()
+	s.db, err = createConnection(s.config)
 	return err
 }
 
@@ -34,262 +37,505 @@ func (s *sqlServerImpl) Disconnect() error {
 	return nil
 }
 
-// Query executes a query and returns results
-func (s *sqlServerImpl) Query(query string, params ...any) ([]map[string]any, error) {
-	ctx := context.Background()
-	
+// Query executes a query and returns the rows of its first result set
+func (s *sqlServerImpl) Query(ctx context.Context, query string, params ...any) ([]map[string]any, error) {
+	return queryDatabase(ctx, s.db, s.config, s.convertValue, query, params...)
+}
+
+// QueryResultSets executes a query and returns the rows of every result set
+func (s *sqlServerImpl) QueryResultSets(ctx context.Context, query string, params ...any) ([][]map[string]any, error) {
+	return queryDatabaseResultSets(ctx, s.db, s.config, s.convertValue, query, params...)
+}
+
+// Execute runs a query that doesn't return results (INSERT, UPDATE, DELETE)
+func (s *sqlServerImpl) Execute(ctx context.Context, query string, params ...any) error {
+	if err := checkWritable(s.config); err != nil {
+		return err
+	}
+
 	// Convert params to a slice of interface{}
 	args := make([]interface{}, len(params))
 	copy(args, params)
-	
+
 	// Execute the query with parameters
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	_, err := s.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error executing query: %w", err)
+		return fmt.Errorf("error executing statement: %w", err)
 	}
-	defer rows.Close()
 
-	// Get column names
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("error getting column names: %w", err)
-	}
+	return nil
+}
+
+// queryPage executes a query and returns at most limit rows of its result
+// sets after skipping offset rows
+func (s *sqlServerImpl) queryPage(ctx context.Context, query string, offset int, limit int, params ...any) ([]queryResult, error) {
+	return queryDatabasePage(ctx, s.db, s.config, s.convertValue, query, offset, limit, params...)
+}
+
+// executeStatement runs a write statement in a transaction, committing it
+// only when commit is set, and returns the number of rows affected
+func (s *sqlServerImpl) executeStatement(ctx context.Context, statement string, commit bool) (int64, error) {
+	return executeInTransaction(ctx, s.db, s.config, statement, commit)
+}
+
+// Scopes of the key, index and constraint queries: every table, or the
+// table named by the @p1 schema and @p2 name parameters
+const (
+	sqlServerAllTables = "IN (SELECT object_id FROM sys.tables)"
+	sqlServerOneTable  = "= OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))"
+)
 
-	// Prepare result slice
-	var results []map[string]any
+// GetSchema returns the columns, keys, indexes and constraints of every
+// table. Each kind of information is read for all tables in one query.
+func (s *sqlServerImpl) GetSchema(ctx context.Context) (interfaces.SchemaInfo, error) {
+	query := `
+		SELECT
+			c.TABLE_SCHEMA,
+			c.TABLE_NAME,
+			c.COLUMN_NAME,
+			c.DATA_TYPE,
+			c.CHARACTER_MAXIMUM_LENGTH,
+			c.NUMERIC_PRECISION,
+			c.NUMERIC_SCALE,
+			c.DATETIME_PRECISION,
+			c.IS_NULLABLE,
+			c.COLUMN_DEFAULT
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY c.TABLE_SCHEMA, c.TABLE_NAME, c.ORDINAL_POSITION
+	`
 
-	// Prepare values for scan
-	values := make([]interface{}, len(columns))
-	valuePtrs := make([]interface{}, len(columns))
-	for i := range columns {
-		valuePtrs[i] = &values[i]
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error getting columns: %w", err)
 	}
+	defer rows.Close()
 
-	// Iterate through rows
+	result := interfaces.SchemaInfo{DatabaseName: s.config.Database}
 	for rows.Next() {
-		err = rows.Scan(valuePtrs...)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning row: %w", err)
+		var (
+			schemaName, relName, dataType, nullable        string
+			maxLength, precision, scale, datetimePrecision sql.NullInt64
+			column                                         interfaces.ColumnInfo
+			defaultValue                                   *string
+		)
+		if err := rows.Scan(&schemaName, &relName, &column.Name, &dataType, &maxLength, &precision, &scale, &datetimePrecision,
+			&nullable, &defaultValue); err != nil {
+			return interfaces.SchemaInfo{}, fmt.Errorf("error scanning column: %w", err)
+		}
+		column.Type = sqlServerColumnType(dataType, maxLength, precision, scale, datetimePrecision)
+		column.Nullable = nullable == "YES"
+		if defaultValue != nil {
+			column.DefaultValue = *defaultValue
 		}
 
-		// Create a map for this row
-		row := make(map[string]any)
-		for i, col := range columns {
-			var v any
-			val := values[i]
-			
-			// Convert bytes to string if needed
-			b, ok := val.([]byte)
-			if ok {
-				v = string(b)
-			} else {
-				v = val
-			}
-			
-			row[col] = v
+		// Rows are ordered by table, one per column
+		if n := len(result.Tables); n == 0 || result.Tables[n-1].Schema != schemaName || result.Tables[n-1].TableName != relName {
+			result.Tables = append(result.Tables, interfaces.TableSchema{Schema: schemaName, TableName: relName})
 		}
-		
-		results = append(results, row)
+		current := &result.Tables[len(result.Tables)-1]
+		current.Columns = append(current.Columns, column)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+	if err := rows.Err(); err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error iterating columns: %w", err)
 	}
 
-	return results, nil
+	tables := make([]*interfaces.TableSchema, len(result.Tables))
+	for i := range result.Tables {
+		tables[i] = &result.Tables[i]
+	}
+	if err := s.getKeys(ctx, tables, sqlServerAllTables); err != nil {
+		return interfaces.SchemaInfo{}, err
+	}
+
+	return result, nil
 }
 
-// Execute runs a query that doesn't return results (INSERT, UPDATE, DELETE)
-func (s *sqlServerImpl) Execute(query string, params ...any) error {
-	ctx := context.Background()
-	
-	// Convert params to a slice of interface{}
-	args := make([]interface{}, len(params))
-	copy(args, params)
-	
-	// Execute the query with parameters
-	_, err := s.db.ExecContext(ctx, query, args...)
+// GetTables returns all table names
+func (s *sqlServerImpl) GetTables(ctx context.Context) ([]string, error) {
+	return s.getDBTables(ctx)
+}
+
+// GetTableSchema returns column information for a specific table.
+// tableName may be schema-qualified ("audit.Orders"); unqualified names
+// are looked up in the user's default schema first, then in any schema
+// holding a single table of that name.
+func (s *sqlServerImpl) GetTableSchema(ctx context.Context, tableName string) (interfaces.TableSchema, error) {
+	schemaName, relName := splitTableName(tableName)
+	if schemaName == "" {
+		var err error
+		if schemaName, err = s.resolveTableSchema(ctx, relName); err != nil {
+			return interfaces.TableSchema{}, err
+		}
+	}
+
+	// Get column information from the database
+	columns, err := s.getTableColumns(ctx, schemaName, relName)
 	if err != nil {
-		return fmt.Errorf("error executing statement: %w", err)
+		return interfaces.TableSchema{}, fmt.Errorf("error getting table schema: %w", err)
 	}
-	
-	return nil
+
+	if len(columns) == 0 {
+		return interfaces.TableSchema{}, fmt.Errorf("table %s not found", tableName)
+	}
+
+	result := interfaces.TableSchema{
+		Schema:    schemaName,
+		TableName: relName,
+		Columns:   columns,
+	}
+
+	if err := s.getTableKeys(ctx, &result); err != nil {
+		return interfaces.TableSchema{}, err
+	}
+
+	return result, nil
 }
 
-// GetSchema returns database schema information
-func (s *sqlServerImpl) GetSchema() (interfaces.SchemaInfo, error) {
-	ctx := context.Background()
-	// We don't use schemas directly but we might in the future
-	_, err := s.getDBSchemas(ctx)
+// resolveTableSchema returns the schema of an unqualified table name. The
+// user's default schema wins, as it does on the server; otherwise the name
+// must be unique across schemas.
+func (s *sqlServerImpl) resolveTableSchema(ctx context.Context, relName string) (string, error) {
+	query := `
+		SELECT TABLE_SCHEMA
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_NAME = @p1
+		ORDER BY CASE WHEN TABLE_SCHEMA = SCHEMA_NAME() THEN 0 ELSE 1 END, TABLE_SCHEMA
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, relName)
 	if err != nil {
-		return interfaces.SchemaInfo{}, fmt.Errorf("error getting schemas: %w", err)
+		return "", fmt.Errorf("error resolving table %s: %w", relName, err)
 	}
-	
-	tables, err := s.getDBTables(ctx)
+	defer rows.Close()
+
+	schemas, err := scanStrings(rows)
 	if err != nil {
-		return interfaces.SchemaInfo{}, fmt.Errorf("error getting tables: %w", err)
+		return "", fmt.Errorf("error resolving table %s: %w", relName, err)
+	}
+
+	switch {
+	case len(schemas) == 0:
+		return "", fmt.Errorf("table %s not found", relName)
+	case len(schemas) == 1:
+		return schemas[0], nil
+	}
+
+	var defaultSchema string
+	if err := s.db.QueryRowContext(ctx, "SELECT SCHEMA_NAME()").Scan(&defaultSchema); err != nil {
+		return "", fmt.Errorf("error resolving table %s: %w", relName, err)
 	}
-	
-	result := interfaces.SchemaInfo{
-		DatabaseName: os.Getenv("SQL_DATABASE"),
-		Tables:       make([]interfaces.TableSchema, 0, len(tables)),
+	if schemas[0] == defaultSchema {
+		return defaultSchema, nil
 	}
-	
-	// Collect schema information for each table
-	for _, tableName := range tables {
-		tableSchema, err := s.GetTableSchema(tableName)
-		if err != nil {
-			return interfaces.SchemaInfo{}, fmt.Errorf("error getting schema for table %s: %w", tableName, err)
+
+	candidates := make([]string, len(schemas))
+	for i, schemaName := range schemas {
+		candidates[i] = schemaName + "." + relName
+	}
+	return "", fmt.Errorf("table name %s is ambiguous, qualify it with its schema: %s", relName, strings.Join(candidates, ", "))
+}
+
+// getTableKeys fills in the primary key, foreign keys, indexes and
+// constraints of a table
+func (s *sqlServerImpl) getTableKeys(ctx context.Context, schema *interfaces.TableSchema) error {
+	return s.getKeys(ctx, []*interfaces.TableSchema{schema}, sqlServerOneTable, schema.Schema, schema.TableName)
+}
+
+// sqlServerTableKey identifies a table in the results of the key, index
+// and constraint queries
+type sqlServerTableKey struct {
+	schema string
+	name   string
+}
+
+// getKeys fills in the primary keys, foreign keys, indexes and constraints
+// of the tables in scope, one of the sqlServer*Tables constants with its
+// parameters. Rows of tables not in tables are skipped.
+func (s *sqlServerImpl) getKeys(ctx context.Context, tables []*interfaces.TableSchema, scope string, args ...any) error {
+	byKey := make(map[sqlServerTableKey]*interfaces.TableSchema, len(tables))
+	for _, table := range tables {
+		byKey[sqlServerTableKey{table.Schema, table.TableName}] = table
+	}
+
+	uniqueConstraints, err := s.getTableIndexes(ctx, byKey, scope, args...)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		keysFromIndexes(table, uniqueConstraints[table])
+		markPrimaryKeyColumns(table)
+	}
+
+	if err := s.getTableForeignKeys(ctx, byKey, scope, args...); err != nil {
+		return err
+	}
+
+	return s.getTableCheckConstraints(ctx, byKey, scope, args...)
+}
+
+// getTableIndexes adds the indexes of the tables in scope to their schemas
+// and returns the names of those backing unique constraints per table
+func (s *sqlServerImpl) getTableIndexes(ctx context.Context, tables map[sqlServerTableKey]*interfaces.TableSchema, scope string, args ...any) (map[*interfaces.TableSchema]map[string]bool, error) {
+	query := `
+		SELECT
+			OBJECT_SCHEMA_NAME(i.object_id),
+			OBJECT_NAME(i.object_id),
+			i.name,
+			i.type_desc,
+			i.is_unique,
+			i.is_primary_key,
+			i.is_unique_constraint,
+			ISNULL(i.filter_definition, ''),
+			c.name,
+			ic.is_included_column
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id ` + scope + `
+			AND i.type > 0
+		ORDER BY i.object_id, i.name, ic.is_included_column, ic.key_ordinal, ic.index_column_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting indexes: %w", err)
+	}
+	defer rows.Close()
+
+	uniqueConstraints := make(map[*interfaces.TableSchema]map[string]bool)
+	for rows.Next() {
+		var (
+			key                            sqlServerTableKey
+			index                          interfaces.IndexInfo
+			isUniqueConstraint, isIncluded bool
+			column                         string
+		)
+		if err := rows.Scan(&key.schema, &key.name, &index.Name, &index.Type, &index.Unique, &index.Primary, &isUniqueConstraint, &index.Filter, &column, &isIncluded); err != nil {
+			return nil, fmt.Errorf("error scanning index: %w", err)
+		}
+		schema, ok := tables[key]
+		if !ok {
+			continue
+		}
+
+		// Rows are ordered by table and index, one per column
+		if n := len(schema.Indexes); n == 0 || schema.Indexes[n-1].Name != index.Name {
+			schema.Indexes = append(schema.Indexes, index)
+		}
+		current := &schema.Indexes[len(schema.Indexes)-1]
+		if isIncluded {
+			current.IncludedColumns = append(current.IncludedColumns, column)
+		} else {
+			current.Columns = append(current.Columns, column)
+		}
+
+		if isUniqueConstraint {
+			if uniqueConstraints[schema] == nil {
+				uniqueConstraints[schema] = make(map[string]bool)
+			}
+			uniqueConstraints[schema][index.Name] = true
 		}
-		result.Tables = append(result.Tables, tableSchema)
 	}
-	
-	return result, nil
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return uniqueConstraints, nil
 }
 
-// GetTables returns all table names
-func (s *sqlServerImpl) GetTables() ([]string, error) {
-	ctx := context.Background()
-	return s.getDBTables(ctx)
+// getTableForeignKeys adds the foreign keys declared on the tables in scope
+// to their schemas
+func (s *sqlServerImpl) getTableForeignKeys(ctx context.Context, tables map[sqlServerTableKey]*interfaces.TableSchema, scope string, args ...any) error {
+	query := `
+		SELECT
+			OBJECT_SCHEMA_NAME(fk.parent_object_id),
+			OBJECT_NAME(fk.parent_object_id),
+			fk.name,
+			OBJECT_SCHEMA_NAME(fk.referenced_object_id) + '.' + OBJECT_NAME(fk.referenced_object_id),
+			fk.delete_referential_action_desc,
+			fk.update_referential_action_desc,
+			pc.name,
+			rc.name
+		FROM sys.foreign_keys fk
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		WHERE fk.parent_object_id ` + scope + `
+		ORDER BY fk.parent_object_id, fk.name, fkc.constraint_column_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error getting foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key                      sqlServerTableKey
+			fk                       interfaces.ForeignKeyInfo
+			column, referencedColumn string
+		)
+		if err := rows.Scan(&key.schema, &key.name, &fk.Name, &fk.ReferencedTable, &fk.OnDelete, &fk.OnUpdate, &column, &referencedColumn); err != nil {
+			return fmt.Errorf("error scanning foreign key: %w", err)
+		}
+		schema, ok := tables[key]
+		if !ok {
+			continue
+		}
+
+		// Rows are ordered by table and foreign key, one per column
+		if n := len(schema.ForeignKeys); n == 0 || schema.ForeignKeys[n-1].Name != fk.Name {
+			fk.OnDelete, fk.OnUpdate = referentialAction(fk.OnDelete), referentialAction(fk.OnUpdate)
+			schema.ForeignKeys = append(schema.ForeignKeys, fk)
+		}
+		current := &schema.ForeignKeys[len(schema.ForeignKeys)-1]
+		current.Columns = append(current.Columns, column)
+		current.ReferencedColumns = append(current.ReferencedColumns, referencedColumn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	return nil
 }
 
-// GetTableSchema returns column information for a specific table
-func (s *sqlServerImpl) GetTableSchema(tableName string) (interfaces.TableSchema, error) {
-	ctx := context.Background()
-	
-	// Get column information from the database
-	columns, err := s.getTableColumns(ctx, tableName)
+// getTableCheckConstraints adds the check constraints of the tables in
+// scope to their schemas
+func (s *sqlServerImpl) getTableCheckConstraints(ctx context.Context, tables map[sqlServerTableKey]*interfaces.TableSchema, scope string, args ...any) error {
+	// parent_column_id is 0 for table-level constraints
+	query := `
+		SELECT
+			OBJECT_SCHEMA_NAME(cc.parent_object_id),
+			OBJECT_NAME(cc.parent_object_id),
+			cc.name,
+			ISNULL(COL_NAME(cc.parent_object_id, NULLIF(cc.parent_column_id, 0)), ''),
+			cc.definition
+		FROM sys.check_constraints cc
+		WHERE cc.parent_object_id ` + scope + `
+		ORDER BY cc.parent_object_id, cc.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return interfaces.TableSchema{}, fmt.Errorf("error getting table schema: %w", err)
+		return fmt.Errorf("error getting check constraints: %w", err)
 	}
-	
-	// Convert to the required format
-	result := interfaces.TableSchema{
-		TableName: tableName,
-		Columns:   make([]interfaces.ColumnInfo, 0, len(columns)),
-	}
-	
-	for _, col := range columns {
-		columnInfo := interfaces.ColumnInfo{
-			Name:     col["COLUMN_NAME"].(string),
-			Type:     col["DATA_TYPE"].(string),
-			Nullable: col["IS_NULLABLE"].(string) == "YES",
-		}
-		
-		// Handle default value if present
-		if defaultVal, ok := col["COLUMN_DEFAULT"]; ok && defaultVal != nil {
-			columnInfo.DefaultValue = defaultVal
-		}
-		
-		// Check if the column is a primary key - would need additional query
-		// This is a simplified version
-		
-		result.Columns = append(result.Columns, columnInfo)
-	}
-	
-	return result, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key    sqlServerTableKey
+			column string
+		)
+		constraint := interfaces.ConstraintInfo{Type: constraintCheck}
+		if err := rows.Scan(&key.schema, &key.name, &constraint.Name, &column, &constraint.Definition); err != nil {
+			return fmt.Errorf("error scanning check constraint: %w", err)
+		}
+		schema, ok := tables[key]
+		if !ok {
+			continue
+		}
+		if column != "" {
+			constraint.Columns = []string{column}
+		}
+		schema.Constraints = append(schema.Constraints, constraint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating check constraints: %w", err)
+	}
+
+	return nil
 }
 
-// getDBTables returns a list of all tables in the database
+// getDBTables returns the schema-qualified names of all tables in the
+// database
 func (s *sqlServerImpl) getDBTables(ctx context.Context) ([]string, error) {
 	query := `
-		SELECT TABLE_NAME 
+		SELECT TABLE_SCHEMA, TABLE_NAME 
 		FROM INFORMATION_SCHEMA.TABLES 
 		WHERE TABLE_TYPE = 'BASE TABLE' 
-		ORDER BY TABLE_NAME
+		ORDER BY TABLE_SCHEMA, TABLE_NAME
 	`
-	
+
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error getting tables: %w", err)
 	}
 	defer rows.Close()
-	
+
 	var tables []string
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, fmt.Errorf("error scanning table name: %w", err)
+		var schemaName, relName string
+		if err := rows.Scan(&schemaName, &relName); err != nil {
+			return nil, fmt.Errorf("error scanning table: %w", err)
 		}
-		tables = append(tables, tableName)
+		tables = append(tables, qualifyTableName(schemaName, relName))
 	}
-	
-	if err = rows.Err(); err != nil {
+
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating tables: %w", err)
 	}
-	
+
 	return tables, nil
 }
 
-// getTableColumns returns the schema of a specific table
-func (s *sqlServerImpl) getTableColumns(ctx context.Context, tableName string) ([]map[string]any, error) {
+// getTableColumns returns the columns of a specific table, with their
+// types as declared, such as nvarchar(50) or decimal(18,2)
+func (s *sqlServerImpl) getTableColumns(ctx context.Context, schemaName string, relName string) ([]interfaces.ColumnInfo, error) {
 	query := `
 		SELECT 
 			COLUMN_NAME, 
 			DATA_TYPE, 
 			CHARACTER_MAXIMUM_LENGTH, 
+			NUMERIC_PRECISION,
+			NUMERIC_SCALE,
+			DATETIME_PRECISION,
 			IS_NULLABLE, 
 			COLUMN_DEFAULT 
 		FROM INFORMATION_SCHEMA.COLUMNS 
-		WHERE TABLE_NAME = @p1 
+		WHERE TABLE_SCHEMA = @p1 
+			AND TABLE_NAME = @p2 
 		ORDER BY ORDINAL_POSITION
 	`
-	
+
 	// Execute the query with parameters
-	rows, err := s.db.QueryContext(ctx, query, tableName)
+	rows, err := s.db.QueryContext(ctx, query, schemaName, relName)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err)
 	}
 	defer rows.Close()
 
-	// Get column names
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("error getting column names: %w", err)
-	}
-
-	// Prepare result slice
-	var results []map[string]any
-
-	// Prepare values for scan
-	values := make([]interface{}, len(columns))
-	valuePtrs := make([]interface{}, len(columns))
-	for i := range columns {
-		valuePtrs[i] = &values[i]
-	}
-
-	// Iterate through rows
+	var columns []interfaces.ColumnInfo
 	for rows.Next() {
-		err = rows.Scan(valuePtrs...)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning row: %w", err)
+		var (
+			column                                         interfaces.ColumnInfo
+			dataType, nullable                             string
+			maxLength, precision, scale, datetimePrecision sql.NullInt64
+			defaultValue                                   *string
+		)
+		if err := rows.Scan(&column.Name, &dataType, &maxLength, &precision, &scale, &datetimePrecision, &nullable, &defaultValue); err != nil {
+			return nil, fmt.Errorf("error scanning column: %w", err)
 		}
 
-		// Create a map for this row
-		row := make(map[string]any)
-		for i, col := range columns {
-			var v any
-			val := values[i]
-			
-			// Convert bytes to string if needed
-			b, ok := val.([]byte)
-			if ok {
-				v = string(b)
-			} else {
-				v = val
-			}
-			
-			row[col] = v
+		column.Type = sqlServerColumnType(dataType, maxLength, precision, scale, datetimePrecision)
+		column.Nullable = nullable == "YES"
+		if defaultValue != nil {
+			column.DefaultValue = *defaultValue
 		}
-		
-		results = append(results, row)
+		columns = append(columns, column)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %w", err)
 	}
 
-	return results, nil
+	return columns, nil
 }
 
 // getDBSchemas returns a list of all schemas in the database
@@ -299,220 +545,935 @@ func (s *sqlServerImpl) getDBSchemas(ctx context.Context) ([]string, error) {
 		FROM INFORMATION_SCHEMA.SCHEMATA 
 		ORDER BY SCHEMA_NAME
 	`
-	
+
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error getting schemas: %w", err)
 	}
 	defer rows.Close()
-	
-	var schemas []string
+
+	return scanStrings(rows)
+}
+
+// GetViews returns the schema-qualified names of all views
+func (s *sqlServerImpl) GetViews(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT SCHEMA_NAME(v.schema_id), v.name
+		FROM sys.views v
+		WHERE v.is_ms_shipped = 0
+		ORDER BY SCHEMA_NAME(v.schema_id), v.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []string
 	for rows.Next() {
-		var schemaName string
-		if err := rows.Scan(&schemaName); err != nil {
-			return nil, fmt.Errorf("error scanning schema name: %w", err)
+		var schemaName, viewName string
+		if err := rows.Scan(&schemaName, &viewName); err != nil {
+			return nil, fmt.Errorf("error scanning view: %w", err)
 		}
-		schemas = append(schemas, schemaName)
+		views = append(views, qualifyTableName(schemaName, viewName))
 	}
-	
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating schemas: %w", err)
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating views: %w", err)
 	}
-	
-	return schemas, nil
+
+	return views, nil
 }
 
-// createConnection establishes a connection to the SQL Server
-func createConnection() (*sql.DB, error) {
-	server := os.Getenv("SQL_SERVER")
-	port := os.Getenv("SQL_PORT")
-	user := os.Getenv("SQL_USER")
-	password := os.Getenv("SQL_PASSWORD")
-	database := os.Getenv("SQL_DATABASE")
-	
-	// Connection string format for Microsoft's driver - updated for macOS compatibility
-	connectionString := fmt.Sprintf("Server=%s,%s;User ID=%s;Password=%s;Database=%s;Encrypt=disable;TrustServerCertificate=true", 
-		server, port, user, password, database)
-	
-	fmt.Printf("Connecting to SQL Server with connection string: %s\n", 
-		strings.Replace(connectionString, password, "********", 1))
-	
-	db, err := sql.Open("mssql", connectionString)
+// sqlServerRoutineTypes are the sys.objects types of procedures and
+// functions: T-SQL and CLR procedures, scalar functions and inline and
+// multi-statement table-valued functions
+const sqlServerRoutineTypes = "'P', 'PC', 'FN', 'FS', 'IF', 'TF', 'FT'"
+
+// GetRoutines returns all user stored procedures and functions
+func (s *sqlServerImpl) GetRoutines(ctx context.Context) ([]interfaces.RoutineInfo, error) {
+	// Parameter 0 of a scalar function describes its return value
+	query := `
+		SELECT
+			SCHEMA_NAME(o.schema_id),
+			o.name,
+			o.type,
+			ISNULL(TYPE_NAME(r.user_type_id), ''),
+			ISNULL(r.max_length, 0),
+			ISNULL(r.precision, 0),
+			ISNULL(r.scale, 0)
+		FROM sys.objects o
+		LEFT JOIN sys.parameters r ON r.object_id = o.object_id AND r.parameter_id = 0
+		WHERE o.type IN (` + sqlServerRoutineTypes + `)
+			AND o.is_ms_shipped = 0
+		ORDER BY SCHEMA_NAME(o.schema_id), o.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("error connecting to SQL Server: %w", err)
+		return nil, fmt.Errorf("error getting routines: %w", err)
 	}
-	
-	// Test the connection
-	err = db.Ping()
-	if err != nil {
-		return nil, fmt.Errorf("error connecting to SQL Server: %w", err)
+	defer rows.Close()
+
+	var routines []interfaces.RoutineInfo
+	for rows.Next() {
+		var (
+			routine                     interfaces.RoutineInfo
+			objectType, returnType      string
+			maxLength, precision, scale int
+		)
+		if err := rows.Scan(&routine.Schema, &routine.Name, &objectType, &returnType, &maxLength, &precision, &scale); err != nil {
+			return nil, fmt.Errorf("error scanning routine: %w", err)
+		}
+
+		routine.Type, routine.ReturnType = sqlServerRoutineKind(objectType, sqlServerTypeName(returnType, maxLength, precision, scale))
+		routines = append(routines, routine)
 	}
-	
-	return db, nil
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating routines: %w", err)
+	}
+
+	return routines, nil
 }
 
-// NewSQLServerTool creates a new instance of SQLServerTool
-func NewSQLServerTool(server *server.MCPServer) interfaces.Database {
-	// Create a new SQL Server implementation
-	sqlServerTool := &sqlServerImpl{}
-	
-	// Connect to the database
-	err := sqlServerTool.Connect()
+// sqlServerRoutineKind maps a sys.objects type to the object type and
+// return type reported for it
+func sqlServerRoutineKind(objectType string, returnType string) (string, string) {
+	switch strings.TrimSpace(objectType) {
+	case "V":
+		return objectView, ""
+	case "P", "PC":
+		return objectProcedure, ""
+	case "IF", "TF", "FT":
+		return objectFunction, "TABLE"
+	}
+	return objectFunction, returnType
+}
+
+// GetObjectDefinition returns the source and parameters of a view,
+// procedure or function. Unqualified names resolve as they would on the
+// server, through the user's default schema and then dbo.
+func (s *sqlServerImpl) GetObjectDefinition(ctx context.Context, name string) (interfaces.ObjectDefinition, error) {
+	schemaName, objectName := splitTableName(name)
+
+	// OBJECT_DEFINITION returns NULL for encrypted and CLR modules
+	query := `
+		SELECT
+			o.object_id,
+			SCHEMA_NAME(o.schema_id),
+			o.name,
+			o.type,
+			ISNULL(OBJECT_DEFINITION(o.object_id), '')
+		FROM sys.objects o
+		WHERE o.object_id = OBJECT_ID(CASE WHEN @p1 = '' THEN QUOTENAME(@p2) ELSE QUOTENAME(@p1) + '.' + QUOTENAME(@p2) END)
+			AND o.type IN ('V', ` + sqlServerRoutineTypes + `)
+	`
+
+	var (
+		objectID   int64
+		objectType string
+		result     interfaces.ObjectDefinition
+	)
+	err := s.db.QueryRowContext(ctx, query, schemaName, objectName).Scan(&objectID, &result.Schema, &result.Name, &objectType, &result.Definition)
+	if err == sql.ErrNoRows {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("view, procedure or function %s not found", name)
+	}
 	if err != nil {
-		// Log the error but continue without the tool
-		fmt.Printf("Failed to initialize SQL Server tool: %v\n", err)
-		return nil
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error getting definition of %s: %w", name, err)
 	}
 
-	fmt.Println("SQL Server tool initialized successfully")
-	
-	// Add the SQL Server tools to the MCP server
-	if server != nil {
-		// Register tool for executing SQL queries
-		executeQueryTool := mcp.NewTool("sql_execute_query",
-			mcp.WithDescription("Execute a SQL query"),
-			mcp.WithString("query",
-				mcp.Required(),
-				mcp.Description("The SQL query to execute"),
-			),
+	query = `
+		SELECT
+			p.parameter_id,
+			p.name,
+			TYPE_NAME(p.user_type_id),
+			p.max_length,
+			p.precision,
+			p.scale,
+			p.is_output
+		FROM sys.parameters p
+		WHERE p.object_id = @p1
+		ORDER BY p.parameter_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, objectID)
+	if err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error getting parameters of %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	returnType := ""
+	for rows.Next() {
+		var (
+			parameterID, maxLength, precision, scale int
+			parameterName, typeName                  string
+			isOutput                                 bool
 		)
-		
-		server.AddTool(executeQueryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			query, ok := request.Params.Arguments["query"].(string)
-			if !ok {
-				return mcp.NewToolResultError("query must be a string"), nil
+		if err := rows.Scan(&parameterID, &parameterName, &typeName, &maxLength, &precision, &scale, &isOutput); err != nil {
+			return interfaces.ObjectDefinition{}, fmt.Errorf("error scanning parameter: %w", err)
+		}
+
+		typeName = sqlServerTypeName(typeName, maxLength, precision, scale)
+		if parameterID == 0 {
+			// The return value of a scalar function
+			returnType = typeName
+			continue
+		}
+
+		// OUTPUT parameters also pass a value in
+		parameter := interfaces.ParameterInfo{Name: parameterName, Type: typeName, Mode: "IN"}
+		if isOutput {
+			parameter.Mode = "INOUT"
+		}
+		result.Parameters = append(result.Parameters, parameter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error iterating parameters: %w", err)
+	}
+
+	result.Type, result.ReturnType = sqlServerRoutineKind(objectType, returnType)
+	return result, nil
+}
+
+// quoteSQLServerName returns the bracket-quoted name of an object in a
+// schema, such as [sales].[Order Items]
+func quoteSQLServerName(schemaName string, objectName string) string {
+	return quoteSQLServerIdentifier(schemaName) + "." + quoteSQLServerIdentifier(objectName)
+}
+
+// quoteSQLServerIdentifier bracket-quotes an identifier
+func quoteSQLServerIdentifier(identifier string) string {
+	return "[" + strings.ReplaceAll(identifier, "]", "]]") + "]"
+}
+
+// callProcedure calls a stored procedure on the connection's allow-list
+// with named parameters. OUTPUT parameters are always bound, starting out
+// NULL unless given a value, and at most limit rows of each result set are
+// returned. On read-only connections the call runs in a transaction that
+// is rolled back afterwards.
+func (s *sqlServerImpl) callProcedure(ctx context.Context, name string, parameters map[string]any, limit int) (procedureResult, error) {
+	definition, err := s.GetObjectDefinition(ctx, name)
+	if err != nil {
+		return procedureResult{}, err
+	}
+	if definition.Type != objectProcedure {
+		return procedureResult{}, fmt.Errorf("%s is a %s, not a stored procedure", name, strings.ToLower(definition.Type))
+	}
+
+	result := procedureResult{procedure: qualifyTableName(definition.Schema, definition.Name)}
+	if !procedureAllowed(s.config.ProcedureAllowList, definition.Schema, definition.Name) {
+		return procedureResult{}, fmt.Errorf("procedure %s is not on the procedure allow-list of connection %q", result.procedure, s.config.Name)
+	}
+
+	declared := make(map[string]bool, len(definition.Parameters))
+	for _, parameter := range definition.Parameters {
+		declared[strings.ToLower(strings.TrimPrefix(parameter.Name, "@"))] = true
+	}
+	for parameterName := range parameters {
+		if !declared[parameterName] {
+			return procedureResult{}, fmt.Errorf("procedure %s has no parameter @%s", result.procedure, parameterName)
+		}
+	}
+
+	// Bind the parameters by name, keeping a destination for each OUTPUT
+	// parameter
+	var (
+		args         []any
+		outputs      []*any
+		returnStatus mssql.ReturnStatus
+	)
+	for _, parameter := range definition.Parameters {
+		parameterName := strings.TrimPrefix(parameter.Name, "@")
+		value, given := parameters[strings.ToLower(parameterName)]
+
+		if parameter.Mode == "IN" {
+			if !given {
+				// Leave the parameter to its default
+				continue
 			}
-			
-			results, err := sqlServerTool.Query(query)
+			value, err = sqlServerParameterValue(parameter.Type, value)
 			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
+				return procedureResult{}, fmt.Errorf("parameter %s: %w", parameter.Name, err)
 			}
-			
-			// Format results as text
-			var resultText strings.Builder
-			resultText.WriteString(fmt.Sprintf("Query executed with %d results:\n\n", len(results)))
-			
-			if len(results) > 0 {
-				// Get column names from the first result
-				var columns []string
-				for col := range results[0] {
-					columns = append(columns, col)
+			args = append(args, sql.Named(parameterName, value))
+			continue
+		}
+
+		dest := new(any)
+		*dest, err = sqlServerOutputParameterValue(parameter.Type, value)
+		if err != nil {
+			return procedureResult{}, fmt.Errorf("parameter %s: %w", parameter.Name, err)
+		}
+		args = append(args, sql.Named(parameterName, sql.Out{Dest: dest}))
+		outputs = append(outputs, dest)
+	}
+	args = append(args, &returnStatus)
+
+	var source queryer = s.db
+	if s.config.ReadOnly {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return procedureResult{}, fmt.Errorf("error starting transaction: %w", err)
+		}
+		// Never committed, so the procedure cannot change any data
+		defer tx.Rollback()
+		source = tx
+		result.rolledBack = true
+	}
+
+	// A bracket-quoted name alone is sent as a remote procedure call
+	procedure := quoteSQLServerName(definition.Schema, definition.Name)
+	rows, err := source.QueryContext(ctx, procedure, args...)
+	if err != nil {
+		return procedureResult{}, fmt.Errorf("error calling procedure %s: %w", result.procedure, err)
+	}
+	defer rows.Close()
+
+	for {
+		set, err := scanRowsPage(rows, s.convertValue, 0, limit)
+		if err != nil {
+			return procedureResult{}, fmt.Errorf("error reading results of %s: %w", result.procedure, err)
+		}
+
+		// The driver only moves on to the next result set once every row
+		// of the current one has been read
+		rowCount := len(set.rows)
+		if set.more {
+			for rowCount++; rows.Next(); rowCount++ {
+			}
+		}
+
+		// Statements that return no rows do not produce a result set
+		if len(set.columns) > 0 {
+			result.resultSets = append(result.resultSets, set)
+			result.rowCounts = append(result.rowCounts, rowCount)
+		}
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return procedureResult{}, fmt.Errorf("error reading results of %s: %w", result.procedure, err)
+	}
+
+	// OUTPUT parameters and the return value are only set once all results
+	// have been consumed
+	if err := rows.Close(); err != nil {
+		return procedureResult{}, fmt.Errorf("error reading results of %s: %w", result.procedure, err)
+	}
+
+	i := 0
+	for _, parameter := range definition.Parameters {
+		if parameter.Mode == "IN" {
+			continue
+		}
+		typeName, _, _ := strings.Cut(parameter.Type, "(")
+		result.outputs = append(result.outputs, procedureOutput{
+			name:  parameter.Name,
+			value: s.convertTypedValue(strings.ToUpper(typeName), *outputs[i]),
+		})
+		i++
+	}
+	result.returnValue = int64(returnStatus)
+
+	return result, nil
+}
+
+// sampleRows reads count random rows of a table, or of the named columns
+// of it. Tables of more than sampleScanRows rows are first narrowed down to
+// a sample of their pages so that the random order does not sort the whole
+// table. TABLESAMPLE picks whole pages, so on sparsely filled tables the
+// sample can come back short; it is then taken again ten times larger, up
+// to sampleAttempts times, and the rows found are returned marked short.
+// The connection's masking policy is applied to the rows before they are
+// returned.
+func (s *sqlServerImpl) sampleRows(ctx context.Context, tableName string, columns []string, count int) (rowSample, error) {
+	schema, err := s.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return rowSample{}, err
+	}
+
+	selected, err := profileColumns(schema, columns)
+	if err != nil {
+		return rowSample{}, err
+	}
+	quoted := make([]string, len(selected))
+	for i, column := range selected {
+		quoted[i] = quoteSQLServerIdentifier(column.name)
+	}
+
+	name := quoteSQLServerName(schema.Schema, schema.TableName)
+
+	var rowCount int64
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(rows), 0)
+		FROM sys.partitions
+		WHERE object_id = OBJECT_ID(@p1) AND index_id IN (0, 1)`, name).Scan(&rowCount)
+	if err != nil {
+		return rowSample{}, fmt.Errorf("error counting rows of table %s: %w", schema.QualifiedName(), err)
+	}
+
+	sample := func(source string) (queryResult, error) {
+		query := fmt.Sprintf("SELECT TOP (@p1) %s FROM %s WITH (NOLOCK) ORDER BY NEWID() OPTION (MAXDOP 1)",
+			strings.Join(quoted, ", "), source)
+		rows, err := s.db.QueryContext(ctx, query, count)
+		if err != nil {
+			return queryResult{}, fmt.Errorf("error sampling table %s: %w", schema.QualifiedName(), err)
+		}
+		defer rows.Close()
+
+		return scanRowsPage(rows, s.convertValue, 0, 0)
+	}
+
+	var (
+		result queryResult
+		short  bool
+	)
+	if rowCount <= sampleScanRows {
+		if result, err = sample(name); err != nil {
+			return rowSample{}, err
+		}
+	} else {
+		sampleSize := int64(count) * 100
+		for attempt := 0; attempt < sampleAttempts && len(result.rows) < count; attempt++ {
+			result, err = sample(fmt.Sprintf("%s TABLESAMPLE SYSTEM (%d ROWS)", name, sampleSize))
+			if err != nil {
+				return rowSample{}, err
+			}
+			sampleSize *= 10
+		}
+		short = len(result.rows) < count
+	}
+
+	return rowSample{
+		table:  schema.QualifiedName(),
+		result: result,
+		masked: s.config.Masking.policy().maskResult(&result),
+		short:  short,
+	}, nil
+}
+
+// sqlServerConfigFromEnv reads the single SQL Server connection configured
+// through SQL_SERVER, SQL_PORT, SQL_USER, SQL_PASSWORD and SQL_DATABASE
+func sqlServerConfigFromEnv() connectionConfig {
+	return connectionConfig{
+		Name:     defaultConnectionName,
+		Engine:   engineSQLServer,
+		Host:     os.Getenv("SQL_SERVER"),
+		Port:     os.Getenv("SQL_PORT"),
+		User:     os.Getenv("SQL_USER"),
+		Password: os.Getenv("SQL_PASSWORD"),
+		Database: os.Getenv("SQL_DATABASE"),
+		ReadOnly: readOnlyFromEnv("SQL_READ_ONLY"),
+
+		MaxAffectedRows: intFromEnv("SQL_MAX_AFFECTED_ROWS", defaultMaxAffectedRows),
+		MaxRows:         intFromEnv("SQL_MAX_ROWS", defaultMaxRows),
+		MaxResultBytes:  intFromEnv("SQL_MAX_RESULT_BYTES", defaultMaxResultBytes),
+
+		StatementTimeout: secondsFromEnv("SQL_STATEMENT_TIMEOUT", defaultStatementTimeout),
+		BinaryEncoding:   binaryEncodingFromEnv("SQL_BINARY_ENCODING"),
+
+		ProcedureAllowList: listFromEnv("SQL_PROCEDURE_ALLOWLIST"),
+		ProfileTimeBudget:  secondsFromEnv("SQL_PROFILE_TIME_BUDGET", defaultProfileTimeBudget),
+		SchemaPollInterval: secondsFromEnv("SQL_SCHEMA_POLL_INTERVAL", defaultSchemaPollInterval),
+
+		Masking: maskingConfigFromEnv("SQL_"),
+	}
+}
+
+// createConnection establishes a connection to the SQL Server
+func createConnection(config connectionConfig) (*sql.DB, error) {
+	port := config.Port
+	if port == "" {
+		port = "1433"
+	}
+
+	// Connection string format for Microsoft's driver - updated for macOS compatibility
+	connectionString := fmt.Sprintf("Server=%s,%s;User ID=%s;Password=%s;Database=%s;Encrypt=disable;TrustServerCertificate=true",
+		config.Host, port, config.User, config.Password, config.Database)
+
+	// Route read-only connections to a readable secondary where the server
+	// is part of an availability group
+	if config.ReadOnly {
+		connectionString += ";ApplicationIntent=ReadOnly"
+	}
+
+	log.Printf("Connecting to SQL Server %q with connection string: %s", config.Name,
+		strings.Replace(connectionString, "Password="+config.Password+";", "Password=********;", 1))
+
+	db, err := sql.Open("mssql", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SQL Server: %w", err)
+	}
+
+	// Test the connection
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to SQL Server: %w", err)
+	}
+
+	return db, nil
+}
+
+// NewSQLServerTool creates a new instance of SQLServerTool. The sql_* tools
+// it registers work against every connection listed in SQL_CONNECTIONS, of
+// any engine, or against the single SQL_SERVER database when no named
+// connections are configured.
+func NewSQLServerTool(server *server.MCPServer) interfaces.Database {
+	configs, err := sqlConnectionConfigs()
+	if err != nil {
+		log.Printf("Failed to initialize SQL Server tool: %v", err)
+		return nil
+	}
+
+	// Connect to the databases
+	connections := openConnections(configs, true)
+	if connections.isEmpty() {
+		// Log the error but continue without the tool
+		log.Println("Failed to initialize SQL Server tool: no connection could be opened")
+		return nil
+	}
+
+	if defaultName := os.Getenv("SQL_DEFAULT_CONNECTION"); defaultName != "" {
+		if err := connections.setDefault(defaultName); err != nil {
+			log.Printf("Ignoring SQL_DEFAULT_CONNECTION: %v", err)
+		}
+	}
+
+	log.Printf("SQL Server tool initialized successfully with connections: %s", strings.Join(connections.names, ", "))
+
+	// Add the SQL Server tools to the MCP server
+	if server != nil {
+		engine := "SQL Server"
+		if len(configs) > 1 || configs[0].Engine != engineSQLServer {
+			engine = "selected"
+		}
+
+		// Register the query and table tools shared with the other backends
+		registerDatabaseTools(server, "sql", engine, connections)
+
+		// Register tool for getting database schemas
+		getSchemasTool := mcp.NewTool("sql_get_schemas", append([]mcp.ToolOption{
+			mcp.WithDescription("Get a list of all schemas in the database"),
+		}, connections.toolOptions()...)...)
+
+		server.AddTool(getSchemasTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			conn, err := connections.fromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			lister, ok := conn.db.(schemaLister)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support listing schemas", conn.config.Name, conn.config.Engine)), nil
+			}
+
+			queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			schemas, err := lister.getDBSchemas(queryCtx)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			return mcp.NewToolResultText(formatNameList("schemas", schemas)), nil
+		})
+
+		// Register tool for calling stored procedures
+		callProcedureTool := mcp.NewTool("sql_call_procedure", append([]mcp.ToolOption{
+			mcp.WithDescription("Call a stored procedure with named parameters and return every result set it produces, " +
+				"its OUTPUT parameters and its return value. Only procedures on the connection's procedure allow-list can be called. " +
+				"On read-only connections the call runs in a transaction that is rolled back, which cannot undo a procedure that commits " +
+				"its own transaction"),
+			mcp.WithString("procedure",
+				mcp.Required(),
+				mcp.Description(`The name of the stored procedure, optionally schema-qualified as "schema.procedure"`),
+			),
+			mcp.WithString("schema",
+				mcp.Description("The schema of the procedure, for an unqualified procedure name"),
+			),
+			mcp.WithObject("parameters",
+				mcp.Description(`JSON object of parameter values by name, e.g. {"@CustomerId": 42, "@Since": "2024-01-01"}. `+
+					`Values may carry a type hint as {"type": "int|decimal|datetime|uniqueidentifier|varbinary", "value": ...}. `+
+					"Binary values are given as 0x-prefixed hex or base64. OUTPUT parameters that are not given start out NULL"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: text (tab-separated, default), json (typed values with column metadata), csv or markdown"),
+				mcp.Enum(formatText, formatJSON, formatCSV, formatMarkdown),
+			),
+			mcp.WithNumber("max_rows",
+				mcp.Description(fmt.Sprintf("Maximum number of rows to return per result set (1 to %d). Defaults to the connection's limit", maxRowsCeiling)),
+			),
+			mcp.WithNumber("timeout_seconds",
+				mcp.Description("Cancel the call after this many seconds. Cannot exceed the connection's statement timeout"),
+			),
+		}, connections.toolOptions()...)...)
+
+		server.AddTool(callProcedureTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			conn, err := connections.fromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			caller, ok := conn.db.(procedureCaller)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support calling stored procedures", conn.config.Name, conn.config.Engine)), nil
+			}
+
+			name, err := qualifiedNameFromRequest(request, "procedure")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			parameters, err := procedureParametersFromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			format, err := parseResultFormat(request.Params.Arguments["format"])
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			maxRows := int(conn.config.MaxRows)
+			if value, ok := request.Params.Arguments["max_rows"].(float64); ok {
+				if value < 1 || value > maxRowsCeiling {
+					return mcp.NewToolResultError(fmt.Sprintf("max_rows must be between 1 and %d", maxRowsCeiling)), nil
 				}
-				
-				// Print column headers
-				for _, col := range columns {
-					resultText.WriteString(fmt.Sprintf("%s\t", col))
+				maxRows = int(value)
+			}
+
+			queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			result, err := caller.callProcedure(queryCtx, name, parameters, maxRows)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			output, err := formatProcedureResult(result, format, conn.config.MaxResultBytes)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			return mcp.NewToolResultText(output), nil
+		})
+
+		// Register tool for explaining queries
+		explainTool := mcp.NewTool("sql_explain", append([]mcp.ToolOption{
+			mcp.WithDescription("Show the execution plan of a query as an operator tree with estimated rows, the cost share of each operator, " +
+				"missing index suggestions and warnings such as implicit conversions. The estimated plan compiles the query without running it; " +
+				"the actual plan runs it, so it is only available for read-only queries"),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("The SQL query to explain"),
+			),
+			mcp.WithArray("parameters",
+				arrayOrObject(),
+				mcp.Description(`Values bound to the query's parameters: an array bound by position (@p1, @p2, ...) or an object bound by name (@name). `+
+					`A value can carry a type hint as {"type": "int|decimal|datetime|uniqueidentifier|varbinary", "value": ...}`),
+			),
+			mcp.WithString("mode",
+				mcp.Description("estimated (default) compiles the query without running it; actual runs it and adds the actual row counts"),
+				mcp.Enum(planModeEstimated, planModeActual),
+			),
+			mcp.WithBoolean("include_xml",
+				mcp.Description("Also return the raw showplan XML. Defaults to false"),
+			),
+			mcp.WithNumber("timeout_seconds",
+				mcp.Description("Cancel the query after this many seconds. Cannot exceed the connection's statement timeout"),
+			),
+		}, connections.toolOptions()...)...)
+
+		server.AddTool(explainTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			conn, err := connections.fromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			explainer, ok := conn.db.(planExplainer)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support execution plans", conn.config.Name, conn.config.Engine)), nil
+			}
+
+			query, ok := request.Params.Arguments["query"].(string)
+			if !ok || strings.TrimSpace(query) == "" {
+				return mcp.NewToolResultError("query must be a non-empty string"), nil
+			}
+
+			params, err := queryParametersFromRequest(request, conn.config.Engine)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			mode := planModeEstimated
+			if value, ok := request.Params.Arguments["mode"].(string); ok && value != "" {
+				mode = strings.ToLower(value)
+			}
+			if mode != planModeEstimated && mode != planModeActual {
+				return mcp.NewToolResultError(fmt.Sprintf("mode must be %s or %s", planModeEstimated, planModeActual)), nil
+			}
+			includeXML, _ := request.Params.Arguments["include_xml"].(bool)
+
+			queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			plans, err := explainer.explainQuery(queryCtx, query, mode == planModeActual, params...)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			var statements []planStatement
+			for _, plan := range plans {
+				planStatements, err := parseShowplan(plan)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
 				}
-				resultText.WriteString("\n")
-				
-				// Print separator
-				for range columns {
-					resultText.WriteString("----------\t")
+				statements = append(statements, planStatements...)
+			}
+
+			output := formatPlan(statements, mode == planModeActual)
+			if includeXML {
+				for i, plan := range plans {
+					output += fmt.Sprintf("\nShowplan XML %d:\n\n%s\n", i+1, plan)
 				}
-				resultText.WriteString("\n")
-				
-				// Print data rows
-				for _, row := range results {
-					for _, col := range columns {
-						resultText.WriteString(fmt.Sprintf("%v\t", row[col]))
+			}
+
+			return mcp.NewToolResultText(output), nil
+		})
+
+		// Register the activity, blocking, wait and query statistics tools
+		registerDiagnosticTools(server, connections)
+
+		// Register tool for profiling the data of a table
+		profileTableTool := mcp.NewTool("sql_profile_table", append(withTableArguments(
+			"The name of the table to profile",
+			mcp.WithDescription("Profile the data of a table: its row count and size on disk and, per column, the percentage of NULLs, "+
+				"the number of distinct values, the minimum and maximum and the most frequent values. Large tables are profiled from a "+
+				"sample and distinct counts are then estimated. Profiling stops when its time budget runs out"),
+			mcp.WithArray("columns",
+				mcp.Description("The columns to profile. Defaults to all columns"),
+				mcp.Items(map[string]any{"type": "string"}),
+			),
+			mcp.WithNumber("top_values",
+				mcp.Description(fmt.Sprintf("Number of most frequent values to list per column (0 to %d, default %d)", maxProfileTopValues, defaultProfileTopValues)),
+			),
+			mcp.WithNumber("sample_rows",
+				mcp.Description(fmt.Sprintf("Tables with more rows are profiled from a sample of about this many rows (default %d)", defaultProfileSampleRows)),
+			),
+			mcp.WithNumber("time_budget_seconds",
+				mcp.Description("Stop profiling after this many seconds and return the columns profiled so far. Cannot exceed the connection's profile time budget"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: text (tab-separated, default) or json"),
+				mcp.Enum(formatText, formatJSON),
+			),
+		), connections.toolOptions()...)...)
+
+		server.AddTool(profileTableTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			conn, err := connections.fromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			profiler, ok := conn.db.(tableProfiler)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support profiling tables", conn.config.Name, conn.config.Engine)), nil
+			}
+
+			tableName, err := tableNameFromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			options := profileOptions{
+				topValues:  defaultProfileTopValues,
+				sampleRows: defaultProfileSampleRows,
+				budget:     conn.config.ProfileTimeBudget,
+			}
+
+			if value, ok := request.Params.Arguments["columns"].([]any); ok {
+				for _, column := range value {
+					name, ok := column.(string)
+					if !ok || strings.TrimSpace(name) == "" {
+						return mcp.NewToolResultError("columns must be a list of column names"), nil
 					}
-					resultText.WriteString("\n")
+					options.columns = append(options.columns, strings.TrimSpace(name))
 				}
 			}
-			
-			return mcp.NewToolResultText(resultText.String()), nil
-		})
-		
-		// Register tool for getting all tables
-		getTablesTool := mcp.NewTool("sql_get_tables",
-			mcp.WithDescription("Get a list of all tables in the database"),
-		)
-		
-		server.AddTool(getTablesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			tables, err := sqlServerTool.GetTables()
+			if value, ok := request.Params.Arguments["top_values"].(float64); ok {
+				if value < 0 || value > maxProfileTopValues {
+					return mcp.NewToolResultError(fmt.Sprintf("top_values must be between 0 and %d", maxProfileTopValues)), nil
+				}
+				options.topValues = int(value)
+			}
+			if value, ok := request.Params.Arguments["sample_rows"].(float64); ok {
+				if value < 1 {
+					return mcp.NewToolResultError("sample_rows must be positive"), nil
+				}
+				options.sampleRows = int64(value)
+			}
+			if value, ok := request.Params.Arguments["time_budget_seconds"].(float64); ok {
+				requested := time.Duration(value * float64(time.Second))
+				if requested <= 0 {
+					return mcp.NewToolResultError("time_budget_seconds must be positive"), nil
+				}
+				if options.budget > 0 && requested > options.budget {
+					return mcp.NewToolResultError(fmt.Sprintf("time_budget_seconds cannot exceed the %v profile time budget of connection %q", options.budget, conn.config.Name)), nil
+				}
+				options.budget = requested
+			}
+
+			format, err := parseResultFormat(request.Params.Arguments["format"])
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
-			
-			// Format tables as text
-			var resultText strings.Builder
-			resultText.WriteString(fmt.Sprintf("Found %d tables:\n\n", len(tables)))
-			
-			for i, table := range tables {
-				resultText.WriteString(fmt.Sprintf("%d. %s\n", i+1, table))
+			if format != formatText && format != formatJSON {
+				return mcp.NewToolResultError("format must be text or json"), nil
 			}
-			
-			return mcp.NewToolResultText(resultText.String()), nil
+
+			queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			profile, err := profiler.profileTable(queryCtx, tableName, options)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			output, err := formatTableProfile(profile, format)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			return mcp.NewToolResultText(output), nil
 		})
-		
-		// Register tool for getting table schema
-		getTableSchemaTool := mcp.NewTool("sql_get_table_schema",
-			mcp.WithDescription("Get the schema of a specific table"),
-			mcp.WithString("table_name",
-				mcp.Required(),
-				mcp.Description("The name of the table to get the schema for"),
+
+		// Register tool for sampling rows with personal data masked
+		sampleRowsTool := mcp.NewTool("sql_sample_rows", append(withTableArguments(
+			"The name of the table to sample",
+			mcp.WithDescription("Return randomly sampled rows of a table to show what its data looks like. Columns that hold personal "+
+				"data, by their name or by values that look like e-mail addresses, card numbers and the like, are masked or hashed"),
+			mcp.WithNumber("rows",
+				mcp.Description(fmt.Sprintf("Number of rows to sample (1 to %d, default %d)", maxSampleRows, defaultSampleRows)),
 			),
-		)
-		
-		server.AddTool(getTableSchemaTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			tableName, ok := request.Params.Arguments["table_name"].(string)
+			mcp.WithArray("columns",
+				mcp.Description("The columns to return. Defaults to all columns"),
+				mcp.Items(map[string]any{"type": "string"}),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: text (tab-separated, default), json (typed values with column metadata), csv or markdown"),
+				mcp.Enum(formatText, formatJSON, formatCSV, formatMarkdown),
+			),
+		), connections.toolOptions()...)...)
+
+		server.AddTool(sampleRowsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			conn, err := connections.fromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			sampler, ok := conn.db.(rowSampler)
 			if !ok {
-				return mcp.NewToolResultError("table_name must be a string"), nil
+				return mcp.NewToolResultError(fmt.Sprintf("connection %q (%s) does not support sampling rows", conn.config.Name, conn.config.Engine)), nil
 			}
-			
-			schema, err := sqlServerTool.GetTableSchema(tableName)
+
+			tableName, err := tableNameFromRequest(request)
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
-			
-			// Format schema as text
-			var resultText strings.Builder
-			resultText.WriteString(fmt.Sprintf("Schema for table %s:\n\n", tableName))
-			
-			// Print headers
-			resultText.WriteString("COLUMN_NAME\tDATA_TYPE\tIS_NULLABLE\tDEFAULT_VALUE\tIS_PRIMARY_KEY\n")
-			resultText.WriteString("----------\t----------\t----------\t----------\t----------\n")
-			
-			// Print data
-			for _, col := range schema.Columns {
-				defaultValue := ""
-				if col.DefaultValue != nil {
-					defaultValue = fmt.Sprintf("%v", col.DefaultValue)
+
+			count := defaultSampleRows
+			if value, ok := request.Params.Arguments["rows"].(float64); ok {
+				if value < 1 || value > maxSampleRows {
+					return mcp.NewToolResultError(fmt.Sprintf("rows must be between 1 and %d", maxSampleRows)), nil
 				}
-				
-				resultText.WriteString(fmt.Sprintf("%s\t%s\t%v\t%s\t%v\n", 
-					col.Name, col.Type, col.Nullable, defaultValue, col.IsPrimaryKey))
+				count = int(value)
 			}
-			
-			return mcp.NewToolResultText(resultText.String()), nil
-		})
-		
-		// Register tool for getting database schemas
-		getSchemasTool := mcp.NewTool("sql_get_schemas",
-			mcp.WithDescription("Get a list of all schemas in the database"),
-		)
-		
-		server.AddTool(getSchemasTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			queryCtx := context.Background()
-			schemas, err := sqlServerTool.getDBSchemas(queryCtx)
+
+			var columns []string
+			if value, ok := request.Params.Arguments["columns"].([]any); ok {
+				for _, column := range value {
+					name, ok := column.(string)
+					if !ok || strings.TrimSpace(name) == "" {
+						return mcp.NewToolResultError("columns must be a list of column names"), nil
+					}
+					columns = append(columns, strings.TrimSpace(name))
+				}
+			}
+
+			format, err := parseResultFormat(request.Params.Arguments["format"])
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
-			
-			// Format schemas as text
+
+			queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			sample, err := sampler.sampleRows(queryCtx, tableName, columns, count)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			output, err := formatRowSample(sample, format)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			return mcp.NewToolResultText(output), nil
+		})
+
+		registerSchemaSearchTool(server, connections)
+		registerSchemaRefreshTool(server, connections)
+		registerSchemaResources(server, connections)
+		registerSchemaDiffTool(server, connections, os.Getenv(snapshotDirVariable))
+
+		// Register tool for listing the configured connections
+		listConnectionsTool := mcp.NewTool("sql_list_connections",
+			mcp.WithDescription("List the configured database connections with their engine, host, database and read-only mode"),
+		)
+
+		server.AddTool(listConnectionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			var resultText strings.Builder
-			resultText.WriteString(fmt.Sprintf("Found %d schemas:\n\n", len(schemas)))
-			
-			for i, schema := range schemas {
-				resultText.WriteString(fmt.Sprintf("%d. %s\n", i+1, schema))
+			resultText.WriteString(fmt.Sprintf("Found %d connections:\n\n", len(connections.names)))
+			resultText.WriteString("NAME\tENGINE\tHOST\tDATABASE\tREAD_ONLY\tDEFAULT\n")
+			resultText.WriteString("----------\t----------\t----------\t----------\t----------\t----------\n")
+
+			for _, conn := range connections.list() {
+				host := conn.config.Host
+				if conn.config.Port != "" {
+					host = host + ":" + conn.config.Port
+				}
+
+				resultText.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%v\t%v\n",
+					conn.config.Name, conn.config.Engine, host, conn.config.Database, conn.config.ReadOnly, conn.config.Name == connections.defaultName))
 			}
-			
+
 			return mcp.NewToolResultText(resultText.String()), nil
 		})
 	}
-	
-	return sqlServerTool
-}
-
-
 
+	// Return the default connection
+	conn, _ := connections.get("")
+	return conn.db
+}
@@ -0,0 +1,355 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// sqlServerIndex is an index or key constraint of a table being scripted
+type sqlServerIndex struct {
+	name                       string
+	typeDesc                   string
+	unique, primary, uniqueKey bool
+	filter                     string
+	columns, includedColumns   []string
+}
+
+// scriptTable scripts a table as CREATE TABLE with its columns, key, unique,
+// check and foreign key constraints, followed by its other indexes
+func (s *sqlServerImpl) scriptTable(ctx context.Context, tableName string) (string, error) {
+	schemaName, relName := splitTableName(tableName)
+	if schemaName == "" {
+		var err error
+		if schemaName, err = s.resolveTableSchema(ctx, relName); err != nil {
+			return "", err
+		}
+	}
+
+	var objectID sql.NullInt64
+	query := "SELECT OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2), 'U')"
+	if err := s.db.QueryRowContext(ctx, query, schemaName, relName).Scan(&objectID); err != nil {
+		return "", fmt.Errorf("error resolving table %s: %w", tableName, err)
+	}
+	if !objectID.Valid {
+		return "", fmt.Errorf("table %s not found", tableName)
+	}
+
+	definitions, err := s.scriptColumns(ctx, objectID.Int64)
+	if err != nil {
+		return "", err
+	}
+
+	indexes, err := s.scriptIndexes(ctx, objectID.Int64)
+	if err != nil {
+		return "", err
+	}
+	for _, index := range indexes {
+		if !index.primary && !index.uniqueKey {
+			continue
+		}
+		kind := "UNIQUE"
+		if index.primary {
+			kind = "PRIMARY KEY"
+		}
+		definitions = append(definitions, fmt.Sprintf("CONSTRAINT %s %s %s (%s)",
+			quoteSQLServerIdentifier(index.name), kind, index.typeDesc, strings.Join(index.columns, ", ")))
+	}
+
+	constraints, err := s.scriptConstraints(ctx, objectID.Int64)
+	if err != nil {
+		return "", err
+	}
+	definitions = append(definitions, constraints...)
+
+	table := quoteSQLServerName(schemaName, relName)
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("CREATE TABLE %s (\n    %s\n);\n", table, strings.Join(definitions, ",\n    ")))
+
+	for _, index := range indexes {
+		if index.primary || index.uniqueKey {
+			continue
+		}
+
+		resultText.WriteString("\nCREATE ")
+		if index.unique {
+			resultText.WriteString("UNIQUE ")
+		}
+		resultText.WriteString(fmt.Sprintf("%s INDEX %s ON %s", index.typeDesc, quoteSQLServerIdentifier(index.name), table))
+		// A clustered columnstore index covers every column
+		if len(index.columns) > 0 && index.typeDesc != "CLUSTERED COLUMNSTORE" {
+			resultText.WriteString(fmt.Sprintf(" (%s)", strings.Join(index.columns, ", ")))
+		}
+		if len(index.includedColumns) > 0 {
+			resultText.WriteString(fmt.Sprintf(" INCLUDE (%s)", strings.Join(index.includedColumns, ", ")))
+		}
+		if index.filter != "" {
+			resultText.WriteString(" WHERE " + index.filter)
+		}
+		resultText.WriteString(";\n")
+	}
+
+	return resultText.String(), nil
+}
+
+// scriptColumns returns the column definitions of a table: types with
+// their length, precision and collation, identity, computed expressions,
+// nullability and named defaults. The collation is only scripted when it
+// differs from the database default.
+func (s *sqlServerImpl) scriptColumns(ctx context.Context, objectID int64) ([]string, error) {
+	query := `
+		SELECT
+			c.name,
+			TYPE_NAME(c.user_type_id),
+			t.is_user_defined,
+			SCHEMA_NAME(t.schema_id),
+			c.max_length,
+			c.precision,
+			c.scale,
+			c.is_nullable,
+			c.is_identity,
+			ISNULL(CONVERT(varchar(40), ic.seed_value), ''),
+			ISNULL(CONVERT(varchar(40), ic.increment_value), ''),
+			ISNULL(cc.definition, ''),
+			ISNULL(cc.is_persisted, 0),
+			ISNULL(dc.name, ''),
+			ISNULL(dc.definition, ''),
+			CASE WHEN c.collation_name <> CONVERT(sysname, DATABASEPROPERTYEX(DB_NAME(), 'Collation'))
+				THEN c.collation_name ELSE '' END
+		FROM sys.columns c
+		JOIN sys.types t ON t.user_type_id = c.user_type_id
+		LEFT JOIN sys.identity_columns ic ON ic.object_id = c.object_id AND ic.column_id = c.column_id
+		LEFT JOIN sys.computed_columns cc ON cc.object_id = c.object_id AND cc.column_id = c.column_id
+		LEFT JOIN sys.default_constraints dc ON dc.object_id = c.default_object_id
+		WHERE c.object_id = @p1
+		ORDER BY c.column_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns: %w", err)
+	}
+	defer rows.Close()
+
+	var definitions []string
+	for rows.Next() {
+		var (
+			name, typeName, typeSchema               string
+			userDefined, nullable, identity, persist bool
+			maxLength, precision, scale              int
+			seed, increment, computed                string
+			defaultName, defaultValue, collation     string
+		)
+		if err := rows.Scan(&name, &typeName, &userDefined, &typeSchema, &maxLength, &precision, &scale, &nullable, &identity,
+			&seed, &increment, &computed, &persist, &defaultName, &defaultValue, &collation); err != nil {
+			return nil, fmt.Errorf("error scanning column: %w", err)
+		}
+
+		definition := quoteSQLServerIdentifier(name)
+		if computed != "" {
+			definition += " AS " + computed
+			if persist {
+				definition += " PERSISTED"
+				if !nullable {
+					definition += " NOT NULL"
+				}
+			}
+			definitions = append(definitions, definition)
+			continue
+		}
+
+		if userDefined {
+			definition += " " + quoteSQLServerName(typeSchema, typeName)
+		} else {
+			definition += " " + sqlServerTypeName(typeName, maxLength, precision, scale)
+		}
+		if collation != "" {
+			definition += " COLLATE " + collation
+		}
+		if identity {
+			definition += fmt.Sprintf(" IDENTITY(%s,%s)", seed, increment)
+		}
+		if nullable {
+			definition += " NULL"
+		} else {
+			definition += " NOT NULL"
+		}
+		if defaultValue != "" {
+			definition += fmt.Sprintf(" CONSTRAINT %s DEFAULT %s", quoteSQLServerIdentifier(defaultName), defaultValue)
+		}
+		definitions = append(definitions, definition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %w", err)
+	}
+
+	return definitions, nil
+}
+
+// scriptIndexes returns the relational and columnstore indexes of a table,
+// including those backing its primary key and unique constraints, with
+// their columns quoted and marked DESC where descending
+func (s *sqlServerImpl) scriptIndexes(ctx context.Context, objectID int64) ([]sqlServerIndex, error) {
+	query := `
+		SELECT
+			i.name,
+			i.type_desc,
+			i.is_unique,
+			i.is_primary_key,
+			i.is_unique_constraint,
+			ISNULL(i.filter_definition, ''),
+			c.name,
+			ic.is_descending_key,
+			ic.is_included_column
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id = @p1
+			AND i.type IN (1, 2, 5, 6)
+		ORDER BY i.index_id, ic.is_included_column, ic.key_ordinal, ic.index_column_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []sqlServerIndex
+	for rows.Next() {
+		var (
+			index                  sqlServerIndex
+			column                 string
+			descending, isIncluded bool
+		)
+		if err := rows.Scan(&index.name, &index.typeDesc, &index.unique, &index.primary, &index.uniqueKey, &index.filter,
+			&column, &descending, &isIncluded); err != nil {
+			return nil, fmt.Errorf("error scanning index: %w", err)
+		}
+		index.typeDesc = strings.ReplaceAll(index.typeDesc, "_", " ")
+
+		// Rows are ordered by index, one per column
+		if n := len(indexes); n == 0 || indexes[n-1].name != index.name {
+			indexes = append(indexes, index)
+		}
+		current := &indexes[len(indexes)-1]
+
+		column = quoteSQLServerIdentifier(column)
+		switch {
+		case isIncluded && strings.HasSuffix(current.typeDesc, "COLUMNSTORE"):
+			// Columnstore indexes list their columns as included ones
+			current.columns = append(current.columns, column)
+		case isIncluded:
+			current.includedColumns = append(current.includedColumns, column)
+		case descending:
+			current.columns = append(current.columns, column+" DESC")
+		default:
+			current.columns = append(current.columns, column)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// scriptConstraints returns the check and foreign key constraints of a
+// table
+func (s *sqlServerImpl) scriptConstraints(ctx context.Context, objectID int64) ([]string, error) {
+	query := `
+		SELECT cc.name, cc.definition
+		FROM sys.check_constraints cc
+		WHERE cc.parent_object_id = @p1
+		ORDER BY cc.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var definitions []string
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return nil, fmt.Errorf("error scanning check constraint: %w", err)
+		}
+		definitions = append(definitions, fmt.Sprintf("CONSTRAINT %s CHECK %s", quoteSQLServerIdentifier(name), definition))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating check constraints: %w", err)
+	}
+
+	query = `
+		SELECT
+			fk.name,
+			OBJECT_SCHEMA_NAME(fk.referenced_object_id),
+			OBJECT_NAME(fk.referenced_object_id),
+			fk.delete_referential_action_desc,
+			fk.update_referential_action_desc,
+			pc.name,
+			rc.name
+		FROM sys.foreign_keys fk
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		WHERE fk.parent_object_id = @p1
+		ORDER BY fk.name, fkc.constraint_column_id
+	`
+
+	rows, err = s.db.QueryContext(ctx, query, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	type foreignKey struct {
+		name, referencedTable, onDelete, onUpdate string
+		columns, referencedColumns                []string
+	}
+	var foreignKeys []foreignKey
+	for rows.Next() {
+		var (
+			fk                               foreignKey
+			referencedSchema, referencedName string
+			column, referencedColumn         string
+		)
+		if err := rows.Scan(&fk.name, &referencedSchema, &referencedName, &fk.onDelete, &fk.onUpdate, &column, &referencedColumn); err != nil {
+			return nil, fmt.Errorf("error scanning foreign key: %w", err)
+		}
+
+		// Rows are ordered by foreign key, one per column
+		if n := len(foreignKeys); n == 0 || foreignKeys[n-1].name != fk.name {
+			fk.referencedTable = quoteSQLServerName(referencedSchema, referencedName)
+			fk.onDelete, fk.onUpdate = referentialAction(fk.onDelete), referentialAction(fk.onUpdate)
+			foreignKeys = append(foreignKeys, fk)
+		}
+		current := &foreignKeys[len(foreignKeys)-1]
+		current.columns = append(current.columns, quoteSQLServerIdentifier(column))
+		current.referencedColumns = append(current.referencedColumns, quoteSQLServerIdentifier(referencedColumn))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	for _, fk := range foreignKeys {
+		definition := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)", quoteSQLServerIdentifier(fk.name),
+			strings.Join(fk.columns, ", "), fk.referencedTable, strings.Join(fk.referencedColumns, ", "))
+		if fk.onDelete != "NO ACTION" {
+			definition += " ON DELETE " + fk.onDelete
+		}
+		if fk.onUpdate != "NO ACTION" {
+			definition += " ON UPDATE " + fk.onUpdate
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, nil
+}
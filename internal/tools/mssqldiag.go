@@ -0,0 +1,827 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Limits of the diagnostic tools
+const (
+	// defaultDiagnosticRows is the number of rows a diagnostic tool
+	// returns without a max_rows argument
+	defaultDiagnosticRows = 25
+
+	// maxDiagnosticRows bounds the max_rows argument of diagnostic tools
+	maxDiagnosticRows = 500
+
+	// maxBlockingSessions bounds the sessions read for the blocking tree
+	maxBlockingSessions = 1000
+
+	// diagnosticTextLength is the number of characters of SQL text shown
+	// per request or query
+	diagnosticTextLength = 1000
+)
+
+// Orderings of the top queries tool, mapped to the column they sort by
+var topQueryOrders = map[string]string{
+	"cpu":            "total_cpu_ms",
+	"duration":       "total_duration_ms",
+	"logical_reads":  "total_logical_reads",
+	"physical_reads": "total_physical_reads",
+	"writes":         "total_writes",
+	"executions":     "execution_count",
+	"avg_cpu":        "avg_cpu_ms",
+	"avg_duration":   "avg_duration_ms",
+}
+
+// Scopes of the top waits tool
+const (
+	waitScopeCumulative = "cumulative"
+	waitScopeCurrent    = "current"
+)
+
+// benignWaitTypes are background and idle waits left out of the wait
+// statistics, since they accumulate without anyone waiting on them
+var benignWaitTypes = []string{
+	"BROKER_EVENTHANDLER", "BROKER_RECEIVE_WAITFOR", "BROKER_TASK_STOP", "BROKER_TO_FLUSH", "BROKER_TRANSMITTER",
+	"CHECKPOINT_QUEUE", "CHKPT", "CLR_AUTO_EVENT", "CLR_MANUAL_EVENT", "CLR_SEMAPHORE",
+	"DBMIRROR_DBM_EVENT", "DBMIRROR_EVENTS_QUEUE", "DBMIRROR_WORKER_QUEUE", "DBMIRRORING_CMD",
+	"DIRTY_PAGE_POLL", "DISPATCHER_QUEUE_SEMAPHORE", "EXECSYNC", "FSAGENT",
+	"FT_IFTS_SCHEDULER_IDLE_WAIT", "FT_IFTSHC_MUTEX",
+	"HADR_CLUSAPI_CALL", "HADR_FILESTREAM_IOMGR_IOCOMPLETION", "HADR_LOGCAPTURE_WAIT", "HADR_NOTIFICATION_DEQUEUE",
+	"HADR_TIMER_TASK", "HADR_WORK_QUEUE",
+	"KSOURCE_WAKEUP", "LAZYWRITER_SLEEP", "LOGMGR_QUEUE", "MEMORY_ALLOCATION_EXT", "ONDEMAND_TASK_QUEUE",
+	"PARALLEL_REDO_DRAIN_WORKER", "PARALLEL_REDO_LOG_CACHE", "PARALLEL_REDO_TRAN_LIST", "PARALLEL_REDO_WORKER_SYNC",
+	"PARALLEL_REDO_WORKER_WAIT_WORK", "PREEMPTIVE_OS_FLUSHFILEBUFFERS", "PREEMPTIVE_XE_GETTARGETSTATE",
+	"PWAIT_ALL_COMPONENTS_INITIALIZED", "PWAIT_DIRECTLOGCONSUMER_GETNEXT", "PWAIT_EXTENSIBILITY_CLEANUP_TASK",
+	"QDS_ASYNC_QUEUE", "QDS_CLEANUP_STALE_QUERIES_TASK_MAIN_LOOP_SLEEP", "QDS_PERSIST_TASK_MAIN_LOOP_SLEEP",
+	"QDS_SHUTDOWN_QUEUE", "REDO_THREAD_PENDING_WORK", "REQUEST_FOR_DEADLOCK_SEARCH", "RESOURCE_QUEUE",
+	"SERVER_IDLE_CHECK", "SLEEP_BPOOL_FLUSH", "SLEEP_DBSTARTUP", "SLEEP_DCOMSTARTUP", "SLEEP_MASTERDBREADY",
+	"SLEEP_MASTERMDREADY", "SLEEP_MASTERUPGRADED", "SLEEP_MSDBSTARTUP", "SLEEP_SYSTEMTASK", "SLEEP_TASK",
+	"SLEEP_TEMPDBSTARTUP", "SNI_HTTP_ACCEPT", "SOS_WORK_DISPATCHER", "SP_SERVER_DIAGNOSTICS_SLEEP",
+	"SQLTRACE_BUFFER_FLUSH", "SQLTRACE_INCREMENTAL_FLUSH_SLEEP", "SQLTRACE_WAIT_ENTRIES",
+	"UCS_SESSION_REGISTRATION", "WAIT_FOR_RESULTS", "WAIT_XTP_CKPT_CLOSE", "WAIT_XTP_HOST_WAIT",
+	"WAIT_XTP_OFFLINE_CKPT_NEW_LOG", "WAIT_XTP_RECOVERY", "WAITFOR", "WAITFOR_TASKSHUTDOWN",
+	"XE_DISPATCHER_JOIN", "XE_DISPATCHER_WAIT", "XE_LIVE_TARGET_TVF", "XE_TIMER_EVENT",
+}
+
+// serverDiagnostics is implemented by backends that can report server
+// activity for the diagnostic tools. Every method only reads dynamic
+// management views, so they are available on read-only connections too.
+type serverDiagnostics interface {
+	activeRequests(ctx context.Context, filter requestFilter, limit int) (queryResult, error)
+	blockingSessions(ctx context.Context) ([]blockingSession, error)
+	topWaits(ctx context.Context, scope string, limit int) (queryResult, error)
+	topQueries(ctx context.Context, orderBy string, database string, minExecutions int, limit int) (queryResult, error)
+}
+
+// requestFilter narrows down the active requests tool
+type requestFilter struct {
+	database     string
+	login        string
+	minElapsedMS int
+
+	// includeSystem also lists requests of system sessions
+	includeSystem bool
+}
+
+// blockingSession is a session that blocks or is blocked by another
+type blockingSession struct {
+	sessionID         int64
+	blockingSessionID int64
+	status            string
+	database          string
+	login             string
+	host              string
+	program           string
+	waitType          string
+	waitTimeMS        int64
+	waitResource      string
+	openTransactions  int64
+	sqlText           string
+
+	// blocked lists the sessions waiting on this one, filled in by
+	// blockingTree
+	blocked []*blockingSession
+}
+
+// sqlServerStatementText returns the expression for the statement that the
+// row alias holds the offsets of, cut from the batch text t.text and
+// shortened to diagnosticTextLength characters
+func sqlServerStatementText(alias string) string {
+	return fmt.Sprintf(`LEFT(SUBSTRING(t.text, %[1]s.statement_start_offset / 2 + 1,
+		(CASE WHEN %[1]s.statement_end_offset = -1 THEN DATALENGTH(t.text) ELSE %[1]s.statement_end_offset END
+			- %[1]s.statement_start_offset) / 2 + 1), %[2]d)`, alias, diagnosticTextLength)
+}
+
+// activeRequests lists the requests running on the server, longest
+// running first. It reads at most limit+1 rows to tell whether more exist.
+func (s *sqlServerImpl) activeRequests(ctx context.Context, filter requestFilter, limit int) (queryResult, error) {
+	query := `
+		SELECT TOP (@limit)
+			r.session_id, r.status, r.command, DB_NAME(r.database_id) AS database_name,
+			se.login_name, se.host_name, se.program_name,
+			r.blocking_session_id, r.wait_type, r.wait_time AS wait_time_ms, r.wait_resource,
+			r.total_elapsed_time AS elapsed_ms, r.cpu_time AS cpu_ms, r.logical_reads, r.reads, r.writes,
+			r.open_transaction_count, r.start_time,
+			` + sqlServerStatementText("r") + ` AS statement_text
+		FROM sys.dm_exec_requests r
+		JOIN sys.dm_exec_sessions se ON se.session_id = r.session_id
+		OUTER APPLY sys.dm_exec_sql_text(r.sql_handle) t
+		WHERE r.session_id <> @@SPID
+			AND (@include_system = 1 OR se.is_user_process = 1)
+			AND (@database = '' OR r.database_id = DB_ID(@database))
+			AND (@login = '' OR se.login_name = @login)
+			AND r.total_elapsed_time >= @min_elapsed_ms
+		ORDER BY r.total_elapsed_time DESC`
+
+	return s.queryDiagnostic(ctx, query, limit,
+		sql.Named("database", filter.database),
+		sql.Named("login", filter.login),
+		sql.Named("min_elapsed_ms", filter.minElapsedMS),
+		sql.Named("include_system", filter.includeSystem),
+	)
+}
+
+// blockingSessions returns every session that is blocked, together with
+// the sessions blocking them. Head blockers are often idle sessions with
+// an open transaction, so their last statement is shown instead.
+func (s *sqlServerImpl) blockingSessions(ctx context.Context) ([]blockingSession, error) {
+	query := fmt.Sprintf(`
+		WITH blocked AS (
+			SELECT session_id, blocking_session_id
+			FROM sys.dm_exec_requests
+			WHERE blocking_session_id <> 0 AND blocking_session_id <> session_id
+		)
+		SELECT TOP (%d)
+			se.session_id,
+			COALESCE(b.blocking_session_id, 0),
+			COALESCE(r.status, se.status),
+			COALESCE(DB_NAME(COALESCE(r.database_id, se.database_id)), ''),
+			se.login_name, COALESCE(se.host_name, ''), COALESCE(se.program_name, ''),
+			COALESCE(r.wait_type, ''), COALESCE(r.wait_time, 0), COALESCE(r.wait_resource, ''),
+			se.open_transaction_count,
+			COALESCE(LEFT(t.text, %d), '')
+		FROM sys.dm_exec_sessions se
+		LEFT JOIN blocked b ON b.session_id = se.session_id
+		LEFT JOIN sys.dm_exec_requests r ON r.session_id = se.session_id
+		LEFT JOIN sys.dm_exec_connections c ON c.session_id = se.session_id AND c.parent_connection_id IS NULL
+		OUTER APPLY sys.dm_exec_sql_text(COALESCE(r.sql_handle, c.most_recent_sql_handle)) t
+		WHERE se.session_id IN (SELECT session_id FROM blocked)
+			OR se.session_id IN (SELECT blocking_session_id FROM blocked)
+		ORDER BY se.session_id`, maxBlockingSessions, diagnosticTextLength)
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, diagnosticError(err)
+	}
+	defer rows.Close()
+
+	var sessions []blockingSession
+	for rows.Next() {
+		var session blockingSession
+		if err := rows.Scan(&session.sessionID, &session.blockingSessionID, &session.status, &session.database,
+			&session.login, &session.host, &session.program, &session.waitType, &session.waitTimeMS,
+			&session.waitResource, &session.openTransactions, &session.sqlText); err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// topWaits reports the wait types the server spent the most time on.
+// The cumulative scope reads the statistics gathered since the server
+// started or they were cleared; the current scope groups the tasks that
+// are waiting right now.
+func (s *sqlServerImpl) topWaits(ctx context.Context, scope string, limit int) (queryResult, error) {
+	benign := make([]string, len(benignWaitTypes))
+	for i, waitType := range benignWaitTypes {
+		benign[i] = "N'" + waitType + "'"
+	}
+	excluded := strings.Join(benign, ", ")
+
+	query := `
+		SELECT TOP (@limit)
+			wait_type, waiting_tasks_count, wait_time_ms,
+			wait_time_ms - signal_wait_time_ms AS resource_wait_ms, signal_wait_time_ms,
+			CAST(wait_time_ms * 1.0 / NULLIF(waiting_tasks_count, 0) AS decimal(18, 2)) AS avg_wait_ms,
+			CAST(100.0 * wait_time_ms / NULLIF(SUM(wait_time_ms) OVER (), 0) AS decimal(5, 2)) AS percent_of_total
+		FROM sys.dm_os_wait_stats
+		WHERE waiting_tasks_count > 0 AND wait_type NOT IN (` + excluded + `)
+			AND wait_type NOT LIKE N'SLEEP[_]%'
+		ORDER BY wait_time_ms DESC`
+
+	if scope == waitScopeCurrent {
+		query = `
+			SELECT TOP (@limit)
+				wt.wait_type, COUNT(*) AS waiting_tasks, COUNT(DISTINCT wt.session_id) AS sessions,
+				SUM(wt.wait_duration_ms) AS total_wait_ms, MAX(wt.wait_duration_ms) AS max_wait_ms,
+				MAX(wt.resource_description) AS example_resource
+			FROM sys.dm_os_waiting_tasks wt
+			JOIN sys.dm_exec_sessions se ON se.session_id = wt.session_id AND se.is_user_process = 1
+			WHERE wt.session_id <> @@SPID AND wt.wait_type NOT IN (` + excluded + `)
+			GROUP BY wt.wait_type
+			ORDER BY SUM(wt.wait_duration_ms) DESC`
+	}
+
+	return s.queryDiagnostic(ctx, query, limit)
+}
+
+// topQueries reports the cached query statements that used the most of a
+// resource, by the column topQueryOrders maps orderBy to
+func (s *sqlServerImpl) topQueries(ctx context.Context, orderBy string, database string, minExecutions int, limit int) (queryResult, error) {
+	column, ok := topQueryOrders[orderBy]
+	if !ok {
+		return queryResult{}, fmt.Errorf("unknown order %q", orderBy)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT TOP (@limit) *
+		FROM (
+			SELECT
+				qs.execution_count,
+				qs.total_worker_time / 1000 AS total_cpu_ms,
+				qs.total_worker_time / 1000 / qs.execution_count AS avg_cpu_ms,
+				qs.total_elapsed_time / 1000 AS total_duration_ms,
+				qs.total_elapsed_time / 1000 / qs.execution_count AS avg_duration_ms,
+				qs.total_logical_reads,
+				qs.total_logical_reads / qs.execution_count AS avg_logical_reads,
+				qs.total_physical_reads,
+				qs.total_logical_writes AS total_writes,
+				qs.last_execution_time,
+				DB_NAME(t.dbid) AS database_name,
+				CONVERT(varchar(18), qs.query_hash, 1) AS query_hash,
+				%s AS statement_text
+			FROM sys.dm_exec_query_stats qs
+			OUTER APPLY sys.dm_exec_sql_text(qs.sql_handle) t
+			WHERE qs.execution_count >= @min_executions
+				AND (@database = '' OR t.dbid = DB_ID(@database))
+		) q
+		ORDER BY %s DESC`, sqlServerStatementText("qs"), column)
+
+	return s.queryDiagnostic(ctx, query, limit,
+		sql.Named("database", database),
+		sql.Named("min_executions", minExecutions),
+	)
+}
+
+// queryDiagnostic runs a diagnostic query that selects TOP (@limit) rows.
+// One row beyond limit is read to tell whether the result was cut off.
+func (s *sqlServerImpl) queryDiagnostic(ctx context.Context, query string, limit int, params ...any) (queryResult, error) {
+	rows, err := s.db.QueryContext(ctx, query, append(params, sql.Named("limit", limit+1))...)
+	if err != nil {
+		return queryResult{}, diagnosticError(err)
+	}
+	defer rows.Close()
+
+	return scanRowsPage(rows, s.convertValue, 0, limit)
+}
+
+// diagnosticError wraps an error of a diagnostic query, pointing out the
+// permission the dynamic management views need
+func diagnosticError(err error) error {
+	if strings.Contains(err.Error(), "VIEW SERVER STATE") || strings.Contains(err.Error(), "permission") {
+		return fmt.Errorf("error reading server state, the login needs the VIEW SERVER STATE permission: %w", err)
+	}
+	return fmt.Errorf("error reading server state: %w", err)
+}
+
+// blockingTree links the sessions to the sessions they block and returns
+// the head blockers, the sessions not blocked by any session in the list.
+// Sessions in a blocking cycle are returned as heads too, once.
+func blockingTree(sessions []blockingSession) []*blockingSession {
+	byID := make(map[int64]*blockingSession, len(sessions))
+	for i := range sessions {
+		byID[sessions[i].sessionID] = &sessions[i]
+	}
+
+	var heads []*blockingSession
+	for i := range sessions {
+		session := &sessions[i]
+		if blocker, ok := byID[session.blockingSessionID]; ok {
+			blocker.blocked = append(blocker.blocked, session)
+		} else {
+			heads = append(heads, session)
+		}
+	}
+
+	// Sessions that cannot be reached from a head wait on each other
+	reached := make(map[int64]bool, len(sessions))
+	var mark func(session *blockingSession)
+	mark = func(session *blockingSession) {
+		if reached[session.sessionID] {
+			return
+		}
+		reached[session.sessionID] = true
+		for _, blocked := range session.blocked {
+			mark(blocked)
+		}
+	}
+	for _, head := range heads {
+		mark(head)
+	}
+	for i := range sessions {
+		if !reached[sessions[i].sessionID] {
+			heads = append(heads, &sessions[i])
+			mark(&sessions[i])
+		}
+	}
+
+	sort.SliceStable(heads, func(i, j int) bool { return countBlocked(heads[i]) > countBlocked(heads[j]) })
+	return heads
+}
+
+// countBlocked returns the number of sessions waiting on session, directly
+// or through other sessions
+func countBlocked(session *blockingSession) int {
+	seen := map[int64]bool{session.sessionID: true}
+	var count func(session *blockingSession) int
+	count = func(session *blockingSession) int {
+		total := 0
+		for _, blocked := range session.blocked {
+			if !seen[blocked.sessionID] {
+				seen[blocked.sessionID] = true
+				total += 1 + count(blocked)
+			}
+		}
+		return total
+	}
+	return count(session)
+}
+
+// jsonBlockingSession is a node of the blocking tree in json format
+type jsonBlockingSession struct {
+	SessionID        int64                  `json:"session_id"`
+	Status           string                 `json:"status"`
+	Database         string                 `json:"database"`
+	Login            string                 `json:"login"`
+	Host             string                 `json:"host"`
+	Program          string                 `json:"program"`
+	WaitType         string                 `json:"wait_type,omitempty"`
+	WaitTimeMS       int64                  `json:"wait_time_ms"`
+	WaitResource     string                 `json:"wait_resource,omitempty"`
+	OpenTransactions int64                  `json:"open_transactions"`
+	SQLText          string                 `json:"sql_text"`
+	Blocked          []*jsonBlockingSession `json:"blocked"`
+}
+
+// formatBlockingTree renders the blocking chains whose longest wait is at
+// least minWaitMS, head blockers first
+func formatBlockingTree(heads []*blockingSession, minWaitMS int64, format string) (string, error) {
+	var shown []*blockingSession
+	for _, head := range heads {
+		if maxChainWait(head, map[int64]bool{}) >= minWaitMS {
+			shown = append(shown, head)
+		}
+	}
+
+	if format == formatJSON {
+		var convert func(session *blockingSession, seen map[int64]bool) *jsonBlockingSession
+		convert = func(session *blockingSession, seen map[int64]bool) *jsonBlockingSession {
+			seen[session.sessionID] = true
+			node := &jsonBlockingSession{
+				SessionID:        session.sessionID,
+				Status:           session.status,
+				Database:         session.database,
+				Login:            session.login,
+				Host:             session.host,
+				Program:          session.program,
+				WaitType:         session.waitType,
+				WaitTimeMS:       session.waitTimeMS,
+				WaitResource:     session.waitResource,
+				OpenTransactions: session.openTransactions,
+				SQLText:          session.sqlText,
+				Blocked:          []*jsonBlockingSession{},
+			}
+			for _, blocked := range session.blocked {
+				if !seen[blocked.sessionID] {
+					node.Blocked = append(node.Blocked, convert(blocked, seen))
+				}
+			}
+			return node
+		}
+
+		document := make([]*jsonBlockingSession, 0, len(shown))
+		for _, head := range shown {
+			document = append(document, convert(head, map[int64]bool{}))
+		}
+
+		data, err := json.MarshalIndent(map[string]any{"head_blockers": document}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if len(shown) == 0 {
+		return "No blocking found.\n", nil
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Found %d blocking chains:\n", len(shown)))
+
+	var write func(session *blockingSession, depth int, seen map[int64]bool)
+	write = func(session *blockingSession, depth int, seen map[int64]bool) {
+		seen[session.sessionID] = true
+		indent := strings.Repeat("  ", depth)
+
+		state := fmt.Sprintf("%s, %d open transactions", session.status, session.openTransactions)
+		if depth == 0 {
+			state = fmt.Sprintf("head blocker blocking %d sessions, %s", countBlocked(session), state)
+		}
+		if session.waitType != "" {
+			state += fmt.Sprintf(", waiting %d ms on %s", session.waitTimeMS, session.waitType)
+			if session.waitResource != "" {
+				state += " (" + session.waitResource + ")"
+			}
+		}
+
+		resultText.WriteString(fmt.Sprintf("%s- Session %d (%s)\n", indent, session.sessionID, state))
+		resultText.WriteString(fmt.Sprintf("%s  %s@%s, %s, database %s\n", indent, session.login, session.host, session.program, session.database))
+		if text := strings.Join(strings.Fields(session.sqlText), " "); text != "" {
+			resultText.WriteString(fmt.Sprintf("%s  SQL: %s\n", indent, abbreviate(text, 200)))
+		}
+
+		for _, blocked := range session.blocked {
+			if !seen[blocked.sessionID] {
+				write(blocked, depth+1, seen)
+			}
+		}
+	}
+
+	for _, head := range shown {
+		resultText.WriteString("\n")
+		write(head, 0, map[int64]bool{})
+	}
+
+	return resultText.String(), nil
+}
+
+// maxChainWait returns the longest wait of a session or the sessions it
+// blocks
+func maxChainWait(session *blockingSession, seen map[int64]bool) int64 {
+	seen[session.sessionID] = true
+	wait := session.waitTimeMS
+	for _, blocked := range session.blocked {
+		if !seen[blocked.sessionID] {
+			if w := maxChainWait(blocked, seen); w > wait {
+				wait = w
+			}
+		}
+	}
+	return wait
+}
+
+// jsonDiagnosticResult is the document a diagnostic tool returns in json
+// format
+type jsonDiagnosticResult struct {
+	Columns   []resultColumn `json:"columns"`
+	Rows      [][]any        `json:"rows"`
+	RowCount  int            `json:"row_count"`
+	Truncated bool           `json:"truncated"`
+}
+
+// formatDiagnosticResult renders the rows of a diagnostic tool under a
+// title, noting when more rows than the cap exist
+func formatDiagnosticResult(title string, result queryResult, format string) (string, error) {
+	if format == formatJSON {
+		data, err := json.MarshalIndent(jsonDiagnosticResult{
+			Columns:   resultColumns(result),
+			Rows:      append(make([][]any, 0, len(result.rows)), result.rows...),
+			RowCount:  len(result.rows),
+			Truncated: result.more,
+		}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	header, lines, err := renderRows(result, format)
+	if err != nil {
+		return "", err
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("%s (%d rows):\n\n", title, len(result.rows)))
+	resultText.WriteString(header)
+	for _, line := range lines {
+		resultText.WriteString(line)
+	}
+	if result.more {
+		resultText.WriteString(fmt.Sprintf("\nResults truncated to %d rows; raise max_rows or narrow the filters to see more.\n", len(result.rows)))
+	}
+
+	return resultText.String(), nil
+}
+
+// diagnosticLimit reads the max_rows argument of a diagnostic tool
+func diagnosticLimit(request mcp.CallToolRequest) (int, error) {
+	value, ok := request.Params.Arguments["max_rows"].(float64)
+	if !ok {
+		return defaultDiagnosticRows, nil
+	}
+	if value < 1 || value > maxDiagnosticRows {
+		return 0, fmt.Errorf("max_rows must be between 1 and %d", maxDiagnosticRows)
+	}
+	return int(value), nil
+}
+
+// nonNegativeArgument reads a whole number argument that defaults to 0
+func nonNegativeArgument(request mcp.CallToolRequest, name string) (int, error) {
+	value, ok := request.Params.Arguments[name].(float64)
+	if !ok {
+		return 0, nil
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+	return int(value), nil
+}
+
+// registerDiagnosticTools registers the SQL Server activity, blocking, wait
+// and query statistics tools
+func registerDiagnosticTools(server *server.MCPServer, connections *connectionRegistry) {
+	// diagnostics returns the connection of a call with its diagnostics
+	diagnostics := func(request mcp.CallToolRequest) (*namedConnection, serverDiagnostics, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		diagnostics, ok := conn.db.(serverDiagnostics)
+		if !ok {
+			return nil, nil, fmt.Errorf("connection %q (%s) does not support server diagnostics", conn.config.Name, conn.config.Engine)
+		}
+		return conn, diagnostics, nil
+	}
+
+	formatOption := mcp.WithString("format",
+		mcp.Description("Output format: text (tab-separated, default), json, csv or markdown"),
+		mcp.Enum(formatText, formatJSON, formatCSV, formatMarkdown),
+	)
+	maxRowsOption := mcp.WithNumber("max_rows",
+		mcp.Description(fmt.Sprintf("Maximum number of rows to return (1 to %d, default %d)", maxDiagnosticRows, defaultDiagnosticRows)),
+	)
+
+	// Register tool for listing active requests
+	activeRequestsTool := mcp.NewTool("sql_active_requests", append([]mcp.ToolOption{
+		mcp.WithDescription("List the requests running on the server, longest running first, with their status, waits, " +
+			"blocking session, resource use and the SQL statement they are executing. Requires VIEW SERVER STATE"),
+		mcp.WithString("database",
+			mcp.Description("Only list requests in this database"),
+		),
+		mcp.WithString("login",
+			mcp.Description("Only list requests of this login"),
+		),
+		mcp.WithNumber("min_elapsed_ms",
+			mcp.Description("Only list requests that have been running for at least this many milliseconds"),
+		),
+		mcp.WithBoolean("include_system",
+			mcp.Description("Also list requests of system sessions. Defaults to false"),
+		),
+		maxRowsOption,
+		formatOption,
+	}, connections.toolOptions()...)...)
+
+	server.AddTool(activeRequestsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, diagnostics, err := diagnostics(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var filter requestFilter
+		filter.database, _ = request.Params.Arguments["database"].(string)
+		filter.login, _ = request.Params.Arguments["login"].(string)
+		filter.includeSystem, _ = request.Params.Arguments["include_system"].(bool)
+		if filter.minElapsedMS, err = nonNegativeArgument(request, "min_elapsed_ms"); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		limit, err := diagnosticLimit(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		format, err := parseResultFormat(request.Params.Arguments["format"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		result, err := diagnostics.activeRequests(queryCtx, filter, limit)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		output, err := formatDiagnosticResult("Active requests", result, format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	})
+
+	// Register tool for showing blocking chains
+	blockingTool := mcp.NewTool("sql_blocking_chains", append([]mcp.ToolOption{
+		mcp.WithDescription("Show the sessions blocking each other as a tree per head blocker, with what each blocked session " +
+			"waits on and the SQL each session runs or last ran. Requires VIEW SERVER STATE"),
+		mcp.WithNumber("min_wait_ms",
+			mcp.Description("Only show chains in which some session has waited at least this many milliseconds"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: text (indented tree, default) or json (nested sessions)"),
+			mcp.Enum(formatText, formatJSON),
+		),
+	}, connections.toolOptions()...)...)
+
+	server.AddTool(blockingTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, diagnostics, err := diagnostics(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		minWaitMS, err := nonNegativeArgument(request, "min_wait_ms")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		format, err := parseResultFormat(request.Params.Arguments["format"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if format != formatText && format != formatJSON {
+			return mcp.NewToolResultError("format must be text or json"), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		sessions, err := diagnostics.blockingSessions(queryCtx)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		output, err := formatBlockingTree(blockingTree(sessions), int64(minWaitMS), format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	})
+
+	// Register tool for reporting top waits
+	topWaitsTool := mcp.NewTool("sql_top_waits", append([]mcp.ToolOption{
+		mcp.WithDescription("Report the wait types the server spends the most time on, leaving out idle and background waits. " +
+			"Requires VIEW SERVER STATE"),
+		mcp.WithString("scope",
+			mcp.Description("cumulative (default) reports the wait statistics since the server started or they were cleared; "+
+				"current groups the tasks waiting right now"),
+			mcp.Enum(waitScopeCumulative, waitScopeCurrent),
+		),
+		maxRowsOption,
+		formatOption,
+	}, connections.toolOptions()...)...)
+
+	server.AddTool(topWaitsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, diagnostics, err := diagnostics(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		scope := waitScopeCumulative
+		if value, ok := request.Params.Arguments["scope"].(string); ok && value != "" {
+			scope = strings.ToLower(value)
+		}
+		if scope != waitScopeCumulative && scope != waitScopeCurrent {
+			return mcp.NewToolResultError(fmt.Sprintf("scope must be %s or %s", waitScopeCumulative, waitScopeCurrent)), nil
+		}
+
+		limit, err := diagnosticLimit(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		format, err := parseResultFormat(request.Params.Arguments["format"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		result, err := diagnostics.topWaits(queryCtx, scope, limit)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		title := "Top waits since the statistics were cleared"
+		if scope == waitScopeCurrent {
+			title = "Current waits"
+		}
+		output, err := formatDiagnosticResult(title, result, format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	})
+
+	// Register tool for reporting the most expensive cached queries
+	orders := make([]string, 0, len(topQueryOrders))
+	for order := range topQueryOrders {
+		orders = append(orders, order)
+	}
+	sort.Strings(orders)
+
+	topQueriesTool := mcp.NewTool("sql_top_queries", append([]mcp.ToolOption{
+		mcp.WithDescription("Report the most expensive query statements in the plan cache with their execution count, CPU, " +
+			"duration, reads and writes. Only queries whose plans are still cached are included. Requires VIEW SERVER STATE"),
+		mcp.WithString("order_by",
+			mcp.Description("The resource to rank queries by: total cpu (default), duration, logical_reads, physical_reads, writes "+
+				"or executions, or avg_cpu and avg_duration per execution"),
+			mcp.Enum(orders...),
+		),
+		mcp.WithString("database",
+			mcp.Description("Only report queries of this database"),
+		),
+		mcp.WithNumber("min_executions",
+			mcp.Description("Only report queries executed at least this many times"),
+		),
+		maxRowsOption,
+		formatOption,
+	}, connections.toolOptions()...)...)
+
+	server.AddTool(topQueriesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, diagnostics, err := diagnostics(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		orderBy := "cpu"
+		if value, ok := request.Params.Arguments["order_by"].(string); ok && value != "" {
+			orderBy = strings.ToLower(value)
+		}
+		if _, ok := topQueryOrders[orderBy]; !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("order_by must be one of %s", strings.Join(orders, ", "))), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+		minExecutions, err := nonNegativeArgument(request, "min_executions")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		limit, err := diagnosticLimit(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		format, err := parseResultFormat(request.Params.Arguments["format"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		result, err := diagnostics.topQueries(queryCtx, orderBy, database, minExecutions, limit)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		output, err := formatDiagnosticResult(fmt.Sprintf("Top queries by %s", strings.ReplaceAll(orderBy, "_", " ")), result, format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	})
+}
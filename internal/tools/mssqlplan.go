@@ -0,0 +1,529 @@
+package tools
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Modes of the explain tool
+const (
+	planModeEstimated = "estimated"
+	planModeActual    = "actual"
+)
+
+// showplanColumn is the name of the column SET STATISTICS XML returns the
+// actual plan of each statement in
+const showplanColumn = "Microsoft SQL Server 2005 XML Showplan"
+
+// planOptions are the session options that turn plan output on or off, or
+// stop statements from executing. Queries may not change them, since that
+// could make an explained query run.
+var planOptions = map[string]bool{
+	"SHOWPLAN_XML":  true,
+	"SHOWPLAN_ALL":  true,
+	"SHOWPLAN_TEXT": true,
+	"STATISTICS":    true,
+	"NOEXEC":        true,
+	"PARSEONLY":     true,
+	"FMTONLY":       true,
+}
+
+// planExplainer is implemented by backends that can return the execution
+// plans of a query for the explain tool
+type planExplainer interface {
+	explainQuery(ctx context.Context, query string, actual bool, params ...any) ([]string, error)
+}
+
+// explainQuery returns the showplan XML documents of query. The estimated
+// plan comes from SET SHOWPLAN_XML, which compiles the query without running
+// it. The actual plan comes from SET STATISTICS XML and runs the query, so
+// only queries that pass the read-only check can be explained that way;
+// they run in a transaction that is rolled back, and their results are read
+// and discarded.
+func (s *sqlServerImpl) explainQuery(ctx context.Context, query string, actual bool, params ...any) ([]string, error) {
+	if err := checkPlanOptions(query); err != nil {
+		return nil, err
+	}
+
+	if actual || s.config.ReadOnly {
+		if err := checkReadOnlyQuery(engineSQLServer, query); err != nil {
+			if actual {
+				return nil, fmt.Errorf("actual plans run the query, use the estimated plan instead: %w", err)
+			}
+			return nil, fmt.Errorf("read-only connection %q: %w", s.config.Name, err)
+		}
+	}
+
+	// The plan option is a session setting, so pin a connection for it
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting connection: %w", err)
+	}
+	defer conn.Close()
+
+	option := "SHOWPLAN_XML"
+	if actual {
+		option = "STATISTICS XML"
+	}
+
+	// SET SHOWPLAN_XML must be the only statement in its batch
+	if _, err := conn.ExecContext(ctx, "SET "+option+" ON"); err != nil {
+		return nil, fmt.Errorf("error enabling %s: %w", option, err)
+	}
+	defer func() {
+		// Never hand a connection with the option still on back to the pool
+		if _, err := conn.ExecContext(context.Background(), "SET "+option+" OFF"); err != nil {
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	// The actual plan runs the query, so run it in a transaction that is
+	// always rolled back in case anything got past the read-only check
+	var runner queryer = conn
+	if actual {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error starting transaction: %w", err)
+		}
+		defer tx.Rollback()
+		runner = tx
+	}
+
+	rows, err := runner.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("error explaining query: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []string
+	for {
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("error getting columns: %w", err)
+		}
+
+		// With STATISTICS XML the query's own result sets come first
+		isPlan := !actual || (len(columns) == 1 && columns[0] == showplanColumn)
+		for rows.Next() {
+			if !isPlan {
+				continue
+			}
+			var plan string
+			if err := rows.Scan(&plan); err != nil {
+				return nil, fmt.Errorf("error scanning plan: %w", err)
+			}
+			plans = append(plans, plan)
+		}
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error explaining query: %w", err)
+	}
+
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("the server returned no plan for the query")
+	}
+
+	return plans, nil
+}
+
+// checkPlanOptions rejects queries that change the plan options set around
+// them by the explain tool
+func checkPlanOptions(query string) error {
+	tokens, err := lexSQL(engineSQLServer, query)
+	if err != nil {
+		return fmt.Errorf("query could not be parsed: %w", err)
+	}
+
+	for i, token := range tokens {
+		if token.isKeyword("SET") && i+1 < len(tokens) && tokens[i+1].kind == tokenWord && planOptions[tokens[i+1].upper] {
+			return fmt.Errorf("SET %s is not allowed in an explained query", tokens[i+1].upper)
+		}
+	}
+
+	return nil
+}
+
+// xmlNode is an element of a showplan document
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []xmlNode  `xml:",any"`
+}
+
+// attr returns the value of the named attribute, or an empty string
+func (n *xmlNode) attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// floatAttr returns the named attribute as a number, or 0
+func (n *xmlNode) floatAttr(name string) float64 {
+	value, _ := strconv.ParseFloat(n.attr(name), 64)
+	return value
+}
+
+// child returns the first child element with the given name, or nil
+func (n *xmlNode) child(name string) *xmlNode {
+	for i := range n.Children {
+		if n.Children[i].XMLName.Local == name {
+			return &n.Children[i]
+		}
+	}
+	return nil
+}
+
+// find returns the descendants with the given name, without descending
+// into the elements it finds or into elements named stop
+func (n *xmlNode) find(name string, stop string) []*xmlNode {
+	var result []*xmlNode
+	for i := range n.Children {
+		child := &n.Children[i]
+		switch child.XMLName.Local {
+		case name:
+			result = append(result, child)
+		case stop:
+		default:
+			result = append(result, child.find(name, stop)...)
+		}
+	}
+	return result
+}
+
+// planStatement is one statement of an execution plan
+type planStatement struct {
+	text          string
+	statementType string
+	subtreeCost   float64
+	estimatedRows float64
+
+	// root is the top operator, nil for statements without a plan such as
+	// variable assignments
+	root *planOperator
+
+	missingIndexes []missingIndex
+	warnings       []string
+}
+
+// planOperator is a node of the operator tree of a statement
+type planOperator struct {
+	nodeID     string
+	physicalOp string
+	logicalOp  string
+
+	// object is the table or index the operator reads, if any
+	object string
+
+	estimatedRows float64
+	subtreeCost   float64
+
+	// costPercent is the operator's own share of the statement's cost
+	costPercent float64
+
+	// actualRows and actualExecutions are summed over threads; they are
+	// only known for actual plans
+	actualRows       *int64
+	actualExecutions *int64
+
+	warnings []string
+	children []*planOperator
+}
+
+// missingIndex is an index the optimizer reports would help the statement
+type missingIndex struct {
+	impact     float64
+	table      string
+	equality   []string
+	inequality []string
+	include    []string
+}
+
+// parseShowplan reads the statements and operator trees of a showplan XML
+// document
+func parseShowplan(document string) ([]planStatement, error) {
+	// The server declares the document as utf-16, but the driver has
+	// already decoded the nvarchar value it came in
+	decoder := xml.NewDecoder(strings.NewReader(document))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var root xmlNode
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("error parsing plan XML: %w", err)
+	}
+
+	var statements []planStatement
+	for _, statementsNode := range root.find("Statements", "") {
+		for i := range statementsNode.Children {
+			node := &statementsNode.Children[i]
+			if !strings.HasPrefix(node.XMLName.Local, "Stmt") {
+				continue
+			}
+
+			statement := planStatement{
+				text:          strings.TrimSpace(node.attr("StatementText")),
+				statementType: node.attr("StatementType"),
+				subtreeCost:   node.floatAttr("StatementSubTreeCost"),
+				estimatedRows: node.floatAttr("StatementEstRows"),
+			}
+
+			if queryPlan := node.child("QueryPlan"); queryPlan != nil {
+				if warnings := queryPlan.child("Warnings"); warnings != nil {
+					statement.warnings = planWarnings(warnings)
+				}
+				if missingIndexes := queryPlan.child("MissingIndexes"); missingIndexes != nil {
+					statement.missingIndexes = parseMissingIndexes(missingIndexes)
+				}
+				if relOp := queryPlan.child("RelOp"); relOp != nil {
+					statement.root = parseRelOp(relOp, statement.subtreeCost)
+				}
+			}
+
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements, nil
+}
+
+// parseRelOp reads an operator and its children. statementCost is the
+// cost of the whole statement the cost percentages are relative to.
+func parseRelOp(node *xmlNode, statementCost float64) *planOperator {
+	operator := &planOperator{
+		nodeID:        node.attr("NodeId"),
+		physicalOp:    node.attr("PhysicalOp"),
+		logicalOp:     node.attr("LogicalOp"),
+		estimatedRows: node.floatAttr("EstimateRows"),
+		subtreeCost:   node.floatAttr("EstimatedTotalSubtreeCost"),
+	}
+
+	if warnings := node.child("Warnings"); warnings != nil {
+		operator.warnings = planWarnings(warnings)
+	}
+
+	if runtime := node.child("RunTimeInformation"); runtime != nil {
+		var rows, executions int64
+		for _, counters := range runtime.find("RunTimeCountersPerThread", "") {
+			r, _ := strconv.ParseInt(counters.attr("ActualRows"), 10, 64)
+			e, _ := strconv.ParseInt(counters.attr("ActualExecutions"), 10, 64)
+			rows += r
+			executions += e
+		}
+		operator.actualRows, operator.actualExecutions = &rows, &executions
+	}
+
+	if objects := node.find("Object", "RelOp"); len(objects) > 0 {
+		operator.object = planObjectName(objects[0])
+	}
+
+	// The operator's own cost is what its subtree costs beyond its children
+	ownCost := operator.subtreeCost
+	for _, child := range node.find("RelOp", "") {
+		childOperator := parseRelOp(child, statementCost)
+		ownCost -= childOperator.subtreeCost
+		operator.children = append(operator.children, childOperator)
+	}
+	if statementCost > 0 && ownCost > 0 {
+		operator.costPercent = ownCost / statementCost * 100
+	}
+
+	return operator
+}
+
+// planObjectName renders the table and index of an Object element as
+// schema.table (index)
+func planObjectName(node *xmlNode) string {
+	unbracket := func(name string) string {
+		return strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(name, "["), "]"), "]]", "]")
+	}
+
+	name := unbracket(node.attr("Table"))
+	if name == "" {
+		return ""
+	}
+	if schemaName := unbracket(node.attr("Schema")); schemaName != "" {
+		name = qualifyTableName(schemaName, name)
+	}
+	if index := unbracket(node.attr("Index")); index != "" {
+		name += " (" + index + ")"
+	}
+	return name
+}
+
+// planWarnings describes the children of a Warnings element
+func planWarnings(node *xmlNode) []string {
+	var warnings []string
+
+	// Boolean warnings are attributes of the Warnings element itself
+	for _, attr := range node.Attrs {
+		if attr.Value == "true" || attr.Value == "1" {
+			warnings = append(warnings, splitCamelCase(attr.Name.Local))
+		}
+	}
+
+	for i := range node.Children {
+		child := &node.Children[i]
+		switch child.XMLName.Local {
+		case "PlanAffectingConvert":
+			warnings = append(warnings, fmt.Sprintf("Implicit conversion affects %s: %s",
+				strings.ToLower(child.attr("ConvertIssue")), child.attr("Expression")))
+		case "ColumnsWithNoStatistics":
+			var columns []string
+			for _, column := range child.find("ColumnReference", "") {
+				columns = append(columns, column.attr("Column"))
+			}
+			warnings = append(warnings, "Columns with no statistics: "+strings.Join(columns, ", "))
+		case "SpillToTempDb":
+			warnings = append(warnings, fmt.Sprintf("Spill to tempdb (spill level %s)", child.attr("SpillLevel")))
+		case "NoJoinPredicate":
+			warnings = append(warnings, "No join predicate")
+		default:
+			warnings = append(warnings, splitCamelCase(child.XMLName.Local))
+		}
+	}
+
+	return warnings
+}
+
+// parseMissingIndexes reads the MissingIndexes element of a query plan
+func parseMissingIndexes(node *xmlNode) []missingIndex {
+	var result []missingIndex
+	for _, group := range node.find("MissingIndexGroup", "") {
+		impact := group.floatAttr("Impact")
+		for _, index := range group.find("MissingIndex", "") {
+			missing := missingIndex{impact: impact}
+			missing.table = planObjectName(index)
+
+			for _, columnGroup := range index.find("ColumnGroup", "") {
+				var columns []string
+				for _, column := range columnGroup.find("Column", "") {
+					columns = append(columns, column.attr("Name"))
+				}
+				switch columnGroup.attr("Usage") {
+				case "EQUALITY":
+					missing.equality = columns
+				case "INEQUALITY":
+					missing.inequality = columns
+				case "INCLUDE":
+					missing.include = columns
+				}
+			}
+
+			result = append(result, missing)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].impact > result[j].impact })
+	return result
+}
+
+// createStatement renders the index as a CREATE INDEX statement
+func (m missingIndex) createStatement() string {
+	keys := append(append([]string{}, m.equality...), m.inequality...)
+
+	var statement strings.Builder
+	statement.WriteString(fmt.Sprintf("CREATE NONCLUSTERED INDEX <name> ON %s (%s)",
+		m.table, strings.Join(keys, ", ")))
+	if len(m.include) > 0 {
+		statement.WriteString(fmt.Sprintf(" INCLUDE (%s)", strings.Join(m.include, ", ")))
+	}
+	return statement.String()
+}
+
+// splitCamelCase turns a showplan name such as UnmatchedIndexes into words
+func splitCamelCase(name string) string {
+	var words strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			words.WriteByte(' ')
+			r += 'a' - 'A'
+		}
+		words.WriteRune(r)
+	}
+	return words.String()
+}
+
+// formatPlan renders the statements of a plan as readable operator trees
+func formatPlan(statements []planStatement, actual bool) string {
+	var resultText strings.Builder
+	kind := "Estimated"
+	if actual {
+		kind = "Actual"
+	}
+	resultText.WriteString(fmt.Sprintf("%s execution plan with %d statements:\n", kind, len(statements)))
+
+	for i, statement := range statements {
+		resultText.WriteString(fmt.Sprintf("\nStatement %d (%s): %s\n", i+1, statement.statementType, abbreviate(statement.text, 200)))
+		if statement.root != nil {
+			resultText.WriteString(fmt.Sprintf("Estimated subtree cost: %s, estimated rows: %s\n",
+				formatPlanNumber(statement.subtreeCost), formatPlanNumber(statement.estimatedRows)))
+		}
+
+		for _, warning := range statement.warnings {
+			resultText.WriteString(fmt.Sprintf("Warning: %s\n", warning))
+		}
+
+		if statement.root != nil {
+			resultText.WriteString("\nOperators:\n")
+			writePlanOperator(&resultText, statement.root, 0)
+		}
+
+		if len(statement.missingIndexes) > 0 {
+			resultText.WriteString("\nMissing indexes:\n")
+			for _, index := range statement.missingIndexes {
+				resultText.WriteString(fmt.Sprintf("- %s, estimated impact %.1f%%\n", index.table, index.impact))
+				resultText.WriteString(fmt.Sprintf("  %s\n", index.createStatement()))
+			}
+		}
+	}
+
+	return resultText.String()
+}
+
+// writePlanOperator renders an operator and its children, indented by depth
+func writePlanOperator(resultText *strings.Builder, operator *planOperator, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	name := operator.physicalOp
+	if operator.logicalOp != "" && operator.logicalOp != operator.physicalOp {
+		name += " (" + operator.logicalOp + ")"
+	}
+	if operator.object != "" {
+		name += " on " + operator.object
+	}
+
+	resultText.WriteString(fmt.Sprintf("%s- [%s] %s: cost %.1f%%, estimated rows %s",
+		indent, operator.nodeID, name, operator.costPercent, formatPlanNumber(operator.estimatedRows)))
+	if operator.actualRows != nil {
+		resultText.WriteString(fmt.Sprintf(", actual rows %d in %d executions", *operator.actualRows, *operator.actualExecutions))
+	}
+	resultText.WriteString("\n")
+
+	for _, warning := range operator.warnings {
+		resultText.WriteString(fmt.Sprintf("%s  ! %s\n", indent, warning))
+	}
+
+	for _, child := range operator.children {
+		writePlanOperator(resultText, child, depth+1)
+	}
+}
+
+// formatPlanNumber renders a cost or row estimate without needless digits
+func formatPlanNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,522 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+)
+
+// Defaults and limits of the table profiling tool
+const (
+	// defaultProfileTimeBudget is the time spent profiling one table when
+	// the connection does not configure a budget
+	defaultProfileTimeBudget = 30 * time.Second
+
+	// defaultProfileSampleRows is the number of rows above which a table is
+	// profiled from a sample of about that many rows
+	defaultProfileSampleRows = 100000
+
+	// defaultProfileTopValues and maxProfileTopValues bound the most
+	// frequent values listed per column
+	defaultProfileTopValues = 5
+	maxProfileTopValues     = 50
+
+	// profileValueLength is the number of characters or bytes of string and
+	// binary values compared when counting distinct and frequent values
+	profileValueLength = 256
+
+	// profileSampleSeed makes every query of a profile read the same sample
+	profileSampleSeed = 1
+)
+
+// unprofiledTypes are the SQL Server types that cannot be compared, so only
+// their null percentage is profiled
+var unprofiledTypes = map[string]bool{
+	"text":      true,
+	"ntext":     true,
+	"image":     true,
+	"xml":       true,
+	"geography": true,
+	"geometry":  true,
+}
+
+// tableProfiler is implemented by backends that can profile the data of a
+// table for the profile_table tool
+type tableProfiler interface {
+	profileTable(ctx context.Context, tableName string, options profileOptions) (tableProfile, error)
+}
+
+// profileOptions controls how much of a table is profiled
+type profileOptions struct {
+	// columns limits the profile to the named columns; all columns are
+	// profiled when it is empty
+	columns []string
+
+	// topValues is the number of most frequent values listed per column
+	topValues int
+
+	// sampleRows is the row count above which the table is sampled
+	sampleRows int64
+
+	// budget is the time after which profiling stops
+	budget time.Duration
+}
+
+// tableProfile is the profile of a table's data
+type tableProfile struct {
+	table string
+
+	// rowCount is the number of rows from the table's metadata
+	rowCount int64
+
+	// reservedKB is the space allocated to the table and its indexes,
+	// dataKB and indexKB the space used by its data and by the other indexes
+	reservedKB int64
+	dataKB     int64
+	indexKB    int64
+
+	// sampled is set when the column statistics come from a sample of
+	// sampleRows rows rather than the whole table
+	sampled    bool
+	sampleRows int64
+
+	columns []columnProfile
+
+	// masked lists the columns whose values were masked
+	masked []maskedColumn
+
+	// incomplete explains why some columns were not profiled
+	incomplete string
+}
+
+// columnProfile is the profile of one column
+type columnProfile struct {
+	name     string
+	typeName string
+
+	// baseType is typeName without its length or precision, such as
+	// nvarchar for nvarchar(50)
+	baseType string
+
+	// profiled is set once the column's statistics were read
+	profiled bool
+
+	nullPercent float64
+
+	// distinct is the number of distinct values, estimated from the
+	// sample when distinctEstimated is set
+	distinct          int64
+	distinctEstimated bool
+
+	// comparable is set for types with a min, max and frequent values
+	comparable bool
+	min        any
+	max        any
+	topValues  []valueCount
+}
+
+// valueCount is a value and the number of rows holding it
+type valueCount struct {
+	value any
+	count int64
+}
+
+// profileTable profiles a table, reading a sample of the rows of large
+// tables. The statistics are read without taking shared locks and without
+// parallelism so that profiling does not get in the way of other work.
+// When the time budget runs out, the columns profiled so far are returned.
+// Values of the columns the masking policy masks are masked.
+func (s *sqlServerImpl) profileTable(ctx context.Context, tableName string, options profileOptions) (tableProfile, error) {
+	schema, err := s.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return tableProfile{}, err
+	}
+
+	columns, err := profileColumns(schema, options.columns)
+	if err != nil {
+		return tableProfile{}, err
+	}
+
+	profile := tableProfile{table: schema.QualifiedName(), columns: columns}
+	name := quoteSQLServerName(schema.Schema, schema.TableName)
+
+	budgetCtx, cancel := context.WithCancel(ctx)
+	if options.budget > 0 {
+		budgetCtx, cancel = context.WithTimeout(ctx, options.budget)
+	}
+	defer cancel()
+
+	// Row count and size come from the metadata, not from counting rows
+	var allocationUnits int64
+	err = s.db.QueryRowContext(budgetCtx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN p.index_id IN (0, 1) AND a.type = 1 THEN p.rows END), 0),
+			COALESCE(SUM(a.total_pages), 0) * 8,
+			COALESCE(SUM(CASE WHEN p.index_id IN (0, 1) THEN a.used_pages END), 0) * 8,
+			COALESCE(SUM(CASE WHEN p.index_id > 1 THEN a.used_pages END), 0) * 8
+		FROM sys.tables t
+		JOIN sys.partitions p ON p.object_id = t.object_id
+		JOIN sys.allocation_units a ON a.container_id = CASE WHEN a.type = 2 THEN p.partition_id ELSE p.hobt_id END
+		WHERE t.object_id = OBJECT_ID(@p1)`, name).Scan(
+		&allocationUnits, &profile.rowCount, &profile.reservedKB, &profile.dataKB, &profile.indexKB)
+	if err != nil {
+		return tableProfile{}, fmt.Errorf("error reading the size of table %s: %w", profile.table, err)
+	}
+	if allocationUnits == 0 {
+		return tableProfile{}, fmt.Errorf("%s is not a table", profile.table)
+	}
+
+	source := name + " AS src"
+	if profile.rowCount > options.sampleRows {
+		profile.sampled = true
+		source += fmt.Sprintf(" TABLESAMPLE SYSTEM (%d ROWS) REPEATABLE (%d)", options.sampleRows, profileSampleSeed)
+	}
+	source += " WITH (NOLOCK)"
+
+	// One pass over the rows for the null counts, minimums and maximums
+	selects := []string{"COUNT_BIG(*)"}
+	for _, column := range profile.columns {
+		quoted := quoteSQLServerIdentifier(column.name)
+		selects = append(selects, fmt.Sprintf("SUM(CASE WHEN %s IS NULL THEN 1 ELSE 0 END)", quoted))
+		if column.comparable {
+			expression := profileExpression(column)
+			selects = append(selects, fmt.Sprintf("MIN(%s)", expression), fmt.Sprintf("MAX(%s)", expression))
+		}
+	}
+
+	values := make([]any, len(selects))
+	targets := make([]any, len(selects))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+
+	// Partial profiles are masked as well
+	policy := s.config.Masking.policy()
+
+	query := fmt.Sprintf("SELECT %s FROM %s OPTION (MAXDOP 1)", strings.Join(selects, ", "), source)
+	if err := s.db.QueryRowContext(budgetCtx, query).Scan(targets...); err != nil {
+		err = profileError(ctx, budgetCtx, &profile, fmt.Errorf("error profiling table %s: %w", profile.table, err))
+		profile.mask(policy)
+		return profile, err
+	}
+
+	profile.sampleRows, _ = values[0].(int64)
+	index := 1
+	for i := range profile.columns {
+		column := &profile.columns[i]
+		nulls, _ := values[index].(int64)
+		index++
+		if profile.sampleRows > 0 {
+			column.nullPercent = float64(nulls) / float64(profile.sampleRows) * 100
+		}
+		if column.comparable {
+			column.min = s.convertTypedValue(strings.ToUpper(column.baseType), values[index])
+			column.max = s.convertTypedValue(strings.ToUpper(column.baseType), values[index+1])
+			index += 2
+		}
+		column.profiled = !column.comparable
+	}
+
+	// Then one grouping query per column for its distinct and top values
+	for i := range profile.columns {
+		column := &profile.columns[i]
+		if !column.comparable {
+			continue
+		}
+
+		if err := s.profileColumnValues(budgetCtx, source, &profile, column, options.topValues); err != nil {
+			err = profileError(ctx, budgetCtx, &profile, err)
+			profile.mask(policy)
+			return profile, err
+		}
+		column.profiled = true
+	}
+
+	profile.mask(policy)
+	return profile, nil
+}
+
+// mask replaces the minimums, maximums and frequent values of the columns
+// the masking policy masks, by name or because one of those values looks
+// like personal data. Null percentages and distinct counts are kept.
+func (p *tableProfile) mask(policy *maskingPolicy) {
+	for i := range p.columns {
+		column := &p.columns[i]
+
+		reason := policy.columnReason(column.name)
+		if reason == "" {
+			values := []any{column.min, column.max}
+			for _, top := range column.topValues {
+				values = append(values, top.value)
+			}
+			reason = policy.valuesReason(values)
+		}
+		if reason == "" {
+			continue
+		}
+
+		column.min, column.max = policy.maskValue(column.min), policy.maskValue(column.max)
+		for j := range column.topValues {
+			column.topValues[j].value = policy.maskValue(column.topValues[j].value)
+		}
+		p.masked = append(p.masked, maskedColumn{name: column.name, reason: reason})
+	}
+}
+
+// profileColumnValues reads the distinct count and the most frequent
+// values of a column. On a sample, the distinct count of the table is
+// estimated from the values seen once in the sample (the GEE estimator).
+func (s *sqlServerImpl) profileColumnValues(ctx context.Context, source string, profile *tableProfile, column *columnProfile, topValues int) error {
+	expression := profileExpression(*column)
+	query := fmt.Sprintf(`
+		SELECT TOP (@p1) v, n, COUNT_BIG(*) OVER (), SUM(CASE WHEN n = 1 THEN 1 ELSE 0 END) OVER ()
+		FROM (
+			SELECT %[1]s AS v, COUNT_BIG(*) AS n
+			FROM %[2]s
+			WHERE %[3]s IS NOT NULL
+			GROUP BY %[1]s
+		) g
+		ORDER BY n DESC, v
+		OPTION (MAXDOP 1)`, expression, source, quoteSQLServerIdentifier(column.name))
+
+	rows, err := s.db.QueryContext(ctx, query, topValues)
+	if err != nil {
+		return fmt.Errorf("error profiling column %s: %w", column.name, err)
+	}
+	defer rows.Close()
+
+	var distinct, singletons int64
+	for rows.Next() {
+		var value any
+		var count int64
+		if err := rows.Scan(&value, &count, &distinct, &singletons); err != nil {
+			return fmt.Errorf("error profiling column %s: %w", column.name, err)
+		}
+		column.topValues = append(column.topValues, valueCount{
+			value: s.convertTypedValue(strings.ToUpper(column.baseType), value),
+			count: count,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error profiling column %s: %w", column.name, err)
+	}
+
+	column.distinct = distinct
+	if profile.sampled && profile.sampleRows > 0 {
+		// Values seen once stand for many unseen ones, the others are
+		// likely to have been seen already
+		estimate := math.Sqrt(float64(profile.rowCount)/float64(profile.sampleRows))*float64(singletons) + float64(distinct-singletons)
+		nonNull := float64(profile.rowCount) * (100 - column.nullPercent) / 100
+		column.distinct = int64(math.Round(math.Max(float64(distinct), math.Min(estimate, nonNull))))
+		column.distinctEstimated = true
+
+		// Frequencies in the sample are scaled to the table
+		scale := float64(profile.rowCount) / float64(profile.sampleRows)
+		for i := range column.topValues {
+			column.topValues[i].count = int64(math.Round(float64(column.topValues[i].count) * scale))
+		}
+	}
+
+	return nil
+}
+
+// profileColumns returns the columns of schema to profile, all of them or
+// the named ones in table order
+func profileColumns(schema interfaces.TableSchema, names []string) ([]columnProfile, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[strings.ToLower(name)] = true
+	}
+
+	var columns []columnProfile
+	for _, column := range schema.Columns {
+		if len(names) > 0 && !wanted[strings.ToLower(column.Name)] {
+			continue
+		}
+		delete(wanted, strings.ToLower(column.Name))
+
+		typeName := strings.ToLower(column.Type)
+		baseType, _, _ := strings.Cut(typeName, "(")
+		columns = append(columns, columnProfile{
+			name:       column.Name,
+			typeName:   typeName,
+			baseType:   baseType,
+			comparable: !unprofiledTypes[baseType],
+		})
+	}
+
+	for _, name := range names {
+		if wanted[strings.ToLower(name)] {
+			return nil, fmt.Errorf("column %s not found in table %s", name, schema.QualifiedName())
+		}
+	}
+
+	return columns, nil
+}
+
+// profileExpression returns the expression a column's values are compared
+// by. Bits cannot be aggregated, and long strings and binary values are
+// compared by their first profileValueLength characters or bytes.
+func profileExpression(column columnProfile) string {
+	quoted := quoteSQLServerIdentifier(column.name)
+	switch column.baseType {
+	case "bit":
+		return fmt.Sprintf("CAST(%s AS tinyint)", quoted)
+	case "char", "varchar", "nchar", "nvarchar", "binary", "varbinary":
+		return fmt.Sprintf("SUBSTRING(%s, 1, %d)", quoted, profileValueLength)
+	}
+	return quoted
+}
+
+// profileError turns the end of the time budget into a partial profile: it
+// records on profile which columns were left out and returns nil. Other
+// errors, including the statement context ending, are returned as they are.
+func profileError(ctx context.Context, budgetCtx context.Context, profile *tableProfile, err error) error {
+	if ctx.Err() != nil || budgetCtx.Err() != context.DeadlineExceeded {
+		return err
+	}
+
+	var skipped []string
+	for _, column := range profile.columns {
+		if !column.profiled {
+			skipped = append(skipped, column.name)
+		}
+	}
+	profile.incomplete = fmt.Sprintf("the time budget ran out before profiling %s", strings.Join(skipped, ", "))
+	return nil
+}
+
+// jsonTableProfile is the document returned for a table profile in json
+// format
+type jsonTableProfile struct {
+	Table      string              `json:"table"`
+	RowCount   int64               `json:"row_count"`
+	ReservedKB int64               `json:"reserved_kb"`
+	DataKB     int64               `json:"data_kb"`
+	IndexKB    int64               `json:"index_kb"`
+	Sampled    bool                `json:"sampled"`
+	SampleRows int64               `json:"sample_rows"`
+	Columns    []jsonColumnProfile `json:"columns"`
+	Masked     []string            `json:"masked_columns,omitempty"`
+	Incomplete string              `json:"incomplete,omitempty"`
+}
+
+// jsonColumnProfile is a column of a table profile in json format
+type jsonColumnProfile struct {
+	Name              string           `json:"name"`
+	Type              string           `json:"type"`
+	Profiled          bool             `json:"profiled"`
+	NullPercent       float64          `json:"null_percent"`
+	Distinct          *int64           `json:"distinct,omitempty"`
+	DistinctEstimated bool             `json:"distinct_estimated,omitempty"`
+	Min               any              `json:"min,omitempty"`
+	Max               any              `json:"max,omitempty"`
+	TopValues         []jsonValueCount `json:"top_values,omitempty"`
+}
+
+// jsonValueCount is a frequent value in json format
+type jsonValueCount struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+// formatTableProfile renders a table profile as text or json
+func formatTableProfile(profile tableProfile, format string) (string, error) {
+	if format == formatJSON {
+		document := jsonTableProfile{
+			Table:      profile.table,
+			RowCount:   profile.rowCount,
+			ReservedKB: profile.reservedKB,
+			DataKB:     profile.dataKB,
+			IndexKB:    profile.indexKB,
+			Sampled:    profile.sampled,
+			SampleRows: profile.sampleRows,
+			Columns:    make([]jsonColumnProfile, 0, len(profile.columns)),
+			Masked:     maskedColumnNames(profile.masked),
+			Incomplete: profile.incomplete,
+		}
+		for _, column := range profile.columns {
+			jsonColumn := jsonColumnProfile{
+				Name:     column.name,
+				Type:     column.typeName,
+				Profiled: column.profiled,
+			}
+			if column.profiled {
+				jsonColumn.NullPercent = roundPercent(column.nullPercent)
+				if column.comparable {
+					distinct := column.distinct
+					jsonColumn.Distinct = &distinct
+					jsonColumn.DistinctEstimated = column.distinctEstimated
+					jsonColumn.Min, jsonColumn.Max = column.min, column.max
+					for _, top := range column.topValues {
+						jsonColumn.TopValues = append(jsonColumn.TopValues, jsonValueCount{Value: top.value, Count: top.count})
+					}
+				}
+			}
+			document.Columns = append(document.Columns, jsonColumn)
+		}
+
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Profile of table %s:\n\n", profile.table))
+	resultText.WriteString(fmt.Sprintf("Rows: %d\n", profile.rowCount))
+	resultText.WriteString(fmt.Sprintf("Size: %d KB reserved, %d KB data, %d KB indexes\n", profile.reservedKB, profile.dataKB, profile.indexKB))
+	if profile.sampled {
+		resultText.WriteString(fmt.Sprintf("Column statistics are estimated from a sample of %d rows.\n", profile.sampleRows))
+	}
+	if profile.incomplete != "" {
+		resultText.WriteString(fmt.Sprintf("Profile incomplete: %s.\n", profile.incomplete))
+	}
+
+	resultText.WriteString("\nCOLUMN_NAME\tDATA_TYPE\tNULL_PERCENT\tDISTINCT\tMIN\tMAX\tTOP_VALUES\n")
+	resultText.WriteString("----------\t----------\t----------\t----------\t----------\t----------\t----------\n")
+	for _, column := range profile.columns {
+		if !column.profiled {
+			resultText.WriteString(fmt.Sprintf("%s\t%s\t\t\t\t\t(not profiled)\n", column.name, column.typeName))
+			continue
+		}
+
+		distinct, min, max, top := "", "", "", ""
+		if column.comparable {
+			distinct = fmt.Sprintf("%d", column.distinct)
+			if column.distinctEstimated {
+				distinct = "~" + distinct
+			}
+			min = abbreviate(formatCell(column.min, "NULL"), 40)
+			max = abbreviate(formatCell(column.max, "NULL"), 40)
+
+			values := make([]string, len(column.topValues))
+			for i, value := range column.topValues {
+				values[i] = fmt.Sprintf("%s (%d)", abbreviate(formatCell(value.value, "NULL"), 40), value.count)
+			}
+			top = strings.Join(values, ", ")
+		}
+
+		resultText.WriteString(fmt.Sprintf("%s\t%s\t%.2f\t%s\t%s\t%s\t%s\n",
+			column.name, column.typeName, column.nullPercent, distinct, min, max, top))
+	}
+
+	if len(profile.masked) > 0 {
+		resultText.WriteString("\n")
+		resultText.WriteString(formatMaskedColumns(profile.masked))
+	}
+
+	return resultText.String(), nil
+}
+
+// roundPercent rounds a percentage to two decimals
+func roundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
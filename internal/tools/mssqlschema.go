@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+)
+
+// loadSchemaSnapshot reads the columns of every user table and view and
+// the source of every view, procedure and function in two queries
+func (s *sqlServerImpl) loadSchemaSnapshot(ctx context.Context) (*schemaSnapshot, error) {
+	snapshot := &schemaSnapshot{loadedAt: time.Now()}
+
+	query := `
+		SELECT
+			SCHEMA_NAME(o.schema_id),
+			o.name,
+			o.type,
+			c.name,
+			TYPE_NAME(c.user_type_id),
+			c.max_length,
+			c.precision,
+			c.scale,
+			c.is_nullable,
+			OBJECT_DEFINITION(c.default_object_id),
+			CASE WHEN EXISTS (
+				SELECT 1
+				FROM sys.indexes i
+				JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+				WHERE i.object_id = o.object_id AND i.is_primary_key = 1 AND ic.column_id = c.column_id
+			) THEN 1 ELSE 0 END
+		FROM sys.objects o
+		JOIN sys.columns c ON c.object_id = o.object_id
+		WHERE o.type IN ('U', 'V')
+			AND o.is_ms_shipped = 0
+		ORDER BY SCHEMA_NAME(o.schema_id), o.name, c.column_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns: %w", err)
+	}
+	defer rows.Close()
+
+	var current *interfaces.TableSchema
+	for rows.Next() {
+		var (
+			schemaName, objectName, objectType, typeName string
+			maxLength, precision, scale                  int
+			column                                       interfaces.ColumnInfo
+			defaultValue                                 *string
+		)
+		if err := rows.Scan(&schemaName, &objectName, &objectType, &column.Name, &typeName, &maxLength, &precision, &scale,
+			&column.Nullable, &defaultValue, &column.IsPrimaryKey); err != nil {
+			return nil, fmt.Errorf("error scanning column: %w", err)
+		}
+
+		column.Type = sqlServerTypeName(typeName, maxLength, precision, scale)
+		if defaultValue != nil {
+			column.DefaultValue = *defaultValue
+		}
+
+		if current == nil || current.Schema != schemaName || current.TableName != objectName {
+			objects := &snapshot.tables
+			if strings.TrimSpace(objectType) == "V" {
+				objects = &snapshot.views
+			}
+			*objects = append(*objects, interfaces.TableSchema{Schema: schemaName, TableName: objectName})
+			current = &(*objects)[len(*objects)-1]
+		}
+		current.Columns = append(current.Columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %w", err)
+	}
+
+	// OBJECT_DEFINITION returns NULL for encrypted and CLR modules
+	query = `
+		SELECT
+			SCHEMA_NAME(o.schema_id),
+			o.name,
+			o.type,
+			ISNULL(OBJECT_DEFINITION(o.object_id), '')
+		FROM sys.objects o
+		WHERE o.type IN ('V', ` + sqlServerRoutineTypes + `)
+			AND o.is_ms_shipped = 0
+		ORDER BY SCHEMA_NAME(o.schema_id), o.name
+	`
+
+	rows, err = s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting object definitions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			module     interfaces.ObjectDefinition
+			objectType string
+		)
+		if err := rows.Scan(&module.Schema, &module.Name, &objectType, &module.Definition); err != nil {
+			return nil, fmt.Errorf("error scanning object definition: %w", err)
+		}
+
+		module.Type, _ = sqlServerRoutineKind(objectType, "")
+		snapshot.modules = append(snapshot.modules, module)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating object definitions: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// schemaVersion returns the time user objects were last created or altered
+// along with their count, which also changes when objects are dropped
+func (s *sqlServerImpl) schemaVersion(ctx context.Context) (string, error) {
+	query := `
+		SELECT CONCAT(CONVERT(varchar(27), MAX(modify_date), 126), '/', COUNT(*))
+		FROM sys.objects
+		WHERE is_ms_shipped = 0
+	`
+
+	var version string
+	if err := s.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return "", fmt.Errorf("error getting schema version: %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+)
+
+// Encodings of binary values in query results
+const (
+	binaryEncodingHex    = "hex"
+	binaryEncodingBase64 = "base64"
+)
+
+// ISO 8601 layouts of the SQL Server date and time types. Only
+// datetimeoffset carries an offset; the other types are rendered without
+// one since the server stores no time zone for them.
+const (
+	sqlServerDateLayout           = "2006-01-02"
+	sqlServerTimeLayout           = "15:04:05.9999999"
+	sqlServerDateTimeLayout       = "2006-01-02T15:04:05.9999999"
+	sqlServerDateTimeOffsetLayout = "2006-01-02T15:04:05.9999999Z07:00"
+)
+
+// convertValue is the valueConverter of SQL Server connections. The driver
+// returns decimal, money, uniqueidentifier and binary values all as bytes,
+// so the column type decides how they are read: decimals are kept as exact
+// strings, GUIDs are put in canonical form and binary data is encoded as
+// configured for the connection.
+func (s *sqlServerImpl) convertValue(columnType *sql.ColumnType, value any) any {
+	return s.convertTypedValue(columnType.DatabaseTypeName(), value)
+}
+
+// convertTypedValue converts a value the driver returned for the named
+// SQL Server type, given in upper case, see convertValue
+func (s *sqlServerImpl) convertTypedValue(typeName string, value any) any {
+	switch v := value.(type) {
+	case []byte:
+		switch typeName {
+		case "DECIMAL", "NUMERIC", "MONEY", "SMALLMONEY":
+			return string(v)
+		case "UNIQUEIDENTIFIER":
+			if guid, ok := formatSQLServerGUID(v); ok {
+				return guid
+			}
+		case "BINARY", "VARBINARY", "IMAGE":
+			return encodeBinary(s.config.BinaryEncoding, v)
+		}
+
+		// sql_variant and other types may hold either text or binary data
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return encodeBinary(s.config.BinaryEncoding, v)
+
+	case time.Time:
+		switch typeName {
+		case "DATE":
+			return v.Format(sqlServerDateLayout)
+		case "TIME":
+			return v.Format(sqlServerTimeLayout)
+		case "DATETIME", "DATETIME2", "SMALLDATETIME":
+			return v.Format(sqlServerDateTimeLayout)
+		}
+		return v.Format(sqlServerDateTimeOffsetLayout)
+	}
+
+	return value
+}
+
+// sqlServerTypeName renders a type from the catalog views the way it is
+// declared, such as nvarchar(50), varbinary(max) or decimal(18,2).
+// maxLength is in bytes, and -1 for the max types.
+func sqlServerTypeName(typeName string, maxLength int, precision int, scale int) string {
+	switch typeName {
+	case "varchar", "char", "varbinary", "binary":
+		if maxLength == -1 {
+			return typeName + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", typeName, maxLength)
+	case "nvarchar", "nchar":
+		if maxLength == -1 {
+			return typeName + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", typeName, maxLength/2)
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", typeName, precision, scale)
+	case "datetime2", "datetimeoffset", "time":
+		return fmt.Sprintf("%s(%d)", typeName, scale)
+	}
+	return typeName
+}
+
+// sqlServerColumnType renders the type of an INFORMATION_SCHEMA.COLUMNS
+// row the way it is declared. CHARACTER_MAXIMUM_LENGTH counts characters
+// rather than bytes, and the scale of the time types is their
+// DATETIME_PRECISION.
+func sqlServerColumnType(dataType string, maxLength sql.NullInt64, precision sql.NullInt64, scale sql.NullInt64, datetimePrecision sql.NullInt64) string {
+	switch dataType {
+	case "nvarchar", "nchar":
+		if maxLength.Int64 > 0 {
+			maxLength.Int64 *= 2
+		}
+	case "datetime2", "datetimeoffset", "time":
+		scale = datetimePrecision
+	}
+	return sqlServerTypeName(dataType, int(maxLength.Int64), int(precision.Int64), int(scale.Int64))
+}
+
+// formatSQLServerGUID renders a uniqueidentifier in its canonical form. SQL
+// Server stores the first three groups little-endian, so their bytes are
+// reversed to match the text the server itself shows.
+func formatSQLServerGUID(b []byte) (string, bool) {
+	if len(b) != 16 {
+		return "", false
+	}
+
+	return fmt.Sprintf("%02X%02X%02X%02X-%02X%02X-%02X%02X-%X-%X",
+		b[3], b[2], b[1], b[0],
+		b[5], b[4],
+		b[7], b[6],
+		b[8:10], b[10:16]), true
+}
+
+// encodeBinary renders binary data as 0x-prefixed hex or as base64
+func encodeBinary(encoding string, b []byte) string {
+	if encoding == binaryEncodingBase64 {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return "0x" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// decodeBinary reads binary data given as 0x-prefixed hex or, otherwise,
+// as base64
+func decodeBinary(value string) ([]byte, error) {
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		b, err := hex.DecodeString(value[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value %q", abbreviate(value, 40))
+		}
+		return b, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 value %q", abbreviate(value, 40))
+	}
+	return b, nil
+}
+
+// sqlServerParameterValue converts a JSON parameter value for a procedure
+// parameter declared as typeName, such as varbinary(16). Binary data is
+// given as 0x-prefixed hex or base64; other strings are left for the server
+// to convert.
+func sqlServerParameterValue(typeName string, value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	switch baseType, _, _ := strings.Cut(typeName, "("); baseType {
+	case "binary", "varbinary", "image":
+		return decodeBinary(s)
+	}
+	return s, nil
+}
+
+// sqlServerOutputParameterValue returns the value an OUTPUT parameter
+// declared as typeName is bound with. NULL still needs a type, so it is
+// bound as the closest typed NULL, or as empty binary data for binary
+// parameters. Strings are sent as nvarchar(max) so
+// that longer output values are not truncated to the input's length.
+func sqlServerOutputParameterValue(typeName string, value any) (any, error) {
+	baseType, _, _ := strings.Cut(typeName, "(")
+	if value == nil {
+		switch baseType {
+		case "tinyint", "smallint", "int", "bigint":
+			return sql.NullInt64{}, nil
+		case "bit":
+			return sql.NullBool{}, nil
+		case "float", "real":
+			return sql.NullFloat64{}, nil
+		case "binary", "varbinary", "image":
+			return []byte{}, nil
+		}
+		return sql.NullString{}, nil
+	}
+
+	value, err := sqlServerParameterValue(typeName, value)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := value.(string); ok {
+		return mssql.NVarCharMax(s), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,753 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mysqlImpl implements the interfaces.Database interface for MySQL and MariaDB
+type mysqlImpl struct {
+	config connectionConfig
+	db     *sql.DB
+}
+
+// Connect establishes a connection to the database
+func (m *mysqlImpl) Connect() error {
+	var err error
+	m.db, err = createMySQLConnection(m.config)
+	return err
+}
+
+// Disconnect closes the connection to the database
+func (m *mysqlImpl) Disconnect() error {
+	if m.db != nil {
+		return m.db.Close()
+	}
+	return nil
+}
+
+// Query executes a query and returns the rows of its first result set
+func (m *mysqlImpl) Query(ctx context.Context, query string, params ...any) ([]map[string]any, error) {
+	return queryDatabase(ctx, m.db, m.config, convertMySQLValue, query, params...)
+}
+
+// QueryResultSets executes a query and returns the rows of every result set
+func (m *mysqlImpl) QueryResultSets(ctx context.Context, query string, params ...any) ([][]map[string]any, error) {
+	return queryDatabaseResultSets(ctx, m.db, m.config, convertMySQLValue, query, params...)
+}
+
+// Execute runs a query that doesn't return results (INSERT, UPDATE, DELETE)
+func (m *mysqlImpl) Execute(ctx context.Context, query string, params ...any) error {
+	if err := checkWritable(m.config); err != nil {
+		return err
+	}
+
+	if _, err := m.db.ExecContext(ctx, query, params...); err != nil {
+		return fmt.Errorf("error executing statement: %w", err)
+	}
+
+	return nil
+}
+
+// queryPage executes a query and returns at most limit rows of its result
+// sets after skipping offset rows
+func (m *mysqlImpl) queryPage(ctx context.Context, query string, offset int, limit int, params ...any) ([]queryResult, error) {
+	return queryDatabasePage(ctx, m.db, m.config, convertMySQLValue, query, offset, limit, params...)
+}
+
+// executeStatement runs a write statement in a transaction, committing it
+// only when commit is set, and returns the number of rows affected
+func (m *mysqlImpl) executeStatement(ctx context.Context, statement string, commit bool) (int64, error) {
+	return executeInTransaction(ctx, m.db, m.config, statement, commit)
+}
+
+// GetSchema returns database schema information
+func (m *mysqlImpl) GetSchema(ctx context.Context) (interfaces.SchemaInfo, error) {
+	var databaseName sql.NullString
+	if err := m.db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&databaseName); err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error getting database name: %w", err)
+	}
+
+	tables, err := m.getDBTables(ctx)
+	if err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error getting tables: %w", err)
+	}
+
+	result := interfaces.SchemaInfo{
+		DatabaseName: databaseName.String,
+		Tables:       make([]interfaces.TableSchema, 0, len(tables)),
+	}
+
+	// Collect schema information for each table
+	for _, tableName := range tables {
+		tableSchema, err := m.GetTableSchema(ctx, tableName)
+		if err != nil {
+			return interfaces.SchemaInfo{}, fmt.Errorf("error getting schema for table %s: %w", tableName, err)
+		}
+		result.Tables = append(result.Tables, tableSchema)
+	}
+
+	return result, nil
+}
+
+// GetTables returns all table names in the current database
+func (m *mysqlImpl) GetTables(ctx context.Context) ([]string, error) {
+	return m.getDBTables(ctx)
+}
+
+// GetTableSchema returns column information for a specific table.
+// tableName may be qualified with a database name ("shop.orders");
+// unqualified names are looked up in the connection's current database.
+func (m *mysqlImpl) GetTableSchema(ctx context.Context, tableName string) (interfaces.TableSchema, error) {
+	databaseName, relName := splitTableName(tableName)
+
+	// COLUMN_TYPE keeps the details DATA_TYPE drops, such as
+	// "int(10) unsigned", "enum('new','paid')" and "set('a','b')"
+	query := `
+		SELECT
+			TABLE_SCHEMA,
+			COLUMN_NAME,
+			COLUMN_TYPE,
+			IS_NULLABLE,
+			COLUMN_DEFAULT,
+			COLUMN_KEY,
+			EXTRA
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, databaseName, relName)
+	if err != nil {
+		return interfaces.TableSchema{}, fmt.Errorf("error getting table schema: %w", err)
+	}
+	defer rows.Close()
+
+	result := interfaces.TableSchema{
+		TableName: relName,
+	}
+
+	for rows.Next() {
+		var (
+			name, columnType, isNullable, columnKey, extra string
+			defaultValue                                   sql.NullString
+		)
+		if err := rows.Scan(&result.Schema, &name, &columnType, &isNullable, &defaultValue, &columnKey, &extra); err != nil {
+			return interfaces.TableSchema{}, fmt.Errorf("error scanning column: %w", err)
+		}
+
+		// Surface auto_increment and generated columns alongside the type
+		if extra != "" {
+			columnType = columnType + " " + extra
+		}
+
+		columnInfo := interfaces.ColumnInfo{
+			Name:         name,
+			Type:         columnType,
+			Nullable:     isNullable == "YES",
+			IsPrimaryKey: columnKey == "PRI",
+		}
+
+		// Handle default value if present
+		if defaultValue.Valid {
+			columnInfo.DefaultValue = defaultValue.String
+		}
+
+		result.Columns = append(result.Columns, columnInfo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return interfaces.TableSchema{}, fmt.Errorf("error iterating columns: %w", err)
+	}
+
+	if len(result.Columns) == 0 {
+		return interfaces.TableSchema{}, fmt.Errorf("table %s not found", tableName)
+	}
+
+	uniqueConstraints, err := m.getTableIndexes(ctx, databaseName, relName, &result)
+	if err != nil {
+		return interfaces.TableSchema{}, err
+	}
+	keysFromIndexes(&result, uniqueConstraints)
+
+	if err := m.getTableForeignKeys(ctx, databaseName, relName, &result); err != nil {
+		return interfaces.TableSchema{}, err
+	}
+
+	if err := m.getTableCheckConstraints(ctx, databaseName, relName, &result); err != nil {
+		return interfaces.TableSchema{}, err
+	}
+
+	return result, nil
+}
+
+// getTableIndexes adds the indexes of a table to schema and returns the
+// names of those backing unique constraints. MySQL names the primary key
+// index PRIMARY.
+func (m *mysqlImpl) getTableIndexes(ctx context.Context, databaseName string, relName string, schema *interfaces.TableSchema) (map[string]bool, error) {
+	// COLUMN_NAME is NULL for the expressions of functional key parts
+	query := `
+		SELECT
+			s.INDEX_NAME,
+			s.INDEX_TYPE,
+			s.NON_UNIQUE = 0,
+			COALESCE(s.COLUMN_NAME, '(expression)'),
+			EXISTS (
+				SELECT 1
+				FROM information_schema.TABLE_CONSTRAINTS tc
+				WHERE tc.TABLE_SCHEMA = s.TABLE_SCHEMA
+					AND tc.TABLE_NAME = s.TABLE_NAME
+					AND tc.CONSTRAINT_NAME = s.INDEX_NAME
+					AND tc.CONSTRAINT_TYPE = 'UNIQUE'
+			)
+		FROM information_schema.STATISTICS s
+		WHERE s.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND s.TABLE_NAME = ?
+		ORDER BY s.INDEX_NAME, s.SEQ_IN_INDEX
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, databaseName, relName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting indexes: %w", err)
+	}
+	defer rows.Close()
+
+	uniqueConstraints := make(map[string]bool)
+	for rows.Next() {
+		var (
+			index              interfaces.IndexInfo
+			isUniqueConstraint bool
+			column             string
+		)
+		if err := rows.Scan(&index.Name, &index.Type, &index.Unique, &column, &isUniqueConstraint); err != nil {
+			return nil, fmt.Errorf("error scanning index: %w", err)
+		}
+
+		// Rows are ordered by index, one per column
+		if n := len(schema.Indexes); n == 0 || schema.Indexes[n-1].Name != index.Name {
+			index.Primary = index.Name == "PRIMARY"
+			schema.Indexes = append(schema.Indexes, index)
+		}
+		current := &schema.Indexes[len(schema.Indexes)-1]
+		current.Columns = append(current.Columns, column)
+
+		if isUniqueConstraint {
+			uniqueConstraints[index.Name] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return uniqueConstraints, nil
+}
+
+// getTableForeignKeys adds the foreign keys declared on a table to schema.
+// Referenced tables in other databases are qualified with the database name.
+func (m *mysqlImpl) getTableForeignKeys(ctx context.Context, databaseName string, relName string, schema *interfaces.TableSchema) error {
+	query := `
+		SELECT
+			k.CONSTRAINT_NAME,
+			IF(k.REFERENCED_TABLE_SCHEMA = k.TABLE_SCHEMA,
+				k.REFERENCED_TABLE_NAME,
+				CONCAT(k.REFERENCED_TABLE_SCHEMA, '.', k.REFERENCED_TABLE_NAME)),
+			r.DELETE_RULE,
+			r.UPDATE_RULE,
+			k.COLUMN_NAME,
+			k.REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+			ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA
+			AND r.TABLE_NAME = k.TABLE_NAME
+			AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE k.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND k.TABLE_NAME = ?
+			AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, databaseName, relName)
+	if err != nil {
+		return fmt.Errorf("error getting foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			fk                       interfaces.ForeignKeyInfo
+			column, referencedColumn string
+		)
+		if err := rows.Scan(&fk.Name, &fk.ReferencedTable, &fk.OnDelete, &fk.OnUpdate, &column, &referencedColumn); err != nil {
+			return fmt.Errorf("error scanning foreign key: %w", err)
+		}
+
+		// Rows are ordered by foreign key, one per column
+		if n := len(schema.ForeignKeys); n == 0 || schema.ForeignKeys[n-1].Name != fk.Name {
+			fk.OnDelete, fk.OnUpdate = referentialAction(fk.OnDelete), referentialAction(fk.OnUpdate)
+			schema.ForeignKeys = append(schema.ForeignKeys, fk)
+		}
+		current := &schema.ForeignKeys[len(schema.ForeignKeys)-1]
+		current.Columns = append(current.Columns, column)
+		current.ReferencedColumns = append(current.ReferencedColumns, referencedColumn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	return nil
+}
+
+// getTableCheckConstraints adds the check constraints of a table to schema.
+// Servers older than MySQL 8.0.16 and MariaDB 10.2 have no check
+// constraints and no table listing them, which is not an error.
+func (m *mysqlImpl) getTableCheckConstraints(ctx context.Context, databaseName string, relName string, schema *interfaces.TableSchema) error {
+	query := `
+		SELECT tc.CONSTRAINT_NAME, cc.CHECK_CLAUSE
+		FROM information_schema.TABLE_CONSTRAINTS tc
+		JOIN information_schema.CHECK_CONSTRAINTS cc
+			ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND tc.TABLE_NAME = ?
+			AND tc.CONSTRAINT_TYPE = 'CHECK'
+		ORDER BY tc.CONSTRAINT_NAME
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, databaseName, relName)
+	if err != nil {
+		// ER_UNKNOWN_TABLE: information_schema.CHECK_CONSTRAINTS is missing
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1109 {
+			return nil
+		}
+		return fmt.Errorf("error getting check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		constraint := interfaces.ConstraintInfo{Type: constraintCheck}
+		if err := rows.Scan(&constraint.Name, &constraint.Definition); err != nil {
+			return fmt.Errorf("error scanning check constraint: %w", err)
+		}
+		schema.Constraints = append(schema.Constraints, constraint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating check constraints: %w", err)
+	}
+
+	return nil
+}
+
+// getDBTables returns a list of all base tables in the current database
+func (m *mysqlImpl) getDBTables(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT TABLE_NAME
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE()
+			AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME
+	`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tables: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// GetViews returns a list of all views in the current database
+func (m *mysqlImpl) GetViews(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT TABLE_NAME
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = DATABASE()
+		ORDER BY TABLE_NAME
+	`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting views: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// GetRoutines returns the procedures and functions of the current database
+func (m *mysqlImpl) GetRoutines(ctx context.Context) ([]interfaces.RoutineInfo, error) {
+	query := `
+		SELECT ROUTINE_SCHEMA, ROUTINE_NAME, ROUTINE_TYPE, COALESCE(DTD_IDENTIFIER, '')
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = DATABASE()
+		ORDER BY ROUTINE_NAME, ROUTINE_TYPE
+	`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []interfaces.RoutineInfo
+	for rows.Next() {
+		var routine interfaces.RoutineInfo
+		if err := rows.Scan(&routine.Schema, &routine.Name, &routine.Type, &routine.ReturnType); err != nil {
+			return nil, fmt.Errorf("error scanning routine: %w", err)
+		}
+		routines = append(routines, routine)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating routines: %w", err)
+	}
+
+	return routines, nil
+}
+
+// GetObjectDefinition returns the source and parameters of a view,
+// procedure or function. name may be qualified with a database name;
+// unqualified names are looked up in the current database. MySQL reports
+// the body of a routine, not its full CREATE statement.
+func (m *mysqlImpl) GetObjectDefinition(ctx context.Context, name string) (interfaces.ObjectDefinition, error) {
+	databaseName, objectName := splitTableName(name)
+
+	// A procedure and a function may share a name
+	query := `
+		SELECT TABLE_SCHEMA, TABLE_NAME, 'VIEW', '', COALESCE(VIEW_DEFINITION, '')
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND TABLE_NAME = ?
+		UNION ALL
+		SELECT ROUTINE_SCHEMA, ROUTINE_NAME, ROUTINE_TYPE, COALESCE(DTD_IDENTIFIER, ''), COALESCE(ROUTINE_DEFINITION, '')
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND ROUTINE_NAME = ?
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, databaseName, objectName, databaseName, objectName)
+	if err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error getting definition of %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var matches []interfaces.ObjectDefinition
+	for rows.Next() {
+		var definition interfaces.ObjectDefinition
+		if err := rows.Scan(&definition.Schema, &definition.Name, &definition.Type, &definition.ReturnType, &definition.Definition); err != nil {
+			return interfaces.ObjectDefinition{}, fmt.Errorf("error scanning definition: %w", err)
+		}
+		matches = append(matches, definition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error iterating definitions: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return interfaces.ObjectDefinition{}, fmt.Errorf("view, procedure or function %s not found", name)
+	case 1:
+	default:
+		return interfaces.ObjectDefinition{}, fmt.Errorf("%s is ambiguous: a procedure and a function share the name", name)
+	}
+
+	result := matches[0]
+	if result.Type == objectView {
+		return result, nil
+	}
+
+	// Position 0 describes a function's return value
+	query = `
+		SELECT COALESCE(PARAMETER_NAME, ''), DTD_IDENTIFIER, COALESCE(PARAMETER_MODE, 'IN')
+		FROM information_schema.PARAMETERS
+		WHERE SPECIFIC_SCHEMA = ?
+			AND SPECIFIC_NAME = ?
+			AND ROUTINE_TYPE = ?
+			AND ORDINAL_POSITION > 0
+		ORDER BY ORDINAL_POSITION
+	`
+
+	paramRows, err := m.db.QueryContext(ctx, query, result.Schema, result.Name, result.Type)
+	if err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error getting parameters of %s: %w", name, err)
+	}
+	defer paramRows.Close()
+
+	for paramRows.Next() {
+		var parameter interfaces.ParameterInfo
+		if err := paramRows.Scan(&parameter.Name, &parameter.Type, &parameter.Mode); err != nil {
+			return interfaces.ObjectDefinition{}, fmt.Errorf("error scanning parameter: %w", err)
+		}
+		result.Parameters = append(result.Parameters, parameter)
+	}
+
+	if err := paramRows.Err(); err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error iterating parameters: %w", err)
+	}
+
+	return result, nil
+}
+
+// getDBSchemas returns a list of all databases visible to the user,
+// which MySQL also calls schemas
+func (m *mysqlImpl) getDBSchemas(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT SCHEMA_NAME
+		FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')
+		ORDER BY SCHEMA_NAME
+	`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting databases: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// schemaVersion returns checksums of the columns, view definitions and
+// routines of the current database. UPDATE_TIME is not used since it
+// changes with the data, and CREATE_TIME since instant ALTER TABLE leaves
+// it alone.
+func (m *mysqlImpl) schemaVersion(ctx context.Context) (string, error) {
+	query := `
+		SELECT CONCAT_WS('/',
+			(SELECT CONCAT(COUNT(*), ':', COALESCE(SUM(CRC32(CONCAT_WS(',', TABLE_NAME, COLUMN_NAME, ORDINAL_POSITION, COLUMN_TYPE, IS_NULLABLE))), 0))
+				FROM information_schema.COLUMNS
+				WHERE TABLE_SCHEMA = DATABASE()),
+			(SELECT CONCAT(COUNT(*), ':', COALESCE(SUM(CRC32(CONCAT_WS(',', TABLE_NAME, VIEW_DEFINITION))), 0))
+				FROM information_schema.VIEWS
+				WHERE TABLE_SCHEMA = DATABASE()),
+			(SELECT CONCAT(COUNT(*), ':', COALESCE(MAX(LAST_ALTERED), ''))
+				FROM information_schema.ROUTINES
+				WHERE ROUTINE_SCHEMA = DATABASE())
+		)
+	`
+
+	var version string
+	if err := m.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return "", fmt.Errorf("error getting schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// scriptTable scripts a table with SHOW CREATE TABLE, which covers its
+// columns, keys, indexes and constraints. Unqualified names are looked up
+// in the current database.
+func (m *mysqlImpl) scriptTable(ctx context.Context, tableName string) (string, error) {
+	databaseName, relName := splitTableName(tableName)
+
+	query := `
+		SELECT TABLE_SCHEMA
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND TABLE_NAME = ?
+			AND TABLE_TYPE = 'BASE TABLE'
+	`
+	err := m.db.QueryRowContext(ctx, query, databaseName, relName).Scan(&databaseName)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("table %s not found", tableName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error resolving table %s: %w", tableName, err)
+	}
+
+	quote := func(identifier string) string {
+		return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+	}
+
+	var name, definition string
+	if err := m.db.QueryRowContext(ctx, "SHOW CREATE TABLE "+quote(databaseName)+"."+quote(relName)).Scan(&name, &definition); err != nil {
+		return "", fmt.Errorf("error scripting table %s: %w", tableName, err)
+	}
+
+	return definition + ";\n", nil
+}
+
+// killMySQLQueryOnCancel makes cancelling ctx kill the statement running on
+// source, a pinned connection or transaction. The driver only closes its
+// end of the connection on cancellation, which leaves the statement running
+// on the server. The returned function stops watching ctx.
+func killMySQLQueryOnCancel(ctx context.Context, db *sql.DB, source queryer) (func(), error) {
+	var connectionID int64
+	if err := source.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connectionID); err != nil {
+		return nil, fmt.Errorf("error getting connection id: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if _, err := db.ExecContext(killCtx, fmt.Sprintf("KILL QUERY %d", connectionID)); err != nil {
+				log.Printf("Failed to kill MySQL query on connection %d: %v", connectionID, err)
+			}
+		case <-done:
+		}
+	}()
+
+	return func() { close(done) }, nil
+}
+
+// convertMySQLValue is the valueConverter for MySQL results. The text
+// protocol returns every value as bytes, so numbers are parsed according
+// to the column type; unsigned integers are kept as uint64 so values above
+// the signed range survive, binary data is rendered as hex, and ENUM, SET,
+// JSON and DECIMAL values are returned verbatim as strings.
+func convertMySQLValue(columnType *sql.ColumnType, value any) any {
+	b, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+
+	text := string(b)
+	switch typeName := columnType.DatabaseTypeName(); typeName {
+	case "UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT", "UNSIGNED BIGINT":
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return n
+		}
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case "FLOAT", "DOUBLE":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case "BINARY", "VARBINARY", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+		return "0x" + strings.ToUpper(hex.EncodeToString(b))
+	}
+
+	return text
+}
+
+// mysqlConfigFromEnv reads the MySQL connection configured through the
+// MYSQL_* variables
+func mysqlConfigFromEnv() connectionConfig {
+	return connectionConfig{
+		Name:     defaultConnectionName,
+		Engine:   engineMySQL,
+		Host:     os.Getenv("MYSQL_HOST"),
+		Port:     os.Getenv("MYSQL_PORT"),
+		User:     os.Getenv("MYSQL_USER"),
+		Password: os.Getenv("MYSQL_PASSWORD"),
+		Database: os.Getenv("MYSQL_DATABASE"),
+		SSLMode:  os.Getenv("MYSQL_TLS"),
+		ReadOnly: readOnlyFromEnv("MYSQL_READ_ONLY"),
+
+		MaxAffectedRows: intFromEnv("MYSQL_MAX_AFFECTED_ROWS", defaultMaxAffectedRows),
+		MaxRows:         intFromEnv("MYSQL_MAX_ROWS", defaultMaxRows),
+		MaxResultBytes:  intFromEnv("MYSQL_MAX_RESULT_BYTES", defaultMaxResultBytes),
+
+		StatementTimeout: secondsFromEnv("MYSQL_STATEMENT_TIMEOUT", defaultStatementTimeout),
+
+		Masking: maskingConfigFromEnv("MYSQL_"),
+	}
+}
+
+// createMySQLConnection establishes a connection to the MySQL server
+func createMySQLConnection(config connectionConfig) (*sql.DB, error) {
+	port := config.Port
+	if port == "" {
+		port = "3306"
+	}
+
+	driverConfig := mysql.NewConfig()
+	driverConfig.Net = "tcp"
+	driverConfig.Addr = fmt.Sprintf("%s:%s", config.Host, port)
+	driverConfig.User = config.User
+	driverConfig.Passwd = config.Password
+	driverConfig.DBName = config.Database
+	driverConfig.ParseTime = true
+	driverConfig.Loc = time.UTC
+	if config.SSLMode != "" {
+		driverConfig.TLSConfig = config.SSLMode
+	}
+
+	log.Printf("Connecting to MySQL %q at %s as %s (database %s)", config.Name, driverConfig.Addr, driverConfig.User, driverConfig.DBName)
+
+	connector, err := mysql.NewConnector(driverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to MySQL: %w", err)
+	}
+	db := sql.OpenDB(connector)
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to MySQL: %w", err)
+	}
+
+	return db, nil
+}
+
+// NewMySQLTool creates a new instance of the MySQL/MariaDB tool
+func NewMySQLTool(server *server.MCPServer) interfaces.Database {
+	// Create a new MySQL implementation
+	config := mysqlConfigFromEnv()
+	mysqlTool := &mysqlImpl{config: config}
+
+	// Connect to the database
+	if err := mysqlTool.Connect(); err != nil {
+		// Log the error but continue without the tool
+		log.Printf("Failed to initialize MySQL tool: %v", err)
+		return nil
+	}
+
+	log.Println("MySQL tool initialized successfully")
+
+	// Add the MySQL tools to the MCP server
+	if server != nil {
+		// Register the query and table tools shared with the other backends
+		connections := newConnectionRegistry(false)
+		connections.add(config, mysqlTool)
+		registerDatabaseTools(server, "mysql", "MySQL", connections)
+
+		// Register tool for getting databases
+		getDatabasesTool := mcp.NewTool("mysql_get_databases",
+			mcp.WithDescription("Get a list of all databases (schemas) on the MySQL server"),
+		)
+
+		server.AddTool(getDatabasesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			queryCtx, cancel, err := statementContext(ctx, config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			databases, err := mysqlTool.getDBSchemas(queryCtx)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			return mcp.NewToolResultText(formatNameList("databases", databases)), nil
+		})
+	}
+
+	return mysqlTool
+}
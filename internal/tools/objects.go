@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+)
+
+// Types of the objects reported by GetRoutines and GetObjectDefinition
+const (
+	objectView      = "VIEW"
+	objectProcedure = "PROCEDURE"
+	objectFunction  = "FUNCTION"
+)
+
+// formatRoutines renders a numbered list of the routines of one type for
+// the get_procedures and get_functions tools
+func formatRoutines(kind string, routines []interfaces.RoutineInfo, routineType string) string {
+	var matching []interfaces.RoutineInfo
+	for _, routine := range routines {
+		if routine.Type == routineType {
+			matching = append(matching, routine)
+		}
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Found %d %s:\n\n", len(matching), kind))
+
+	for i, routine := range matching {
+		resultText.WriteString(fmt.Sprintf("%d. %s", i+1, qualifyTableName(routine.Schema, routine.Name)))
+		if routine.ReturnType != "" {
+			resultText.WriteString(fmt.Sprintf(" (returns %s)", routine.ReturnType))
+		}
+		resultText.WriteString("\n")
+	}
+
+	return resultText.String()
+}
+
+// formatObjectDefinition renders a view, procedure or function for the
+// get_object_definition tools
+func formatObjectDefinition(definition interfaces.ObjectDefinition) string {
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("%s %s", definition.Type, qualifyTableName(definition.Schema, definition.Name)))
+	if definition.ReturnType != "" {
+		resultText.WriteString(fmt.Sprintf(" returns %s", definition.ReturnType))
+	}
+	resultText.WriteString("\n")
+
+	if definition.Type != objectView {
+		if len(definition.Parameters) == 0 {
+			resultText.WriteString("\nParameters: none\n")
+		} else {
+			resultText.WriteString("\nParameters:\n\n")
+			resultText.WriteString("POSITION\tNAME\tDATA_TYPE\tMODE\n")
+			resultText.WriteString("----------\t----------\t----------\t----------\n")
+			for i, parameter := range definition.Parameters {
+				resultText.WriteString(fmt.Sprintf("%d\t%s\t%s\t%s\n", i+1, parameter.Name, parameter.Type, parameter.Mode))
+			}
+		}
+	}
+
+	if definition.Definition == "" {
+		resultText.WriteString("\nThe definition is not available (the object may be encrypted or implemented outside SQL).\n")
+	} else {
+		resultText.WriteString("\nDefinition:\n\n")
+		resultText.WriteString(definition.Definition)
+		resultText.WriteString("\n")
+	}
+
+	return resultText.String()
+}
@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Defaults for the size of query results returned to the client
+const (
+	// defaultMaxRows is the number of rows returned per page when the
+	// connection does not configure a limit
+	defaultMaxRows = 500
+
+	// defaultMaxResultBytes is the size of formatted results after which
+	// the remaining rows of a page are left for the next page
+	defaultMaxResultBytes = 64 * 1024
+
+	// maxRowsCeiling bounds the max_rows argument of a tool call
+	maxRowsCeiling = 10000
+)
+
+// queryResult is one page of rows read from a result set of a query
+type queryResult struct {
+	// index is the position of the result set among those the query
+	// returned, starting at 0
+	index int
+
+	// columns lists the column names in the order the query returned them,
+	// and columnTypes the driver's description of each
+	columns     []string
+	columnTypes []*sql.ColumnType
+
+	// rows holds the values of each row in column order
+	rows [][]any
+
+	// more is set when rows beyond the page were left unread
+	more bool
+}
+
+// pageToken locates the next page of a query. It is handed to clients
+// base64 encoded and treated by them as opaque.
+type pageToken struct {
+	// Connection and QueryHash tie the token to the query it was issued for
+	Connection string `json:"c"`
+	QueryHash  string `json:"q"`
+
+	// Offset is the number of rows to skip
+	Offset int `json:"o"`
+
+	// MaxRows is the page size of the original call
+	MaxRows int `json:"n"`
+}
+
+// hashQuery returns a short fingerprint of a query for page tokens
+func hashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodePageToken renders the token for the page at offset
+func encodePageToken(connection string, query string, offset int, maxRows int) string {
+	data, _ := json.Marshal(pageToken{
+		Connection: connection,
+		QueryHash:  hashQuery(query),
+		Offset:     offset,
+		MaxRows:    maxRows,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageToken parses a token and checks that it was issued for the
+// same query on the same connection
+func decodePageToken(token string, connection string, query string) (pageToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageToken{}, fmt.Errorf("invalid page_token")
+	}
+
+	var result pageToken
+	if err := json.Unmarshal(data, &result); err != nil || result.Offset < 0 {
+		return pageToken{}, fmt.Errorf("invalid page_token")
+	}
+
+	if result.Connection != connection || result.QueryHash != hashQuery(query) {
+		return pageToken{}, fmt.Errorf("page_token was issued for a different query or connection")
+	}
+
+	return result, nil
+}
+
+// maps returns the rows as column-name keyed maps
+func (r queryResult) maps() []map[string]any {
+	if r.rows == nil {
+		return nil
+	}
+
+	results := make([]map[string]any, 0, len(r.rows))
+	for _, values := range r.rows {
+		row := make(map[string]any, len(values))
+		for i, value := range values {
+			row[r.columns[i]] = value
+		}
+		results = append(results, row)
+	}
+	return results
+}
+
+// scanRowsPage skips offset rows and reads at most limit of the remaining
+// rows, or all of them when limit is 0. Rows past the page are not read.
+func scanRowsPage(rows *sql.Rows, convert valueConverter, offset int, limit int) (queryResult, error) {
+	result, _, err := scanResultSet(rows, convert, offset, limit)
+	if err != nil {
+		return queryResult{}, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return queryResult{}, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// scanResultSets reads one page of every result set of rows, in order. The
+// sets are treated as one sequence of rows: offset rows are skipped and at
+// most limit of the remaining rows are read, or all of them when limit is
+// 0. Sets whose rows all precede the page are left out, as are sets without
+// columns unless the query returned no other set.
+func scanResultSets(rows *sql.Rows, convert valueConverter, offset int, limit int) ([]queryResult, error) {
+	var (
+		results []queryResult
+		first   queryResult
+		shown   int
+	)
+
+	for index := 0; ; index++ {
+		remaining := 0
+		if limit > 0 {
+			remaining = limit - shown
+			if remaining == 0 {
+				// The page is full; more rows follow if a later set has any
+				for {
+					if rows.Next() {
+						results[len(results)-1].more = true
+						break
+					}
+					if !rows.NextResultSet() {
+						break
+					}
+				}
+				break
+			}
+		}
+
+		startOffset := offset
+		result, skipped, err := scanResultSet(rows, convert, offset, remaining)
+		if err != nil {
+			return nil, err
+		}
+		result.index = index
+		offset -= skipped
+
+		if index == 0 {
+			first = result
+		}
+		if len(result.columns) > 0 && (startOffset == 0 || len(result.rows) > 0) {
+			results = append(results, result)
+			shown += len(result.rows)
+		}
+
+		if result.more || !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if len(results) == 0 {
+		results = append(results, first)
+	}
+
+	return results, nil
+}
+
+// scanResultSet reads one page of the current result set of rows like
+// scanRowsPage, and also returns the number of rows skipped
+func scanResultSet(rows *sql.Rows, convert valueConverter, offset int, limit int) (queryResult, int, error) {
+	// Get column names and types
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return queryResult{}, 0, fmt.Errorf("error getting column types: %w", err)
+	}
+
+	result := queryResult{
+		columns:     make([]string, len(columnTypes)),
+		columnTypes: columnTypes,
+	}
+	for i, columnType := range columnTypes {
+		result.columns[i] = columnType.Name()
+	}
+
+	// Prepare values for scan
+	values := make([]interface{}, len(columnTypes))
+	valuePtrs := make([]interface{}, len(columnTypes))
+	for i := range columnTypes {
+		valuePtrs[i] = &values[i]
+	}
+
+	// Iterate through rows
+	skipped := 0
+	for rows.Next() {
+		if skipped < offset {
+			skipped++
+			continue
+		}
+
+		if limit > 0 && len(result.rows) == limit {
+			result.more = true
+			break
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return queryResult{}, 0, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		row := make([]any, len(columnTypes))
+		for i, columnType := range columnTypes {
+			row[i] = convert(columnType, values[i])
+		}
+
+		result.rows = append(result.rows, row)
+	}
+
+	return result, skipped, nil
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	token := encodePageToken("main", "SELECT 1", 500, 250)
+
+	page, err := decodePageToken(token, "main", "SELECT 1")
+	if err != nil {
+		t.Fatalf("decodePageToken failed: %v", err)
+	}
+	if page.Offset != 500 || page.MaxRows != 250 {
+		t.Errorf("decodePageToken = offset %d, max rows %d, want 500 and 250", page.Offset, page.MaxRows)
+	}
+}
+
+func TestDecodePageTokenErrors(t *testing.T) {
+	token := encodePageToken("main", "SELECT 1", 500, 250)
+	encode := func(data string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(data))
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		connection string
+		query      string
+	}{
+		{"other query", token, "main", "SELECT 2"},
+		{"other connection", token, "reporting", "SELECT 1"},
+		{"not base64", "not a token!", "main", "SELECT 1"},
+		{"not JSON", encode("{"), "main", "SELECT 1"},
+		{"negative offset", encode(`{"c":"main","q":"` + hashQuery("SELECT 1") + `","o":-1,"n":10}`), "main", "SELECT 1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := decodePageToken(test.token, test.connection, test.query); err == nil {
+				t.Errorf("decodePageToken succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/golang-sql/civil"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Type hints of query parameters, given as {"type": ..., "value": ...}
+const (
+	parameterTypeInt              = "int"
+	parameterTypeDecimal          = "decimal"
+	parameterTypeDatetime         = "datetime"
+	parameterTypeUniqueIdentifier = "uniqueidentifier"
+	parameterTypeVarbinary        = "varbinary"
+)
+
+// decimalPattern matches the decimal numbers accepted for decimal parameters
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// uniqueIdentifierPattern matches a GUID with or without hyphens and braces
+var uniqueIdentifierPattern = regexp.MustCompile(`^\{?[0-9A-Fa-f]{8}-?[0-9A-Fa-f]{4}-?[0-9A-Fa-f]{4}-?[0-9A-Fa-f]{4}-?[0-9A-Fa-f]{12}\}?$`)
+
+// datetimeLayouts are the forms accepted for datetime parameters, the
+// ones with an offset first
+var datetimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// supportsNamedParameters reports whether the engine's driver binds
+// parameters by name
+func supportsNamedParameters(engine string) bool {
+	return engine == engineSQLServer || engine == engineSQLite
+}
+
+// arrayOrObject widens a property added with mcp.WithArray to also accept
+// a JSON object
+func arrayOrObject() mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["type"] = []string{"array", "object"}
+	}
+}
+
+// queryParametersFromRequest reads the parameters argument of a query tool
+// call. A JSON array is bound by position (@p1, $1 or ?) and an object by
+// name (@name, or :name on SQLite); either may also be given as a string
+// holding the JSON.
+func queryParametersFromRequest(request mcp.CallToolRequest, engine string) ([]any, error) {
+	value := request.Params.Arguments["parameters"]
+	if text, ok := value.(string); ok {
+		if strings.TrimSpace(text) == "" {
+			return nil, nil
+		}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("parameters must be a JSON array or object: %w", err)
+		}
+	}
+
+	switch parameters := value.(type) {
+	case nil:
+		return nil, nil
+
+	case []any:
+		args := make([]any, len(parameters))
+		for i, parameter := range parameters {
+			arg, err := queryParameterValue(engine, parameter)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %d: %w", i+1, err)
+			}
+			args[i] = arg
+		}
+		return args, nil
+
+	case map[string]any:
+		if !supportsNamedParameters(engine) {
+			return nil, fmt.Errorf("%s connections do not support named parameters; pass parameters as an array", engine)
+		}
+
+		// Bind in a stable order so that repeated calls match
+		names := make([]string, 0, len(parameters))
+		for name := range parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		args := make([]any, 0, len(parameters))
+		for _, name := range names {
+			arg, err := queryParameterValue(engine, parameters[name])
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", name, err)
+			}
+			args = append(args, sql.Named(strings.TrimLeft(strings.TrimSpace(name), "@:$"), arg))
+		}
+		return args, nil
+	}
+
+	return nil, fmt.Errorf("parameters must be a JSON array or object")
+}
+
+// queryParameterValue converts a JSON parameter value into the value bound
+// on the engine's driver. Whole numbers are bound as integers. An object
+// such as {"type": "datetime", "value": "2024-05-01T13:45:00"} binds the
+// value with the named type.
+func queryParameterValue(engine string, value any) (any, error) {
+	switch v := value.(type) {
+	case nil, string, bool:
+		return v, nil
+	case float64:
+		// JSON numbers arrive as floats
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+		return v, nil
+	case map[string]any:
+		typeName, _ := v["type"].(string)
+		return typedParameterValue(engine, strings.ToLower(strings.TrimSpace(typeName)), v["value"])
+	}
+
+	return nil, fmt.Errorf(`must be a string, number, boolean, null or an object like {"type": "int", "value": 1}`)
+}
+
+// typedParameterValue converts value for a parameter with a type hint
+func typedParameterValue(engine string, typeName string, value any) (any, error) {
+	switch typeName {
+	case parameterTypeInt, parameterTypeDecimal, parameterTypeDatetime, parameterTypeUniqueIdentifier, parameterTypeVarbinary:
+	default:
+		return nil, fmt.Errorf("type must be one of int, decimal, datetime, uniqueidentifier or varbinary")
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	// Numbers are accepted for the numeric types, text for all types
+	text, isText := value.(string)
+	number, isNumber := value.(float64)
+	if !isText && !(isNumber && (typeName == parameterTypeInt || typeName == parameterTypeDecimal)) {
+		return nil, fmt.Errorf("%s value must be a string", typeName)
+	}
+	text = strings.TrimSpace(text)
+
+	switch typeName {
+	case parameterTypeInt:
+		if isNumber {
+			if number != math.Trunc(number) {
+				return nil, fmt.Errorf("int value %v is not a whole number", number)
+			}
+			return int64(number), nil
+		}
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int value %q", text)
+		}
+		return n, nil
+
+	case parameterTypeDecimal:
+		// Bound as text so that no digits are lost to floating point
+		if isNumber {
+			return strconv.FormatFloat(number, 'f', -1, 64), nil
+		}
+		if !decimalPattern.MatchString(text) {
+			return nil, fmt.Errorf("invalid decimal value %q", text)
+		}
+		return text, nil
+
+	case parameterTypeDatetime:
+		for i, layout := range datetimeLayouts {
+			t, err := time.Parse(layout, text)
+			if err != nil {
+				continue
+			}
+			// Without an offset SQL Server gets a datetime2 rather than a
+			// datetimeoffset, which would not compare with datetime columns
+			// without converting them
+			if engine == engineSQLServer && i > 0 {
+				return civil.DateTimeOf(t), nil
+			}
+			return t, nil
+		}
+		return nil, fmt.Errorf("invalid datetime value %q, expected ISO 8601 such as 2024-05-01T13:45:00", text)
+
+	case parameterTypeUniqueIdentifier:
+		if !uniqueIdentifierPattern.MatchString(text) {
+			return nil, fmt.Errorf("invalid uniqueidentifier value %q", text)
+		}
+		if engine == engineSQLServer {
+			// The driver sends the bytes in the order the server stores them
+			var guid mssql.UniqueIdentifier
+			b, _ := hex.DecodeString(strings.NewReplacer("-", "", "{", "", "}", "").Replace(text))
+			copy(guid[:], b)
+			return guid, nil
+		}
+		return text, nil
+	}
+
+	// varbinary
+	return decodeBinary(text)
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/golang-sql/civil"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// parametersRequest builds a tool call with a parameters argument
+func parametersRequest(parameters any) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"parameters": parameters}
+	return request
+}
+
+func TestQueryParametersFromRequest(t *testing.T) {
+	args, err := queryParametersFromRequest(parametersRequest([]any{float64(42), 1.5, "text", nil, true}), enginePostgres)
+	if err != nil {
+		t.Fatalf("binding an array failed: %v", err)
+	}
+	want := []any{int64(42), 1.5, "text", nil, true}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("parameter %d = %#v, want %#v", i+1, args[i], want[i])
+		}
+	}
+
+	args, err = queryParametersFromRequest(parametersRequest(`{"@name": "Ann", ":id": 7}`), engineSQLite)
+	if err != nil {
+		t.Fatalf("binding an object failed: %v", err)
+	}
+	if len(args) != 2 || args[0] != sql.Named("id", int64(7)) || args[1] != sql.Named("name", "Ann") {
+		t.Errorf("object bound as %#v, want id and name in order", args)
+	}
+
+	if args, err := queryParametersFromRequest(parametersRequest(" "), engineSQLite); args != nil || err != nil {
+		t.Errorf("blank parameters bound as %#v, %v", args, err)
+	}
+
+	for _, test := range []struct {
+		name       string
+		parameters any
+		engine     string
+	}{
+		{"object on MySQL", map[string]any{"id": 1}, engineMySQL},
+		{"invalid JSON", "[1,", engineSQLite},
+		{"scalar", float64(1), engineSQLite},
+		{"nested array", []any{[]any{1}}, engineSQLite},
+	} {
+		if _, err := queryParametersFromRequest(parametersRequest(test.parameters), test.engine); err == nil {
+			t.Errorf("%s: binding succeeded, want an error", test.name)
+		}
+	}
+}
+
+func TestTypedParameterValue(t *testing.T) {
+	guid := "6F9619FF-8B86-D011-B42D-00C04FC964FF"
+	var mssqlGUID mssql.UniqueIdentifier
+	copy(mssqlGUID[:], []byte{0x6F, 0x96, 0x19, 0xFF, 0x8B, 0x86, 0xD0, 0x11, 0xB4, 0x2D, 0x00, 0xC0, 0x4F, 0xC9, 0x64, 0xFF})
+	date := time.Date(2024, 5, 1, 13, 45, 0, 0, time.UTC)
+
+	tests := []struct {
+		engine   string
+		typeName string
+		value    any
+		want     any
+	}{
+		{enginePostgres, parameterTypeInt, "42", int64(42)},
+		{enginePostgres, parameterTypeInt, float64(42), int64(42)},
+		{enginePostgres, parameterTypeDecimal, "-12.50", "-12.50"},
+		{enginePostgres, parameterTypeDecimal, 0.1, "0.1"},
+		{enginePostgres, parameterTypeDatetime, "2024-05-01T13:45:00Z", date},
+		{enginePostgres, parameterTypeDatetime, "2024-05-01T13:45:00", date},
+		{engineSQLServer, parameterTypeDatetime, "2024-05-01 13:45:00", civil.DateTimeOf(date)},
+		{engineSQLServer, parameterTypeUniqueIdentifier, "{" + guid + "}", mssqlGUID},
+		{enginePostgres, parameterTypeUniqueIdentifier, guid, guid},
+		{engineSQLite, parameterTypeInt, nil, nil},
+	}
+
+	for _, test := range tests {
+		got, err := typedParameterValue(test.engine, test.typeName, test.value)
+		if err != nil {
+			t.Errorf("%s %s %v failed: %v", test.engine, test.typeName, test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s %s %v = %#v, want %#v", test.engine, test.typeName, test.value, got, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		typeName string
+		value    any
+	}{
+		{"money", "1"},
+		{parameterTypeInt, 1.5},
+		{parameterTypeInt, "ten"},
+		{parameterTypeDecimal, "1e5"},
+		{parameterTypeDatetime, "yesterday"},
+		{parameterTypeDatetime, float64(1)},
+		{parameterTypeUniqueIdentifier, "not-a-guid"},
+		{parameterTypeVarbinary, "not base64!"},
+	} {
+		if _, err := typedParameterValue(engineSQLServer, test.typeName, test.value); err == nil {
+			t.Errorf("%s %v succeeded, want an error", test.typeName, test.value)
+		}
+	}
+}
@@ -0,0 +1,1028 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+
+	"github.com/lib/pq"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// postgresImpl implements the interfaces.Database interface for PostgreSQL
+type postgresImpl struct {
+	config connectionConfig
+	db     *sql.DB
+}
+
+// Connect establishes a connection to the database
+func (p *postgresImpl) Connect() error {
+	var err error
+	p.db, err = createPostgresConnection(p.config)
+	return err
+}
+
+// Disconnect closes the connection to the database
+func (p *postgresImpl) Disconnect() error {
+	if p.db != nil {
+		return p.db.Close()
+	}
+	return nil
+}
+
+// Query executes a query and returns the rows of its first result set
+func (p *postgresImpl) Query(ctx context.Context, query string, params ...any) ([]map[string]any, error) {
+	return queryDatabase(ctx, p.db, p.config, convertBytesToString, query, params...)
+}
+
+// QueryResultSets executes a query and returns the rows of every result set
+func (p *postgresImpl) QueryResultSets(ctx context.Context, query string, params ...any) ([][]map[string]any, error) {
+	return queryDatabaseResultSets(ctx, p.db, p.config, convertBytesToString, query, params...)
+}
+
+// Execute runs a query that doesn't return results (INSERT, UPDATE, DELETE)
+func (p *postgresImpl) Execute(ctx context.Context, query string, params ...any) error {
+	if err := checkWritable(p.config); err != nil {
+		return err
+	}
+
+	if _, err := p.db.ExecContext(ctx, query, params...); err != nil {
+		return fmt.Errorf("error executing statement: %w", err)
+	}
+
+	return nil
+}
+
+// queryPage executes a query and returns at most limit rows of its result
+// sets after skipping offset rows
+func (p *postgresImpl) queryPage(ctx context.Context, query string, offset int, limit int, params ...any) ([]queryResult, error) {
+	return queryDatabasePage(ctx, p.db, p.config, convertBytesToString, query, offset, limit, params...)
+}
+
+// executeStatement runs a write statement in a transaction, committing it
+// only when commit is set, and returns the number of rows affected
+func (p *postgresImpl) executeStatement(ctx context.Context, statement string, commit bool) (int64, error) {
+	return executeInTransaction(ctx, p.db, p.config, statement, commit)
+}
+
+// GetSchema returns database schema information
+func (p *postgresImpl) GetSchema(ctx context.Context) (interfaces.SchemaInfo, error) {
+	var databaseName string
+	if err := p.db.QueryRowContext(ctx, "SELECT current_database()").Scan(&databaseName); err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error getting database name: %w", err)
+	}
+
+	tables, err := p.getDBTables(ctx)
+	if err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error getting tables: %w", err)
+	}
+
+	result := interfaces.SchemaInfo{
+		DatabaseName: databaseName,
+		Tables:       make([]interfaces.TableSchema, 0, len(tables)),
+	}
+
+	// Collect schema information for each table
+	for _, tableName := range tables {
+		tableSchema, err := p.GetTableSchema(ctx, tableName)
+		if err != nil {
+			return interfaces.SchemaInfo{}, fmt.Errorf("error getting schema for table %s: %w", tableName, err)
+		}
+		result.Tables = append(result.Tables, tableSchema)
+	}
+
+	return result, nil
+}
+
+// GetTables returns all table names qualified with their schema
+func (p *postgresImpl) GetTables(ctx context.Context) ([]string, error) {
+	return p.getDBTables(ctx)
+}
+
+// GetTableSchema returns column information for a specific table.
+// tableName may be schema-qualified ("sales.orders"); unqualified names
+// are resolved through the connection's search_path.
+func (p *postgresImpl) GetTableSchema(ctx context.Context, tableName string) (interfaces.TableSchema, error) {
+	schemaName, relName := splitTableName(tableName)
+
+	// Resolve unqualified names the same way the server would
+	if schemaName == "" {
+		query := `
+			SELECT n.nspname
+			FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relname = $1
+				AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+				AND pg_catalog.pg_table_is_visible(c.oid)
+		`
+		err := p.db.QueryRowContext(ctx, query, relName).Scan(&schemaName)
+		if err == sql.ErrNoRows {
+			return interfaces.TableSchema{}, fmt.Errorf("table %s not found", tableName)
+		}
+		if err != nil {
+			return interfaces.TableSchema{}, fmt.Errorf("error resolving table %s: %w", tableName, err)
+		}
+	}
+
+	query := `
+		SELECT
+			a.attname AS column_name,
+			pg_catalog.format_type(a.atttypid, a.atttypmod) AS data_type,
+			NOT a.attnotnull AS is_nullable,
+			pg_catalog.pg_get_expr(d.adbin, d.adrelid) AS column_default,
+			COALESCE(i.indisprimary, false) AS is_primary_key
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		LEFT JOIN pg_catalog.pg_index i ON i.indrelid = c.oid AND i.indisprimary AND a.attnum = ANY(i.indkey)
+		WHERE n.nspname = $1
+			AND c.relname = $2
+			AND a.attnum > 0
+			AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, schemaName, relName)
+	if err != nil {
+		return interfaces.TableSchema{}, fmt.Errorf("error getting table schema: %w", err)
+	}
+	defer rows.Close()
+
+	result := interfaces.TableSchema{
+		Schema:    schemaName,
+		TableName: relName,
+	}
+
+	for rows.Next() {
+		var (
+			columnInfo   interfaces.ColumnInfo
+			defaultValue sql.NullString
+		)
+		if err := rows.Scan(&columnInfo.Name, &columnInfo.Type, &columnInfo.Nullable, &defaultValue, &columnInfo.IsPrimaryKey); err != nil {
+			return interfaces.TableSchema{}, fmt.Errorf("error scanning column: %w", err)
+		}
+
+		// Handle default value if present
+		if defaultValue.Valid {
+			columnInfo.DefaultValue = defaultValue.String
+		}
+
+		result.Columns = append(result.Columns, columnInfo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return interfaces.TableSchema{}, fmt.Errorf("error iterating columns: %w", err)
+	}
+
+	if len(result.Columns) == 0 {
+		return interfaces.TableSchema{}, fmt.Errorf("table %s not found", tableName)
+	}
+
+	if err := p.getTableConstraints(ctx, schemaName, relName, &result); err != nil {
+		return interfaces.TableSchema{}, err
+	}
+
+	if err := p.getTableIndexes(ctx, schemaName, relName, &result); err != nil {
+		return interfaces.TableSchema{}, err
+	}
+
+	return result, nil
+}
+
+// postgresReferentialActions maps the action codes of pg_constraint
+var postgresReferentialActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// getTableConstraints adds the primary key, foreign keys and unique and
+// check constraints of a table to schema
+func (p *postgresImpl) getTableConstraints(ctx context.Context, schemaName string, relName string, schema *interfaces.TableSchema) error {
+	query := `
+		SELECT
+			con.conname,
+			con.contype,
+			pg_catalog.pg_get_constraintdef(con.oid, true),
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			) AS columns,
+			COALESCE(fn.nspname || '.' || fc.relname, '') AS referenced_table,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			) AS referenced_columns,
+			con.confdeltype,
+			con.confupdtype
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_class fc ON fc.oid = con.confrelid
+		LEFT JOIN pg_catalog.pg_namespace fn ON fn.oid = fc.relnamespace
+		WHERE n.nspname = $1
+			AND c.relname = $2
+			AND con.contype IN ('p', 'f', 'u', 'c')
+		ORDER BY con.contype, con.conname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, schemaName, relName)
+	if err != nil {
+		return fmt.Errorf("error getting constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name, constraintType, definition, referencedTable string
+			onDelete, onUpdate                                string
+			columns, referencedColumns                        pq.StringArray
+		)
+		if err := rows.Scan(&name, &constraintType, &definition, &columns, &referencedTable, &referencedColumns, &onDelete, &onUpdate); err != nil {
+			return fmt.Errorf("error scanning constraint: %w", err)
+		}
+
+		switch constraintType {
+		case "p":
+			schema.PrimaryKey = &interfaces.KeyInfo{Name: name, Columns: columns}
+		case "f":
+			schema.ForeignKeys = append(schema.ForeignKeys, interfaces.ForeignKeyInfo{
+				Name:              name,
+				Columns:           columns,
+				ReferencedTable:   referencedTable,
+				ReferencedColumns: referencedColumns,
+				OnDelete:          postgresReferentialActions[onDelete],
+				OnUpdate:          postgresReferentialActions[onUpdate],
+			})
+		case "u":
+			schema.Constraints = append(schema.Constraints, interfaces.ConstraintInfo{
+				Name:    name,
+				Type:    constraintUnique,
+				Columns: columns,
+			})
+		case "c":
+			schema.Constraints = append(schema.Constraints, interfaces.ConstraintInfo{
+				Name:       name,
+				Type:       constraintCheck,
+				Columns:    columns,
+				Definition: definition,
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating constraints: %w", err)
+	}
+
+	return nil
+}
+
+// getTableIndexes adds the indexes of a table to schema. Columns holds the
+// key columns or expressions and IncludedColumns the INCLUDE columns.
+func (p *postgresImpl) getTableIndexes(ctx context.Context, schemaName string, relName string, schema *interfaces.TableSchema) error {
+	query := `
+		SELECT
+			ic.relname,
+			am.amname,
+			ix.indisunique,
+			ix.indisprimary,
+			COALESCE(pg_catalog.pg_get_expr(ix.indpred, ix.indrelid, true), ''),
+			ARRAY(
+				SELECT pg_catalog.pg_get_indexdef(ix.indexrelid, k, true)
+				FROM generate_series(1, ix.indnkeyatts) AS k
+			) AS columns,
+			ARRAY(
+				SELECT pg_catalog.pg_get_indexdef(ix.indexrelid, k, true)
+				FROM generate_series(ix.indnkeyatts + 1, ix.indnatts) AS k
+			) AS included_columns
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class ic ON ic.oid = ix.indexrelid
+		JOIN pg_catalog.pg_class c ON c.oid = ix.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_catalog.pg_am am ON am.oid = ic.relam
+		WHERE n.nspname = $1
+			AND c.relname = $2
+		ORDER BY ic.relname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, schemaName, relName)
+	if err != nil {
+		return fmt.Errorf("error getting indexes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			index             interfaces.IndexInfo
+			columns, included pq.StringArray
+		)
+		if err := rows.Scan(&index.Name, &index.Type, &index.Unique, &index.Primary, &index.Filter, &columns, &included); err != nil {
+			return fmt.Errorf("error scanning index: %w", err)
+		}
+
+		index.Columns = columns
+		if len(included) > 0 {
+			index.IncludedColumns = included
+		}
+		schema.Indexes = append(schema.Indexes, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	return nil
+}
+
+// getDBTables returns the schema-qualified names of all ordinary and
+// partitioned tables outside the system schemas
+func (p *postgresImpl) getDBTables(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT n.nspname, c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p')
+			AND NOT c.relispartition
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND n.nspname NOT LIKE 'pg_toast%'
+			AND n.nspname NOT LIKE 'pg_temp%'
+		ORDER BY n.nspname, c.relname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var schemaName, relName string
+		if err := rows.Scan(&schemaName, &relName); err != nil {
+			return nil, fmt.Errorf("error scanning table: %w", err)
+		}
+		tables = append(tables, qualifyTableName(schemaName, relName))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// GetViews returns the schema-qualified names of all views and
+// materialized views outside the system schemas
+func (p *postgresImpl) GetViews(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT n.nspname, c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('v', 'm')
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY n.nspname, c.relname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []string
+	for rows.Next() {
+		var schemaName, viewName string
+		if err := rows.Scan(&schemaName, &viewName); err != nil {
+			return nil, fmt.Errorf("error scanning view: %w", err)
+		}
+		views = append(views, qualifyTableName(schemaName, viewName))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating views: %w", err)
+	}
+
+	return views, nil
+}
+
+// GetRoutines returns the procedures and functions outside the system
+// schemas. Overloaded functions are listed once per signature.
+func (p *postgresImpl) GetRoutines(ctx context.Context) ([]interfaces.RoutineInfo, error) {
+	// prokind is 'f' for functions and 'p' for procedures; aggregates and
+	// window functions are left out
+	query := `
+		SELECT
+			n.nspname,
+			p.proname,
+			p.prokind,
+			CASE WHEN p.prokind = 'f' THEN pg_catalog.pg_get_function_result(p.oid) ELSE '' END
+		FROM pg_catalog.pg_proc p
+		JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+		WHERE p.prokind IN ('f', 'p')
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM pg_catalog.pg_depend d
+				WHERE d.classid = 'pg_catalog.pg_proc'::regclass
+					AND d.objid = p.oid
+					AND d.deptype = 'e'
+			)
+		ORDER BY n.nspname, p.proname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []interfaces.RoutineInfo
+	for rows.Next() {
+		var (
+			routine interfaces.RoutineInfo
+			kind    string
+		)
+		if err := rows.Scan(&routine.Schema, &routine.Name, &kind, &routine.ReturnType); err != nil {
+			return nil, fmt.Errorf("error scanning routine: %w", err)
+		}
+
+		routine.Type = objectFunction
+		if kind == "p" {
+			routine.Type = objectProcedure
+		}
+		routines = append(routines, routine)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating routines: %w", err)
+	}
+
+	return routines, nil
+}
+
+// GetObjectDefinition returns the source and parameters of a view,
+// procedure or function. Unqualified names are resolved through the
+// search_path; overloaded functions have to be told apart by the caller.
+func (p *postgresImpl) GetObjectDefinition(ctx context.Context, name string) (interfaces.ObjectDefinition, error) {
+	schemaName, objectName := splitTableName(name)
+
+	// Views and routines share the lookup rules, so both are searched; an
+	// empty schema matches the objects visible through the search_path
+	query := `
+		SELECT n.nspname, c.relname, 'VIEW', '', pg_catalog.pg_get_viewdef(c.oid, true), 0::oid
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('v', 'm')
+			AND c.relname = $2
+			AND (n.nspname = $1 OR ($1 = '' AND pg_catalog.pg_table_is_visible(c.oid)))
+		UNION ALL
+		SELECT
+			n.nspname,
+			p.proname,
+			CASE WHEN p.prokind = 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+			CASE WHEN p.prokind = 'f' THEN pg_catalog.pg_get_function_result(p.oid) ELSE '' END,
+			pg_catalog.pg_get_functiondef(p.oid),
+			p.oid
+		FROM pg_catalog.pg_proc p
+		JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+		WHERE p.prokind IN ('f', 'p')
+			AND p.proname = $2
+			AND (n.nspname = $1 OR ($1 = '' AND pg_catalog.pg_function_is_visible(p.oid)))
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, schemaName, objectName)
+	if err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error getting definition of %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var (
+		matches []interfaces.ObjectDefinition
+		oids    []int64
+	)
+	for rows.Next() {
+		var (
+			definition interfaces.ObjectDefinition
+			oid        int64
+		)
+		if err := rows.Scan(&definition.Schema, &definition.Name, &definition.Type, &definition.ReturnType, &definition.Definition, &oid); err != nil {
+			return interfaces.ObjectDefinition{}, fmt.Errorf("error scanning definition: %w", err)
+		}
+		matches = append(matches, definition)
+		oids = append(oids, oid)
+	}
+
+	if err := rows.Err(); err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error iterating definitions: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return interfaces.ObjectDefinition{}, fmt.Errorf("view, procedure or function %s not found", name)
+	case 1:
+	default:
+		return interfaces.ObjectDefinition{}, fmt.Errorf("%s is ambiguous: it matches %d views or overloaded routines", name, len(matches))
+	}
+
+	result := matches[0]
+	if result.Type == objectView {
+		return result, nil
+	}
+
+	// Output columns of RETURNS TABLE functions (mode 't') belong to the
+	// result, not the parameter list
+	query = `
+		SELECT
+			COALESCE(a.name, ''),
+			pg_catalog.format_type(a.type, NULL),
+			CASE a.mode WHEN 'o' THEN 'OUT' WHEN 'b' THEN 'INOUT' WHEN 'v' THEN 'VARIADIC' ELSE 'IN' END
+		FROM pg_catalog.pg_proc p,
+			unnest(COALESCE(p.proallargtypes, p.proargtypes::oid[]), p.proargnames, p.proargmodes)
+				WITH ORDINALITY AS a(type, name, mode, position)
+		WHERE p.oid = $1
+			AND a.type IS NOT NULL
+			AND COALESCE(a.mode, 'i') <> 't'
+		ORDER BY a.position
+	`
+
+	paramRows, err := p.db.QueryContext(ctx, query, oids[0])
+	if err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error getting parameters of %s: %w", name, err)
+	}
+	defer paramRows.Close()
+
+	for paramRows.Next() {
+		var parameter interfaces.ParameterInfo
+		if err := paramRows.Scan(&parameter.Name, &parameter.Type, &parameter.Mode); err != nil {
+			return interfaces.ObjectDefinition{}, fmt.Errorf("error scanning parameter: %w", err)
+		}
+		result.Parameters = append(result.Parameters, parameter)
+	}
+
+	if err := paramRows.Err(); err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error iterating parameters: %w", err)
+	}
+
+	return result, nil
+}
+
+// getDBSchemas returns a list of all user schemas in the database
+func (p *postgresImpl) getDBSchemas(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT nspname
+		FROM pg_catalog.pg_namespace
+		WHERE nspname NOT IN ('pg_catalog', 'information_schema')
+			AND nspname NOT LIKE 'pg_toast%'
+			AND nspname NOT LIKE 'pg_temp%'
+		ORDER BY nspname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting schemas: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// schemaVersion returns the count and the summed transaction IDs of the
+// catalog rows describing user relations, columns, routines and view rules.
+// Creating, altering or dropping an object writes new rows, while VACUUM
+// and ANALYZE update statistics in place and leave the version alone.
+func (p *postgresImpl) schemaVersion(ctx context.Context) (string, error) {
+	query := `
+		WITH user_class AS (
+			SELECT c.oid, c.xmin
+			FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+				AND n.nspname NOT LIKE 'pg_toast%'
+				AND n.nspname NOT LIKE 'pg_temp%'
+		)
+		SELECT concat_ws('/',
+			(SELECT count(*) || ':' || coalesce(sum(xmin::text::bigint), 0) FROM user_class),
+			(SELECT count(*) || ':' || coalesce(sum(a.xmin::text::bigint), 0)
+				FROM pg_catalog.pg_attribute a JOIN user_class c ON c.oid = a.attrelid
+				WHERE a.attnum > 0),
+			(SELECT count(*) || ':' || coalesce(sum(r.xmin::text::bigint), 0)
+				FROM pg_catalog.pg_rewrite r JOIN user_class c ON c.oid = r.ev_class),
+			(SELECT count(*) || ':' || coalesce(sum(p.xmin::text::bigint), 0)
+				FROM pg_catalog.pg_proc p
+				JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+				WHERE n.nspname NOT IN ('pg_catalog', 'information_schema'))
+		)
+	`
+
+	var version string
+	if err := p.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return "", fmt.Errorf("error getting schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// getSequences returns every sequence with its type, bounds and current value
+func (p *postgresImpl) getSequences(ctx context.Context) ([]map[string]any, error) {
+	query := `
+		SELECT
+			n.nspname || '.' || c.relname AS sequence_name,
+			pg_catalog.format_type(s.seqtypid, NULL) AS data_type,
+			s.seqstart AS start_value,
+			s.seqincrement AS increment_by,
+			s.seqmin AS min_value,
+			s.seqmax AS max_value,
+			s.seqcycle AS cycle,
+			CASE WHEN pg_catalog.has_sequence_privilege(c.oid, 'SELECT,USAGE')
+				THEN pg_catalog.pg_sequence_last_value(c.oid) END AS last_value,
+			tn.nspname || '.' || t.relname || '.' || ta.attname AS owned_by
+		FROM pg_catalog.pg_sequence s
+		JOIN pg_catalog.pg_class c ON c.oid = s.seqrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_depend dep
+			ON dep.classid = 'pg_catalog.pg_class'::regclass
+			AND dep.objid = c.oid
+			AND dep.refclassid = 'pg_catalog.pg_class'::regclass
+			AND dep.deptype IN ('a', 'i')
+		LEFT JOIN pg_catalog.pg_class t ON t.oid = dep.refobjid
+		LEFT JOIN pg_catalog.pg_namespace tn ON tn.oid = t.relnamespace
+		LEFT JOIN pg_catalog.pg_attribute ta ON ta.attrelid = dep.refobjid AND ta.attnum = dep.refobjsubid
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY n.nspname, c.relname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sequences: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRows(rows)
+}
+
+// getEnumTypes returns every enum type with its labels in sort order
+func (p *postgresImpl) getEnumTypes(ctx context.Context) ([]map[string]any, error) {
+	query := `
+		SELECT
+			n.nspname || '.' || t.typname AS enum_name,
+			string_agg(e.enumlabel, ', ' ORDER BY e.enumsortorder) AS labels
+		FROM pg_catalog.pg_type t
+		JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+		GROUP BY n.nspname, t.typname
+		ORDER BY n.nspname, t.typname
+	`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting enum types: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRows(rows)
+}
+
+// scriptTable scripts a table as CREATE TABLE with its columns and
+// constraints as the server defines them, followed by its other indexes.
+// Unqualified names are resolved through the search path.
+func (p *postgresImpl) scriptTable(ctx context.Context, tableName string) (string, error) {
+	schemaName, relName := splitTableName(tableName)
+
+	query := `
+		SELECT
+			c.oid,
+			pg_catalog.quote_ident(n.nspname) || '.' || pg_catalog.quote_ident(c.relname),
+			CASE WHEN c.relkind = 'p' THEN pg_catalog.pg_get_partkeydef(c.oid) ELSE '' END
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relname = $1
+			AND c.relkind IN ('r', 'p')
+	`
+	args := []any{relName}
+	if schemaName == "" {
+		query += " AND pg_catalog.pg_table_is_visible(c.oid)"
+	} else {
+		query += " AND n.nspname = $2"
+		args = append(args, schemaName)
+	}
+
+	var (
+		oid                  int64
+		qualifiedName, parts string
+	)
+	err := p.db.QueryRowContext(ctx, query, args...).Scan(&oid, &qualifiedName, &parts)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("table %s not found", tableName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error resolving table %s: %w", tableName, err)
+	}
+
+	// attidentity is 'a' for GENERATED ALWAYS and 'd' for BY DEFAULT
+	// identity columns, attgenerated 's' for stored generated columns
+	query = `
+		SELECT
+			pg_catalog.quote_ident(a.attname),
+			pg_catalog.format_type(a.atttypid, a.atttypmod),
+			a.attnotnull,
+			COALESCE(pg_catalog.pg_get_expr(d.adbin, d.adrelid), ''),
+			a.attidentity::text,
+			a.attgenerated::text,
+			CASE WHEN a.attcollation <> t.typcollation THEN pg_catalog.quote_ident(co.collname) ELSE '' END
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_type t ON t.oid = a.atttypid
+		LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		LEFT JOIN pg_catalog.pg_collation co ON co.oid = a.attcollation
+		WHERE a.attrelid = $1
+			AND a.attnum > 0
+			AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, oid)
+	if err != nil {
+		return "", fmt.Errorf("error getting columns: %w", err)
+	}
+	defer rows.Close()
+
+	var definitions []string
+	for rows.Next() {
+		var (
+			name, typeName, expression, identity, generated, collation string
+			notNull                                                    bool
+		)
+		if err := rows.Scan(&name, &typeName, &notNull, &expression, &identity, &generated, &collation); err != nil {
+			return "", fmt.Errorf("error scanning column: %w", err)
+		}
+
+		definition := name + " " + typeName
+		if collation != "" {
+			definition += " COLLATE " + collation
+		}
+		switch {
+		case generated == "s":
+			definition += fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", expression)
+		case identity == "a":
+			definition += " GENERATED ALWAYS AS IDENTITY"
+		case identity == "d":
+			definition += " GENERATED BY DEFAULT AS IDENTITY"
+		case expression != "":
+			definition += " DEFAULT " + expression
+		}
+		if notNull {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating columns: %w", err)
+	}
+
+	// NOT NULL constraints are scripted with their columns
+	query = `
+		SELECT
+			pg_catalog.quote_ident(conname),
+			pg_catalog.pg_get_constraintdef(oid, true)
+		FROM pg_catalog.pg_constraint
+		WHERE conrelid = $1
+			AND contype IN ('p', 'u', 'x', 'c', 'f')
+		ORDER BY CASE contype WHEN 'p' THEN 0 WHEN 'u' THEN 1 WHEN 'x' THEN 2 WHEN 'c' THEN 3 ELSE 4 END, conname
+	`
+
+	rows, err = p.db.QueryContext(ctx, query, oid)
+	if err != nil {
+		return "", fmt.Errorf("error getting constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return "", fmt.Errorf("error scanning constraint: %w", err)
+		}
+		definitions = append(definitions, fmt.Sprintf("CONSTRAINT %s %s", name, definition))
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating constraints: %w", err)
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("CREATE TABLE %s (\n    %s\n)", qualifiedName, strings.Join(definitions, ",\n    ")))
+	if parts != "" {
+		resultText.WriteString(" PARTITION BY " + parts)
+	}
+	resultText.WriteString(";\n")
+
+	// Indexes backing constraints were scripted with the constraints
+	query = `
+		SELECT pg_catalog.pg_get_indexdef(i.indexrelid)
+		FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+		WHERE i.indrelid = $1
+			AND NOT EXISTS (
+				SELECT 1
+				FROM pg_catalog.pg_constraint con
+				WHERE con.conindid = i.indexrelid
+					AND con.conrelid = i.indrelid
+					AND con.contype IN ('p', 'u', 'x')
+			)
+		ORDER BY ic.relname
+	`
+
+	rows, err = p.db.QueryContext(ctx, query, oid)
+	if err != nil {
+		return "", fmt.Errorf("error getting indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes, err := scanStrings(rows)
+	if err != nil {
+		return "", fmt.Errorf("error getting indexes: %w", err)
+	}
+	for _, index := range indexes {
+		resultText.WriteString("\n" + index + ";\n")
+	}
+
+	return resultText.String(), nil
+}
+
+// postgresConfigFromEnv reads the PostgreSQL connection configured through
+// the PG_* variables
+func postgresConfigFromEnv() connectionConfig {
+	return connectionConfig{
+		Name:     defaultConnectionName,
+		Engine:   enginePostgres,
+		Host:     os.Getenv("PG_HOST"),
+		Port:     os.Getenv("PG_PORT"),
+		User:     os.Getenv("PG_USER"),
+		Password: os.Getenv("PG_PASSWORD"),
+		Database: os.Getenv("PG_DATABASE"),
+		SSLMode:  os.Getenv("PG_SSLMODE"),
+		ReadOnly: readOnlyFromEnv("PG_READ_ONLY"),
+
+		MaxAffectedRows: intFromEnv("PG_MAX_AFFECTED_ROWS", defaultMaxAffectedRows),
+		MaxRows:         intFromEnv("PG_MAX_ROWS", defaultMaxRows),
+		MaxResultBytes:  intFromEnv("PG_MAX_RESULT_BYTES", defaultMaxResultBytes),
+
+		StatementTimeout: secondsFromEnv("PG_STATEMENT_TIMEOUT", defaultStatementTimeout),
+
+		Masking: maskingConfigFromEnv("PG_"),
+	}
+}
+
+// createPostgresConnection establishes a connection to the PostgreSQL server
+func createPostgresConnection(config connectionConfig) (*sql.DB, error) {
+	port := config.Port
+	sslMode := config.SSLMode
+
+	if port == "" {
+		port = "5432"
+	}
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	// Build a URL so credentials containing special characters are escaped
+	connectionURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.User, config.Password),
+		Host:     fmt.Sprintf("%s:%s", config.Host, port),
+		Path:     config.Database,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+
+	log.Printf("Connecting to PostgreSQL %q with connection string: %s", config.Name, connectionURL.Redacted())
+
+	db, err := sql.Open("postgres", connectionURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to PostgreSQL: %w", err)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to PostgreSQL: %w", err)
+	}
+
+	return db, nil
+}
+
+// NewPostgresTool creates a new instance of the PostgreSQL tool
+func NewPostgresTool(server *server.MCPServer) interfaces.Database {
+	// Create a new PostgreSQL implementation
+	config := postgresConfigFromEnv()
+	postgresTool := &postgresImpl{config: config}
+
+	// Connect to the database
+	if err := postgresTool.Connect(); err != nil {
+		// Log the error but continue without the tool
+		log.Printf("Failed to initialize PostgreSQL tool: %v", err)
+		return nil
+	}
+
+	log.Println("PostgreSQL tool initialized successfully")
+
+	// Add the PostgreSQL tools to the MCP server
+	if server != nil {
+		// Register the query and table tools shared with the other backends
+		connections := newConnectionRegistry(false)
+		connections.add(config, postgresTool)
+		registerDatabaseTools(server, "pg", "PostgreSQL", connections)
+
+		// Register tool for getting database schemas
+		getSchemasTool := mcp.NewTool("pg_get_schemas",
+			mcp.WithDescription("Get a list of all schemas in the PostgreSQL database"),
+		)
+
+		server.AddTool(getSchemasTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			queryCtx, cancel, err := statementContext(ctx, config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			schemas, err := postgresTool.getDBSchemas(queryCtx)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			return mcp.NewToolResultText(formatNameList("schemas", schemas)), nil
+		})
+
+		// Register tool for listing sequences
+		getSequencesTool := mcp.NewTool("pg_get_sequences",
+			mcp.WithDescription("Get all sequences in the PostgreSQL database with their bounds, current value and owning column"),
+		)
+
+		server.AddTool(getSequencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			queryCtx, cancel, err := statementContext(ctx, config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			sequences, err := postgresTool.getSequences(queryCtx)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			var resultText strings.Builder
+			resultText.WriteString(fmt.Sprintf("Found %d sequences:\n\n", len(sequences)))
+			resultText.WriteString("SEQUENCE_NAME\tDATA_TYPE\tSTART\tINCREMENT\tMIN\tMAX\tCYCLE\tLAST_VALUE\tOWNED_BY\n")
+			resultText.WriteString("----------\t----------\t----------\t----------\t----------\t----------\t----------\t----------\t----------\n")
+
+			for _, seq := range sequences {
+				resultText.WriteString(fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+					seq["sequence_name"], seq["data_type"], seq["start_value"], seq["increment_by"],
+					seq["min_value"], seq["max_value"], seq["cycle"], valueOrEmpty(seq["last_value"]), valueOrEmpty(seq["owned_by"])))
+			}
+
+			return mcp.NewToolResultText(resultText.String()), nil
+		})
+
+		// Register tool for listing enum types
+		getEnumTypesTool := mcp.NewTool("pg_get_enum_types",
+			mcp.WithDescription("Get all enum types in the PostgreSQL database with their labels in sort order"),
+		)
+
+		server.AddTool(getEnumTypesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			queryCtx, cancel, err := statementContext(ctx, config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			enums, err := postgresTool.getEnumTypes(queryCtx)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			var resultText strings.Builder
+			resultText.WriteString(fmt.Sprintf("Found %d enum types:\n\n", len(enums)))
+
+			for i, enum := range enums {
+				resultText.WriteString(fmt.Sprintf("%d. %v: %v\n", i+1, enum["enum_name"], enum["labels"]))
+			}
+
+			return mcp.NewToolResultText(resultText.String()), nil
+		})
+	}
+
+	return postgresTool
+}
@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// procedureCaller is implemented by backends that can call stored
+// procedures for the call_procedure tool
+type procedureCaller interface {
+	callProcedure(ctx context.Context, name string, parameters map[string]any, limit int) (procedureResult, error)
+}
+
+// procedureResult is everything a stored procedure call produced
+type procedureResult struct {
+	// procedure is the schema-qualified name of the procedure called
+	procedure string
+
+	// resultSets holds at most limit rows of each result set, and
+	// rowCounts the number of rows each set had in total
+	resultSets []queryResult
+	rowCounts  []int
+
+	// outputs holds the final values of the OUTPUT parameters in
+	// declaration order
+	outputs []procedureOutput
+
+	// returnValue is the value of the procedure's RETURN statement
+	returnValue int64
+
+	// rolledBack is set when the call ran in a transaction that was rolled
+	// back, as on read-only connections
+	rolledBack bool
+}
+
+// procedureOutput is the value an OUTPUT parameter held after the call
+type procedureOutput struct {
+	name  string
+	value any
+}
+
+// procedureParametersFromRequest reads the parameters argument of a
+// procedure call, a JSON object given either as an object or as a string.
+// Names may be given with or without their leading @, and values with the
+// type hints of queryParameterValue.
+func procedureParametersFromRequest(request mcp.CallToolRequest) (map[string]any, error) {
+	var parameters map[string]any
+	switch value := request.Params.Arguments["parameters"].(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		parameters = value
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return nil, nil
+		}
+		if err := json.Unmarshal([]byte(value), &parameters); err != nil {
+			return nil, fmt.Errorf("parameters must be a JSON object: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("parameters must be a JSON object")
+	}
+
+	result := make(map[string]any, len(parameters))
+	for name, value := range parameters {
+		key := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+		if _, exists := result[key]; exists {
+			return nil, fmt.Errorf("parameter @%s is given more than once", key)
+		}
+
+		value, err := queryParameterValue(engineSQLServer, value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter @%s: %w", key, err)
+		}
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// procedureAllowed reports whether allowList permits calling the procedure
+// schemaName.procName. Entries are "schema.procedure" or "schema.*" and
+// compare case-insensitively; unqualified entries name procedures in dbo.
+func procedureAllowed(allowList []string, schemaName string, procName string) bool {
+	for _, entry := range allowList {
+		entrySchema, entryName := splitTableName(entry)
+		if entrySchema == "" {
+			entrySchema = "dbo"
+		}
+
+		if strings.EqualFold(entrySchema, schemaName) && (entryName == "*" || strings.EqualFold(entryName, procName)) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonProcedureResult is the document returned for a procedure call in
+// json format
+type jsonProcedureResult struct {
+	Procedure        string          `json:"procedure"`
+	ResultSets       []jsonResultSet `json:"result_sets"`
+	OutputParameters map[string]any  `json:"output_parameters"`
+	ReturnValue      int64           `json:"return_value"`
+	RolledBack       bool            `json:"rolled_back"`
+}
+
+// formatProcedureResult renders the result sets, OUTPUT parameters and
+// return value of a procedure call, labelling each result set. Rows past
+// maxBytes of output are left out like those of a query page; a procedure
+// cannot be paged, so they are only reported.
+func formatProcedureResult(result procedureResult, format string, maxBytes int64) (string, error) {
+	headers := make([]string, len(result.resultSets))
+	lines := make([][]string, len(result.resultSets))
+	for i, set := range result.resultSets {
+		var err error
+		headers[i], lines[i], err = renderRows(set, format)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	shownInSet, _, truncatedBytes := fitRows(headers, lines, maxBytes)
+	for i := range result.resultSets {
+		set := &result.resultSets[i]
+		if shownInSet[i] < len(set.rows) {
+			set.rows = set.rows[:shownInSet[i]]
+			set.more = true
+		}
+	}
+
+	if format == formatJSON {
+		document := jsonProcedureResult{
+			Procedure:        result.procedure,
+			ResultSets:       make([]jsonResultSet, 0, len(result.resultSets)),
+			OutputParameters: make(map[string]any, len(result.outputs)),
+			ReturnValue:      result.returnValue,
+			RolledBack:       result.rolledBack,
+		}
+		for i, set := range result.resultSets {
+			document.ResultSets = append(document.ResultSets, jsonResultSet{
+				ResultSet: i + 1,
+				Columns:   resultColumns(set),
+				Rows:      append(make([][]any, 0, len(set.rows)), set.rows...),
+				RowCount:  result.rowCounts[i],
+				Truncated: set.more,
+			})
+		}
+		for _, output := range result.outputs {
+			document.OutputParameters[output.name] = output.value
+		}
+
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Procedure %s executed with %d result sets.\n", result.procedure, len(result.resultSets)))
+	if result.rolledBack {
+		resultText.WriteString("The connection is read-only: the call ran in a transaction that was rolled back.\n")
+	}
+
+	for i, set := range result.resultSets {
+		resultText.WriteString(fmt.Sprintf("\nResult set %d (%d rows):\n\n", i+1, result.rowCounts[i]))
+		resultText.WriteString(headers[i])
+		for _, line := range lines[i][:len(set.rows)] {
+			resultText.WriteString(line)
+		}
+		if set.more {
+			resultText.WriteString(fmt.Sprintf("Result set truncated: showing the first %d of %d rows.\n", len(set.rows), result.rowCounts[i]))
+		}
+	}
+	if truncatedBytes {
+		resultText.WriteString(fmt.Sprintf("\nResults truncated: output reached the %d byte limit.\n", maxBytes))
+	}
+
+	if len(result.outputs) > 0 {
+		resultText.WriteString("\nOutput parameters:\n\n")
+		resultText.WriteString("NAME\tVALUE\n")
+		resultText.WriteString("----------\t----------\n")
+		for _, output := range result.outputs {
+			value := "NULL"
+			if output.value != nil {
+				value = fmt.Sprintf("%v", output.value)
+			}
+			resultText.WriteString(fmt.Sprintf("%s\t%s\n", output.name, value))
+		}
+	}
+
+	resultText.WriteString(fmt.Sprintf("\nReturn value: %d\n", result.returnValue))
+
+	return resultText.String(), nil
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatProcedureResultByteLimit(t *testing.T) {
+	set := func(values ...string) queryResult {
+		result := queryResult{columns: []string{"value"}}
+		for _, value := range values {
+			result.rows = append(result.rows, []any{value})
+		}
+		return result
+	}
+	long := strings.Repeat("x", 100)
+	result := procedureResult{
+		procedure:  "dbo.usp_Report",
+		resultSets: []queryResult{set(long, long, long), set(long)},
+		rowCounts:  []int{3, 1},
+	}
+
+	output, err := formatProcedureResult(result, formatText, 250)
+	if err != nil {
+		t.Fatalf("formatProcedureResult failed: %v", err)
+	}
+	if strings.Count(output, long) != 2 {
+		t.Errorf("output holds %d rows, want the 2 that fit in 250 bytes:\n%s", strings.Count(output, long), output)
+	}
+	for _, want := range []string{
+		"Result set truncated: showing the first 2 of 3 rows.",
+		"Result set truncated: showing the first 0 of 1 rows.",
+		"Results truncated: output reached the 250 byte limit.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q:\n%s", want, output)
+		}
+	}
+
+	result = procedureResult{procedure: "dbo.usp_Report", resultSets: []queryResult{set(long, long)}, rowCounts: []int{2}}
+	output, err = formatProcedureResult(result, formatText, 0)
+	if err != nil {
+		t.Fatalf("formatProcedureResult failed: %v", err)
+	}
+	if strings.Count(output, long) != 2 || strings.Contains(output, "truncated") {
+		t.Errorf("output without a byte limit was cut short:\n%s", output)
+	}
+}
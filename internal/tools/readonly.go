@@ -0,0 +1,270 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// readStatementKeywords are the statement types a read-only connection runs
+var readStatementKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXPLAIN":  true,
+}
+
+// writeKeywords mark a data-modifying or data-exporting clause inside an
+// otherwise read-only statement, e.g. SELECT ... INTO or a CTE running DELETE
+var writeKeywords = map[string]bool{
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+	"MERGE":  true,
+	"INTO":   true,
+}
+
+// sideEffectFunctions are functions callable from a SELECT that change
+// state outside the query, per engine
+var sideEffectFunctions = map[string]map[string]bool{
+	engineSQLServer: {
+		"OPENQUERY":      true,
+		"OPENROWSET":     true,
+		"OPENDATASOURCE": true,
+	},
+	enginePostgres: {
+		"DBLINK_EXEC":          true,
+		"PG_TERMINATE_BACKEND": true,
+		"PG_CANCEL_BACKEND":    true,
+		"PG_RELOAD_CONF":       true,
+		"PG_ROTATE_LOGFILE":    true,
+		"LO_IMPORT":            true,
+		"LO_EXPORT":            true,
+		"SET_CONFIG":           true,
+	},
+	engineMySQL: {
+		"GET_LOCK": true,
+	},
+}
+
+// sqlServerStatementKeywords start statements that modify data, schema or
+// server state on SQL Server. T-SQL batches need no semicolons between
+// statements, and these are reserved words that cannot be unquoted
+// identifiers, so they are rejected wherever they appear. The Service
+// Broker keywords RECEIVE, SEND and MOVE are not reserved, but are rejected
+// as well since their statements dequeue and move messages; columns of
+// those names must be quoted.
+var sqlServerStatementKeywords = map[string]bool{
+	"ALTER":       true,
+	"BACKUP":      true,
+	"BULK":        true,
+	"CHECKPOINT":  true,
+	"CREATE":      true,
+	"DBCC":        true,
+	"DENY":        true,
+	"DROP":        true,
+	"EXEC":        true,
+	"EXECUTE":     true,
+	"GRANT":       true,
+	"KILL":        true,
+	"MOVE":        true,
+	"RECEIVE":     true,
+	"RECONFIGURE": true,
+	"RESTORE":     true,
+	"REVERT":      true,
+	"REVOKE":      true,
+	"SEND":        true,
+	"SETUSER":     true,
+	"SHUTDOWN":    true,
+	"TRUNCATE":    true,
+	"UPDATETEXT":  true,
+	"USE":         true,
+	"WRITETEXT":   true,
+}
+
+// explainedStatementKeywords start the statement that follows the options of
+// an EXPLAIN
+var explainedStatementKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "VALUES": true, "TABLE": true,
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "REPLACE": true,
+	"CREATE": true, "EXECUTE": true, "DECLARE": true,
+}
+
+// checkReadOnlyQuery parses query in the given engine's dialect and
+// returns an error naming the first statement that could modify data or
+// server state. It is the gate for connections in read-only mode; where the
+// engine supports it, the query additionally runs in a read-only session.
+func checkReadOnlyQuery(engine string, query string) error {
+	statements, err := splitSQLStatements(engine, query)
+	if err != nil {
+		return fmt.Errorf("query could not be parsed for read-only checking: %w", err)
+	}
+
+	for i, statement := range statements {
+		if reason := readOnlyViolation(engine, statement.tokens); reason != "" {
+			return fmt.Errorf("statement %d of %d is not allowed on a read-only connection (%s): %s",
+				i+1, len(statements), reason, abbreviate(statement.text, 200))
+		}
+	}
+
+	return nil
+}
+
+// readOnlyViolation returns why the statement is not read-only, or an
+// empty string when it is
+func readOnlyViolation(engine string, tokens []sqlToken) string {
+	// Skip the parentheses around a parenthesized query such as (SELECT 1) UNION (SELECT 2)
+	first := 0
+	for first < len(tokens) && tokens[first].text == "(" {
+		first++
+	}
+	if first == len(tokens) {
+		return ""
+	}
+
+	if engine == engineSQLServer {
+		if reason := sqlServerBatchViolation(tokens[first:]); reason != "" {
+			return reason
+		}
+	}
+
+	lead := tokens[first]
+	if lead.kind != tokenWord {
+		return fmt.Sprintf("unexpected %q at the start of the statement", lead.text)
+	}
+
+	switch {
+	case lead.upper == "EXPLAIN":
+		return explainViolation(engine, tokens[first+1:])
+
+	case lead.upper == "DECLARE" && engine == engineSQLServer:
+		// Variable declarations may initialize from a query
+		return clauseViolation(engine, tokens[first+1:])
+
+	case lead.upper == "SET" && (engine == engineSQLServer || engine == engineMySQL):
+		return setViolation(engine, tokens[first+1:])
+
+	case lead.upper == "PRAGMA" && engine == engineSQLite:
+		// Reading pragmas is fine, assigning them is not
+		for _, token := range tokens[first+1:] {
+			if token.text == "=" {
+				return "PRAGMA assignment"
+			}
+		}
+		return ""
+
+	case readStatementKeywords[lead.upper]:
+		return clauseViolation(engine, tokens[first+1:])
+	}
+
+	return lead.upper + " statement"
+}
+
+// clauseViolation looks for data-modifying clauses and side-effecting
+// function calls inside a read statement
+func clauseViolation(engine string, tokens []sqlToken) string {
+	for i, token := range tokens {
+		if token.kind != tokenWord {
+			continue
+		}
+
+		if writeKeywords[token.upper] {
+			// FOR UPDATE and friends take row locks rather than writing
+			if token.upper == "UPDATE" && i > 0 && (tokens[i-1].isKeyword("FOR") || tokens[i-1].isKeyword("KEY")) {
+				return "locking clause FOR UPDATE"
+			}
+			if token.upper == "INTO" {
+				return "SELECT ... INTO"
+			}
+			return token.upper + " inside the statement"
+		}
+
+		if sideEffectFunctions[engine][token.upper] && i+1 < len(tokens) && tokens[i+1].text == "(" {
+			return "call to " + token.text
+		}
+	}
+
+	return ""
+}
+
+// sqlServerBatchViolation looks for statements that follow the first one
+// of a T-SQL batch without a semicolon between them, such as
+// SELECT 1 DROP TABLE t, and returns the first that is not read-only
+func sqlServerBatchViolation(tokens []sqlToken) string {
+	for i, token := range tokens {
+		if token.kind != tokenWord {
+			continue
+		}
+
+		switch {
+		case sqlServerStatementKeywords[token.upper]:
+			return token.upper + " statement"
+		case (token.upper == "ENABLE" || token.upper == "DISABLE") && i+1 < len(tokens) && tokens[i+1].isKeyword("TRIGGER"):
+			return token.upper + " TRIGGER statement"
+		case (token.upper == "END" || token.upper == "GET") && i+1 < len(tokens) && tokens[i+1].isKeyword("CONVERSATION"):
+			// END CONVERSATION and GET CONVERSATION GROUP; END also closes
+			// CASE expressions and BEGIN blocks
+			return token.upper + " CONVERSATION statement"
+		case token.upper == "SET" && i > 0:
+			// UPDATE ... SET is rejected as UPDATE, so any other SET starts
+			// a statement of its own
+			if reason := setViolation(engineSQLServer, tokens[i+1:]); reason != "" {
+				return reason
+			}
+		}
+	}
+
+	return ""
+}
+
+// explainViolation checks the statement being explained. EXPLAIN ANALYZE
+// executes it, so it must be read-only itself.
+func explainViolation(engine string, tokens []sqlToken) string {
+	depth := 0
+	for i, token := range tokens {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case depth == 0 && token.kind == tokenWord && explainedStatementKeywords[token.upper]:
+			return readOnlyViolation(engine, tokens[i:])
+		}
+	}
+
+	// MySQL's EXPLAIN table_name describes a table
+	return ""
+}
+
+// setViolation allows assigning variables (and on SQL Server a few
+// harmless session options) but no other SET statement
+func setViolation(engine string, tokens []sqlToken) string {
+	if len(tokens) == 0 {
+		return "SET statement"
+	}
+
+	switch {
+	case tokens[0].kind == tokenParam:
+		return clauseViolation(engine, tokens[1:])
+	case engine == engineSQLServer && tokens[0].isKeyword("NOCOUNT"):
+		return ""
+	case engine == engineSQLServer && tokens[0].isKeyword("TRANSACTION") &&
+		len(tokens) > 1 && tokens[1].isKeyword("ISOLATION"):
+		return ""
+	}
+
+	return "SET " + tokens[0].text
+}
+
+// abbreviate shortens text to at most max runes, collapsing whitespace
+func abbreviate(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,66 @@
+package tools
+
+import "testing"
+
+func TestCheckReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		engine  string
+		query   string
+		allowed bool
+	}{
+		{engineSQLServer, "SELECT * FROM dbo.Orders", true},
+		{engineSQLServer, "SELECT 1; SELECT 2", true},
+		{engineSQLServer, "(SELECT 1) UNION (SELECT 2)", true},
+		{engineSQLServer, "WITH c AS (SELECT 1 AS n) SELECT n FROM c", true},
+		{engineSQLServer, "DECLARE @x int = 1 SELECT @x", true},
+		{engineSQLServer, "SET NOCOUNT ON SELECT 1", true},
+		{engineSQLServer, "SET @x = 1 SELECT @x", true},
+		{engineSQLServer, "SELECT [Drop], 'EXEC x' FROM t -- DROP TABLE t", true},
+		{engineSQLServer, "INSERT INTO t VALUES (1)", false},
+		{engineSQLServer, "SELECT * INTO t2 FROM t", false},
+		{engineSQLServer, "SELECT * FROM OPENROWSET('x', 'y', 'z')", false},
+		{engineSQLServer, "SELECT 1 DROP TABLE dbo.Orders", false},
+		{engineSQLServer, "SELECT 1 EXEC sp_configure 'x', 1", false},
+		{engineSQLServer, "SELECT 1 SHUTDOWN", false},
+		{engineSQLServer, "SELECT 1 ALTER LOGIN sa WITH PASSWORD='x'", false},
+		{engineSQLServer, "DECLARE @x int = 1 TRUNCATE TABLE dbo.Orders", false},
+		{engineSQLServer, "SET @x = 1 DROP TABLE t", false},
+		{engineSQLServer, "SELECT 1 DBCC FREEPROCCACHE", false},
+		{engineSQLServer, "SELECT 1 DISABLE TRIGGER tr ON t", false},
+		{engineSQLServer, "SELECT 1 SET IDENTITY_INSERT t ON", false},
+		{engineSQLServer, "SELECT 1 UPDATE t SET x = 1", false},
+		{engineSQLServer, "SELECT 1 USE master", false},
+		{engineSQLServer, "SELECT 1 RECEIVE TOP(100) * FROM dbo.SomeQueue", false},
+		{engineSQLServer, "WAITFOR (RECEIVE TOP(1) * FROM dbo.SomeQueue), TIMEOUT 1000", false},
+		{engineSQLServer, "SELECT 1 SEND ON CONVERSATION @handle MESSAGE TYPE [m] ('x')", false},
+		{engineSQLServer, "SELECT 1 END CONVERSATION @handle", false},
+		{engineSQLServer, "SELECT 1 MOVE CONVERSATION @handle TO @group", false},
+		{engineSQLServer, "SELECT 1 GET CONVERSATION GROUP @group FROM dbo.SomeQueue", false},
+		{engineSQLServer, "SELECT CASE WHEN x = 1 THEN 'a' END FROM t", true},
+		{engineSQLServer, "SELECT [Receive], [Send] FROM t", true},
+		{enginePostgres, "SELECT * FROM t FOR SHARE", true},
+		{enginePostgres, "EXPLAIN ANALYZE SELECT 1", true},
+		{enginePostgres, "EXPLAIN ANALYZE DELETE FROM t", false},
+		{enginePostgres, "WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", false},
+		{enginePostgres, "SELECT * FROM t FOR UPDATE", false},
+		{enginePostgres, "SELECT set_config('x', 'y', false)", false},
+		{enginePostgres, "SELECT $$; DROP TABLE t$$", true},
+		{engineMySQL, "SHOW TABLES", true},
+		{engineMySQL, "SET @x = 1", true},
+		{engineMySQL, "SET GLOBAL max_connections = 1", false},
+		{engineMySQL, "SELECT 'a\\'; DROP TABLE t'", true},
+		{engineSQLite, "PRAGMA table_info(t)", true},
+		{engineSQLite, "PRAGMA journal_mode = WAL", false},
+		{engineSQLite, "VACUUM", false},
+	}
+
+	for _, test := range tests {
+		err := checkReadOnlyQuery(test.engine, test.query)
+		if test.allowed && err != nil {
+			t.Errorf("checkReadOnlyQuery(%s, %q) = %v, want it allowed", test.engine, test.query, err)
+		}
+		if !test.allowed && err == nil {
+			t.Errorf("checkReadOnlyQuery(%s, %q) allowed it, want an error", test.engine, test.query)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+	"github.com/anhnt2003/mcp-tool-kit/internal/transport"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resourceScheme prefixes the URIs of the schema resources, which name a
+// connection, a schema and a table as db://<connection>/<schema>/<table>
+const resourceScheme = "db://"
+
+// MIME types of the schema resources
+const (
+	resourceMIMEText = "text/plain"
+	resourceMIMESQL  = "application/sql"
+)
+
+// schemaResources publishes every connection, schema and table as an MCP
+// resource and keeps the published list in step with the schema caches
+type schemaResources struct {
+	server      *server.MCPServer
+	connections *connectionRegistry
+
+	// published maps the URIs of the published resources to a signature of
+	// their content, so that changed resources can be told apart. mcp-go
+	// cannot remove resources, so URIs of dropped objects stay listed and
+	// fail to read.
+	mu        sync.Mutex
+	published map[string]string
+}
+
+// registerSchemaResources publishes the schema of every connection and
+// republishes it whenever a schema cache notices a change
+func registerSchemaResources(server *server.MCPServer, connections *connectionRegistry) {
+	resources := &schemaResources{
+		server:      server,
+		connections: connections,
+		published:   make(map[string]string),
+	}
+
+	// Tables not listed yet, such as tables created since the last sync,
+	// can still be read through the template
+	server.AddResourceTemplate(mcp.NewResourceTemplate(resourceScheme+"{connection}/{schema}/{table}", "Table",
+		mcp.WithTemplateDescription("Columns, keys, indexes and constraints of a table as a CREATE TABLE statement"),
+		mcp.WithTemplateMIMEType(resourceMIMESQL),
+	), resources.read)
+
+	for _, conn := range connections.list() {
+		conn := conn
+		conn.schema.onChange(func() { resources.sync(conn) })
+		go resources.sync(conn)
+	}
+}
+
+// sync publishes the resources of a connection's current schema and tells
+// clients what was added, removed or changed since the last sync
+func (r *schemaResources) sync(conn *namedConnection) {
+	ctx := context.Background()
+	if timeout := conn.config.StatementTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	snapshot, err := conn.schema.get(ctx)
+	if err != nil {
+		log.Printf("Failed to publish the schema of connection %q as resources: %v", conn.config.Name, err)
+		return
+	}
+
+	// The signature of a connection or schema is its list of schemas and
+	// their table counts, or of tables and their column counts. A table resource renders keys,
+	// indexes and constraints a snapshot does not hold, so the signature of
+	// a table is the schema version the snapshot was read at: any schema
+	// change marks every table updated. Snapshots of backends without a
+	// version are told apart by their load time.
+	tableSignature := snapshot.version
+	if tableSignature == "" {
+		tableSignature = snapshot.loadedAt.String()
+	}
+
+	wanted := map[string]string{}
+	resources := map[string]mcp.Resource{}
+	schemas := map[string][]string{}
+	for _, table := range snapshot.tables {
+		uri := tableResourceURI(conn.config.Name, table.Schema, table.TableName)
+		wanted[uri] = tableSignature
+		resources[uri] = mcp.NewResource(uri, table.QualifiedName(),
+			mcp.WithResourceDescription(fmt.Sprintf("Table %s of connection %q", table.QualifiedName(), conn.config.Name)),
+			mcp.WithMIMEType(resourceMIMESQL),
+		)
+		schemas[table.Schema] = append(schemas[table.Schema], fmt.Sprintf("%s\t%d", table.TableName, len(table.Columns)))
+	}
+
+	var schemaCounts []string
+	for schema, tables := range schemas {
+		uri := schemaResourceURI(conn.config.Name, schema)
+		wanted[uri] = strings.Join(tables, "\n")
+		resources[uri] = mcp.NewResource(uri, schema,
+			mcp.WithResourceDescription(fmt.Sprintf("Tables of schema %s of connection %q", schema, conn.config.Name)),
+			mcp.WithMIMEType(resourceMIMEText),
+		)
+		schemaCounts = append(schemaCounts, fmt.Sprintf("%s\t%d", schema, len(tables)))
+	}
+	sort.Strings(schemaCounts)
+
+	uri := connectionResourceURI(conn.config.Name)
+	wanted[uri] = strings.Join(schemaCounts, "\n")
+	resources[uri] = mcp.NewResource(uri, conn.config.Name,
+		mcp.WithResourceDescription(fmt.Sprintf("Schemas of the %s database %q", conn.config.Engine, conn.config.Database)),
+		mcp.WithMIMEType(resourceMIMEText),
+	)
+
+	r.mu.Lock()
+	var listChanged bool
+	var updated []string
+	prefix := connectionResourceURI(conn.config.Name)
+	for uri, signature := range r.published {
+		if uri != prefix && !strings.HasPrefix(uri, prefix+"/") {
+			continue
+		}
+		if _, ok := wanted[uri]; !ok {
+			delete(r.published, uri)
+			listChanged = true
+			updated = append(updated, uri)
+		} else if wanted[uri] != signature {
+			updated = append(updated, uri)
+		}
+	}
+	for uri, signature := range wanted {
+		if _, ok := r.published[uri]; !ok {
+			r.server.AddResource(resources[uri], r.read)
+			listChanged = true
+		}
+		r.published[uri] = signature
+	}
+	r.mu.Unlock()
+
+	if listChanged {
+		transport.NotifyResourceListChanged()
+	}
+	for _, uri := range updated {
+		transport.NotifyResourceUpdated(uri)
+	}
+}
+
+// read returns the content of a schema resource: the schemas of a
+// connection, the tables of a schema or the definition of a table
+func (r *schemaResources) read(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	parts, err := parseResourceURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if parts[0] == "" {
+		return nil, fmt.Errorf("resource URI %q names no connection", uri)
+	}
+	conn, err := r.connections.get(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = transport.RequestContext(ctx)
+	if timeout := conn.config.StatementTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if len(parts) == 3 {
+		schema, err := conn.db.GetTableSchema(ctx, qualifyTableName(parts[1], parts[2]))
+		if err != nil {
+			return nil, contextError(ctx, err)
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: resourceMIMESQL, Text: formatTableDDL(schema, conn.config.Engine)}}, nil
+	}
+
+	snapshot, err := conn.schema.get(ctx)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	var resultText strings.Builder
+	if len(parts) == 1 {
+		schemas := schemaTables(snapshot)
+		names := make([]string, 0, len(schemas))
+		for schema := range schemas {
+			names = append(names, schema)
+		}
+		sort.Strings(names)
+
+		resultText.WriteString(fmt.Sprintf("Connection %q (%s, database %s) has %d schemas:\n\n", conn.config.Name, conn.config.Engine, conn.config.Database, len(names)))
+		resultText.WriteString("SCHEMA\tTABLES\tURI\n")
+		resultText.WriteString("----------\t----------\t----------\n")
+		for _, schema := range names {
+			resultText.WriteString(fmt.Sprintf("%s\t%d\t%s\n", schema, len(schemas[schema]), schemaResourceURI(conn.config.Name, schema)))
+		}
+	} else {
+		tables, ok := schemaTables(snapshot)[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("schema %q of connection %q has no tables", parts[1], conn.config.Name)
+		}
+
+		resultText.WriteString(fmt.Sprintf("Schema %s of connection %q has %d tables:\n\n", parts[1], conn.config.Name, len(tables)))
+		resultText.WriteString("TABLE\tCOLUMNS\tURI\n")
+		resultText.WriteString("----------\t----------\t----------\n")
+		for _, table := range tables {
+			resultText.WriteString(fmt.Sprintf("%s\t%d\t%s\n", table.TableName, len(table.Columns), tableResourceURI(conn.config.Name, table.Schema, table.TableName)))
+		}
+	}
+
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: resourceMIMEText, Text: resultText.String()}}, nil
+}
+
+// schemaTables groups the tables of a snapshot by schema
+func schemaTables(snapshot *schemaSnapshot) map[string][]interfaces.TableSchema {
+	schemas := make(map[string][]interfaces.TableSchema)
+	for _, table := range snapshot.tables {
+		schemas[table.Schema] = append(schemas[table.Schema], table)
+	}
+	return schemas
+}
+
+// parseResourceURI splits a schema resource URI into its unescaped
+// connection, schema and table names, of which the last two are optional
+func parseResourceURI(uri string) ([]string, error) {
+	if !strings.HasPrefix(uri, resourceScheme) {
+		return nil, fmt.Errorf("resource URI %q does not start with %s", uri, resourceScheme)
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(uri, resourceScheme), "/"), "/")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("resource URI %q has more than a connection, schema and table", uri)
+	}
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource URI %q: %w", uri, err)
+		}
+		parts[i] = unescaped
+	}
+
+	return parts, nil
+}
+
+// connectionResourceURI returns the URI of the resource listing the schemas
+// of a connection
+func connectionResourceURI(connection string) string {
+	return resourceScheme + url.PathEscape(connection)
+}
+
+// schemaResourceURI returns the URI of the resource listing the tables of a
+// schema
+func schemaResourceURI(connection string, schema string) string {
+	return connectionResourceURI(connection) + "/" + url.PathEscape(schema)
+}
+
+// tableResourceURI returns the URI of the resource describing a table
+func tableResourceURI(connection string, schema string, table string) string {
+	return schemaResourceURI(connection, schema) + "/" + url.PathEscape(table)
+}
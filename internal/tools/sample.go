@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Limits of the sample_rows tool
+const (
+	defaultSampleRows = 10
+	maxSampleRows     = 100
+
+	// sampleScanRows is the size up to which a table is sampled by sorting
+	// all of its rows in random order
+	sampleScanRows = 100000
+
+	// sampleAttempts bounds the page samples taken of a larger table, each
+	// ten times the size of the one before, until one holds enough rows
+	sampleAttempts = 3
+)
+
+// rowSampler is implemented by backends that can read a random sample of
+// the rows of a table for the sample_rows tool
+type rowSampler interface {
+	sampleRows(ctx context.Context, tableName string, columns []string, count int) (rowSample, error)
+}
+
+// rowSample is a random sample of the rows of a table
+type rowSample struct {
+	// table is the schema-qualified name of the table sampled
+	table string
+
+	result queryResult
+
+	// masked lists the columns whose values were masked
+	masked []maskedColumn
+
+	// short is set when the pages sampled held fewer rows than requested
+	short bool
+}
+
+// jsonRowSample is the document returned for a sample in json format
+type jsonRowSample struct {
+	Table         string         `json:"table"`
+	Columns       []resultColumn `json:"columns"`
+	Rows          [][]any        `json:"rows"`
+	RowCount      int            `json:"row_count"`
+	MaskedColumns []string       `json:"masked_columns,omitempty"`
+	Short         bool           `json:"short,omitempty"`
+}
+
+// formatRowSample renders the sampled rows and the columns masked in them
+func formatRowSample(sample rowSample, format string) (string, error) {
+	if format == formatJSON {
+		data, err := json.MarshalIndent(jsonRowSample{
+			Table:         sample.table,
+			Columns:       resultColumns(sample.result),
+			Rows:          append(make([][]any, 0, len(sample.result.rows)), sample.result.rows...),
+			RowCount:      len(sample.result.rows),
+			MaskedColumns: maskedColumnNames(sample.masked),
+			Short:         sample.short,
+		}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	header, lines, err := renderRows(sample.result, format)
+	if err != nil {
+		return "", err
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Sampled %d rows from table %s:\n\n", len(sample.result.rows), sample.table))
+	resultText.WriteString(header)
+	for _, line := range lines {
+		resultText.WriteString(line)
+	}
+	if len(sample.masked) > 0 {
+		resultText.WriteString("\n")
+		resultText.WriteString(formatMaskedColumns(sample.masked))
+	}
+	if sample.short {
+		resultText.WriteString("\nThe sample is short: the sampled pages of the table held fewer rows than requested.\n")
+	}
+
+	return resultText.String(), nil
+}
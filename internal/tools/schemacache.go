@@ -0,0 +1,368 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultSchemaPollInterval is how often a connection with a cached schema
+// snapshot is checked for schema changes
+const defaultSchemaPollInterval = time.Minute
+
+// schemaVersionTimeout bounds the query checking a connection's schema
+// version
+const schemaVersionTimeout = 10 * time.Second
+
+// schemaSnapshot is the catalog of a database at one point in time
+type schemaSnapshot struct {
+	// tables and views hold the columns of the tables and views; keys,
+	// indexes and constraints are not part of a snapshot
+	tables []interfaces.TableSchema
+	views  []interfaces.TableSchema
+
+	// modules holds the source of the views, procedures and functions,
+	// without their parameters
+	modules []interfaces.ObjectDefinition
+
+	// loadedAt is when the catalog was read
+	loadedAt time.Time
+
+	// version is the schema version read just before the catalog, empty
+	// for backends that do not report one
+	version string
+}
+
+// schemaSnapshotter is implemented by backends that can read the whole
+// catalog in a few queries. Other backends are snapshotted through the
+// interfaces.Database methods, one object at a time.
+type schemaSnapshotter interface {
+	loadSchemaSnapshot(ctx context.Context) (*schemaSnapshot, error)
+}
+
+// schemaVersioner is implemented by backends that can tell cheaply whether
+// their schema changed. The version is an opaque string that changes
+// whenever objects are created, altered or dropped.
+type schemaVersioner interface {
+	schemaVersion(ctx context.Context) (string, error)
+}
+
+// schemaCache holds the schema snapshot of a connection. The snapshot is
+// loaded on first use; once loaded, the connection's schema version is
+// polled and the snapshot dropped when it changes, to be loaded again on
+// next use. Snapshots of backends without a schema version expire at every
+// poll.
+type schemaCache struct {
+	name         string
+	db           interfaces.Database
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	snapshot *schemaSnapshot
+	hits     int64
+	misses   int64
+
+	// loading is closed when the load in progress, if any, finishes. The
+	// catalog is read without holding mu, so callers wanting a snapshot
+	// wait on this channel instead.
+	loading chan struct{}
+
+	// stop is closed to end the poller, which runs once stop is set
+	stop   chan struct{}
+	closed bool
+
+	// listeners are called after the snapshot was dropped because the
+	// schema changed, or refreshed
+	listeners []func()
+}
+
+// newSchemaCache creates the empty schema cache of a connection. A poll
+// interval of 0 disables polling, keeping snapshots until refreshed.
+func newSchemaCache(config connectionConfig, db interfaces.Database) *schemaCache {
+	return &schemaCache{
+		name:         config.Name,
+		db:           db,
+		pollInterval: config.SchemaPollInterval,
+	}
+}
+
+// get returns the cached snapshot, loading it when there is none. A
+// caller arriving while another loads the snapshot waits for that load
+// rather than starting its own, giving up when its context is done.
+func (c *schemaCache) get(ctx context.Context) (*schemaSnapshot, error) {
+	c.mu.Lock()
+	for c.snapshot == nil && c.loading != nil {
+		loading := c.loading
+		c.mu.Unlock()
+		select {
+		case <-loading:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.mu.Lock()
+	}
+
+	if c.snapshot != nil {
+		c.hits++
+		snapshot := c.snapshot
+		c.mu.Unlock()
+		return snapshot, nil
+	}
+
+	c.misses++
+	loading := c.startLoad()
+	c.mu.Unlock()
+
+	return c.load(ctx, "cache miss", loading)
+}
+
+// refresh loads the snapshot again, whether or not the schema changed
+func (c *schemaCache) refresh(ctx context.Context) (*schemaSnapshot, error) {
+	c.mu.Lock()
+	loading := c.startLoad()
+	c.mu.Unlock()
+
+	snapshot, err := c.load(ctx, "refresh", loading)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	listeners := c.listeners
+	c.mu.Unlock()
+	for _, listener := range listeners {
+		listener()
+	}
+	return snapshot, nil
+}
+
+// onChange registers a function called after the snapshot was dropped
+// because the schema changed, or refreshed
+func (c *schemaCache) onChange(listener func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listeners = append(c.listeners, listener)
+}
+
+// close stops the poller. The cache still serves snapshots afterwards but
+// no longer notices schema changes.
+func (c *schemaCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		if c.stop != nil {
+			close(c.stop)
+		}
+	}
+}
+
+// startLoad marks a load as in progress and returns the channel closed
+// when it finishes. c.mu must be held.
+func (c *schemaCache) startLoad() chan struct{} {
+	loading := make(chan struct{})
+	c.loading = loading
+	return loading
+}
+
+// load reads the schema version and catalog without holding c.mu and
+// caches them, starting the poller on the first load. loading is the
+// channel returned by startLoad; it is closed when load returns.
+func (c *schemaCache) load(ctx context.Context, reason string, loading chan struct{}) (*schemaSnapshot, error) {
+	defer func() {
+		c.mu.Lock()
+		// A refresh may have started another load in the meantime
+		if c.loading == loading {
+			c.loading = nil
+		}
+		c.mu.Unlock()
+		close(loading)
+	}()
+
+	started := time.Now()
+
+	var version string
+	if versioner, ok := c.db.(schemaVersioner); ok {
+		var err error
+		if version, err = versioner.schemaVersion(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot, err := loadSchemaSnapshot(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.version = version
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.snapshot = snapshot
+
+	views, routines := countModules(snapshot)
+	log.Printf("Loaded schema of connection %q (%s): %d tables, %d views and %d routines in %v; %d cache hits, %d misses",
+		c.name, reason, len(snapshot.tables), views, routines, time.Since(started).Round(time.Millisecond), c.hits, c.misses)
+
+	if c.stop == nil && !c.closed && c.pollInterval > 0 {
+		c.stop = make(chan struct{})
+		go c.poll(c.stop)
+	}
+
+	return snapshot, nil
+}
+
+// poll checks for schema changes every poll interval until stop is closed
+func (c *schemaCache) poll(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.check()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// check drops the cached snapshot when the schema version changed since it
+// was loaded, or when the backend cannot tell. Listeners are only called
+// for a version change; an expired snapshot is simply loaded again on next
+// use.
+func (c *schemaCache) check() {
+	c.mu.Lock()
+	snapshot := c.snapshot
+	c.mu.Unlock()
+	if snapshot == nil {
+		return
+	}
+
+	reason := "expired"
+	if versioner, ok := c.db.(schemaVersioner); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), schemaVersionTimeout)
+		version, err := versioner.schemaVersion(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to check the schema version of connection %q: %v", c.name, err)
+			return
+		}
+		if version == snapshot.version {
+			return
+		}
+		reason = "changed"
+	}
+
+	c.mu.Lock()
+	// A refresh may have replaced the snapshot in the meantime
+	dropped := c.snapshot == snapshot
+	if dropped {
+		c.snapshot = nil
+		log.Printf("Dropped schema snapshot of connection %q (%s); %d cache hits, %d misses", c.name, reason, c.hits, c.misses)
+	}
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	if dropped && reason == "changed" {
+		for _, listener := range listeners {
+			listener()
+		}
+	}
+}
+
+// loadSchemaSnapshot reads the catalog of db
+func loadSchemaSnapshot(ctx context.Context, db interfaces.Database) (*schemaSnapshot, error) {
+	if snapshotter, ok := db.(schemaSnapshotter); ok {
+		return snapshotter.loadSchemaSnapshot(ctx)
+	}
+
+	schema, err := db.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &schemaSnapshot{tables: schema.Tables, loadedAt: time.Now()}
+
+	views, err := db.GetViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		definition, err := db.GetObjectDefinition(ctx, view)
+		if err != nil {
+			return nil, fmt.Errorf("error getting definition of view %s: %w", view, err)
+		}
+		snapshot.modules = append(snapshot.modules, definition)
+	}
+
+	routines, err := db.GetRoutines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, routine := range routines {
+		// Overloaded functions cannot be looked up by name alone, so a
+		// routine whose source cannot be read is still listed without it
+		definition, err := db.GetObjectDefinition(ctx, qualifyTableName(routine.Schema, routine.Name))
+		if err != nil {
+			definition = interfaces.ObjectDefinition{Schema: routine.Schema, Name: routine.Name, Type: routine.Type, ReturnType: routine.ReturnType}
+		}
+		snapshot.modules = append(snapshot.modules, definition)
+	}
+
+	return snapshot, nil
+}
+
+// registerSchemaRefreshTool registers the tool that reloads the cached
+// schema snapshot of a connection
+func registerSchemaRefreshTool(server *server.MCPServer, connections *connectionRegistry) {
+	refreshSchemaTool := mcp.NewTool("sql_refresh_schema", append([]mcp.ToolOption{
+		mcp.WithDescription("Reload the cached schema of a database used by sql_search_schema. The cache notices most schema " +
+			"changes by itself within a minute; use this right after creating or altering objects"),
+	}, connections.toolOptions()...)...)
+
+	server.AddTool(refreshSchemaTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		started := time.Now()
+		snapshot, err := conn.schema.refresh(queryCtx)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		views, routines := countModules(snapshot)
+		return mcp.NewToolResultText(fmt.Sprintf("Reloaded the schema of connection %q in %v: %d tables, %d views and %d procedures and functions.\n",
+			conn.config.Name, time.Since(started).Round(time.Millisecond), len(snapshot.tables), views, routines)), nil
+	})
+}
+
+// countModules counts the views and the procedures and functions of a
+// snapshot
+func countModules(snapshot *schemaSnapshot) (int, int) {
+	views, routines := 0, 0
+	for _, module := range snapshot.modules {
+		if module.Type == objectView {
+			views++
+		} else {
+			routines++
+		}
+	}
+	return views, routines
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+)
+
+// blockingSnapshotter is a backend whose catalog loads only once release
+// is closed
+type blockingSnapshotter struct {
+	interfaces.Database
+
+	release chan struct{}
+	loads   atomic.Int32
+}
+
+func (b *blockingSnapshotter) loadSchemaSnapshot(ctx context.Context) (*schemaSnapshot, error) {
+	b.loads.Add(1)
+	select {
+	case <-b.release:
+		return &schemaSnapshot{loadedAt: time.Now()}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestSchemaCacheGetWaitsOutsideLock(t *testing.T) {
+	db := &blockingSnapshotter{release: make(chan struct{})}
+	cache := newSchemaCache(connectionConfig{Name: "test", SchemaPollInterval: time.Hour}, db)
+	defer cache.close()
+
+	loaded := make(chan error, 1)
+	go func() {
+		_, err := cache.get(context.Background())
+		loaded <- err
+	}()
+	for db.loads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A caller giving up must not be held by the load in progress
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cache.get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("get during a load returned %v, want the context error", err)
+	}
+	cache.onChange(func() {})
+
+	waited := make(chan error, 1)
+	go func() {
+		_, err := cache.get(context.Background())
+		waited <- err
+	}()
+
+	close(db.release)
+	if err := <-loaded; err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if err := <-waited; err != nil {
+		t.Fatalf("waiting get failed: %v", err)
+	}
+	if loads := db.loads.Load(); loads != 1 {
+		t.Errorf("concurrent gets loaded the catalog %d times, want once", loads)
+	}
+}
+
+func TestSchemaCacheCloseStopsPoller(t *testing.T) {
+	db := &blockingSnapshotter{release: make(chan struct{})}
+	close(db.release)
+	cache := newSchemaCache(connectionConfig{Name: "test", SchemaPollInterval: time.Millisecond}, db)
+
+	if _, err := cache.get(context.Background()); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	cache.mu.Lock()
+	stop := cache.stop
+	cache.mu.Unlock()
+	if stop == nil {
+		t.Fatal("the first load did not start the poller")
+	}
+
+	cache.close()
+	select {
+	case <-stop:
+	default:
+		t.Error("close did not stop the poller")
+	}
+	cache.close()
+}
@@ -0,0 +1,644 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Kinds of objects compared by the schema diff
+const (
+	diffTable      = "table"
+	diffColumn     = "column"
+	diffIndex      = "index"
+	diffForeignKey = "foreign_key"
+)
+
+// Changes found by the schema diff. Objects only in the target schema are
+// added, objects only in the source schema removed.
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// schemaChange is one difference between the source and target schemas
+type schemaChange struct {
+	Change string `json:"change"`
+	Kind   string `json:"kind"`
+	Table  string `json:"table"`
+	Name   string `json:"name,omitempty"`
+
+	// Source and Target describe the object in either schema
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+
+	// table and the column, index or foreign key that changed, as they are
+	// in the source schema, or in the target for added objects
+	table      interfaces.TableSchema
+	column     interfaces.ColumnInfo
+	index      interfaces.IndexInfo
+	foreignKey interfaces.ForeignKeyInfo
+}
+
+// diffSchemas lists the tables, columns, indexes and foreign keys that
+// differ between two schemas. Tables are matched by schema and name, and
+// their objects by name; unnamed foreign keys are matched by definition.
+func diffSchemas(source interfaces.SchemaInfo, target interfaces.SchemaInfo) []schemaChange {
+	targetTables := make(map[string]interfaces.TableSchema, len(target.Tables))
+	for _, table := range target.Tables {
+		targetTables[table.QualifiedName()] = table
+	}
+
+	var changes []schemaChange
+	sourceTables := make(map[string]bool, len(source.Tables))
+	for _, table := range source.Tables {
+		sourceTables[table.QualifiedName()] = true
+		if other, ok := targetTables[table.QualifiedName()]; ok {
+			changes = append(changes, diffTables(table, other)...)
+		} else {
+			changes = append(changes, schemaChange{Change: diffRemoved, Kind: diffTable, Table: table.QualifiedName(), table: table})
+		}
+	}
+	for _, table := range target.Tables {
+		if !sourceTables[table.QualifiedName()] {
+			changes = append(changes, schemaChange{Change: diffAdded, Kind: diffTable, Table: table.QualifiedName(), table: table})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Table < changes[j].Table
+	})
+	return changes
+}
+
+// diffTables lists the columns, indexes and foreign keys that differ
+// between two versions of a table
+func diffTables(source interfaces.TableSchema, target interfaces.TableSchema) []schemaChange {
+	var changes []schemaChange
+	change := func(kind string, name string, sourceText string, targetText string) *schemaChange {
+		entry := schemaChange{Kind: kind, Table: source.QualifiedName(), Name: name, Source: sourceText, Target: targetText, table: source}
+		switch {
+		case sourceText == "":
+			entry.Change, entry.table = diffAdded, target
+		case targetText == "":
+			entry.Change = diffRemoved
+		default:
+			entry.Change = diffChanged
+		}
+		changes = append(changes, entry)
+		return &changes[len(changes)-1]
+	}
+
+	targetColumns := make(map[string]interfaces.ColumnInfo, len(target.Columns))
+	for _, column := range target.Columns {
+		targetColumns[column.Name] = column
+	}
+	sourceColumns := make(map[string]bool, len(source.Columns))
+	for _, column := range source.Columns {
+		sourceColumns[column.Name] = true
+		other, ok := targetColumns[column.Name]
+		switch {
+		case !ok:
+			change(diffColumn, column.Name, describeColumn(column), "").column = column
+		case normalizeTypeName(column.Type) != normalizeTypeName(other.Type) || column.Nullable != other.Nullable:
+			change(diffColumn, column.Name, describeColumn(column), describeColumn(other)).column = column
+		}
+	}
+	for _, column := range target.Columns {
+		if !sourceColumns[column.Name] {
+			change(diffColumn, column.Name, "", describeColumn(column)).column = column
+		}
+	}
+
+	sourceIndexes, targetIndexes := plainIndexes(source), plainIndexes(target)
+	for _, index := range sourceIndexes {
+		description := describeIndex(index)
+		other, ok := findIndex(targetIndexes, index.Name)
+		switch {
+		case !ok:
+			change(diffIndex, index.Name, description, "").index = index
+		case describeIndex(other) != description:
+			change(diffIndex, index.Name, description, describeIndex(other)).index = index
+		}
+	}
+	for _, index := range targetIndexes {
+		if _, ok := findIndex(sourceIndexes, index.Name); !ok {
+			change(diffIndex, index.Name, "", describeIndex(index)).index = index
+		}
+	}
+
+	for _, fk := range source.ForeignKeys {
+		description := describeForeignKey(fk)
+		other, ok := findForeignKey(target.ForeignKeys, fk)
+		switch {
+		case !ok:
+			change(diffForeignKey, fk.Name, description, "").foreignKey = fk
+		case describeForeignKey(other) != description:
+			change(diffForeignKey, fk.Name, description, describeForeignKey(other)).foreignKey = fk
+		}
+	}
+	for _, fk := range target.ForeignKeys {
+		if _, ok := findForeignKey(source.ForeignKeys, fk); !ok {
+			change(diffForeignKey, fk.Name, "", describeForeignKey(fk)).foreignKey = fk
+		}
+	}
+
+	return changes
+}
+
+// plainIndexes returns the indexes of a table other than those backing its
+// primary key and unique constraints
+func plainIndexes(table interfaces.TableSchema) []interfaces.IndexInfo {
+	constraints := make(map[string]bool, len(table.Constraints))
+	for _, constraint := range table.Constraints {
+		constraints[constraint.Name] = true
+	}
+
+	var indexes []interfaces.IndexInfo
+	for _, index := range table.Indexes {
+		if !index.Primary && !constraints[index.Name] {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// findIndex looks an index up by name
+func findIndex(indexes []interfaces.IndexInfo, name string) (interfaces.IndexInfo, bool) {
+	for _, index := range indexes {
+		if index.Name == name {
+			return index, true
+		}
+	}
+	return interfaces.IndexInfo{}, false
+}
+
+// findForeignKey looks a foreign key up by name, or by definition when it
+// has none, as on SQLite
+func findForeignKey(foreignKeys []interfaces.ForeignKeyInfo, fk interfaces.ForeignKeyInfo) (interfaces.ForeignKeyInfo, bool) {
+	for _, other := range foreignKeys {
+		if fk.Name != "" && other.Name == fk.Name {
+			return other, true
+		}
+		if fk.Name == "" && other.Name == "" && describeForeignKey(other) == describeForeignKey(fk) {
+			return other, true
+		}
+	}
+	return interfaces.ForeignKeyInfo{}, false
+}
+
+// normalizeTypeName makes type names that differ only in case or spacing
+// compare equal
+func normalizeTypeName(typeName string) string {
+	return strings.ToLower(strings.Join(strings.Fields(typeName), " "))
+}
+
+// describeColumn summarizes a column's type and nullability
+func describeColumn(column interfaces.ColumnInfo) string {
+	if column.Nullable {
+		return column.Type + " NULL"
+	}
+	return column.Type + " NOT NULL"
+}
+
+// describeIndex summarizes an index's type, uniqueness, columns and filter
+func describeIndex(index interfaces.IndexInfo) string {
+	var description []string
+	if index.Type != "" {
+		description = append(description, strings.ToUpper(index.Type))
+	}
+	if index.Unique {
+		description = append(description, "UNIQUE")
+	}
+	description = append(description, fmt.Sprintf("(%s)", strings.Join(index.Columns, ", ")))
+	if len(index.IncludedColumns) > 0 {
+		description = append(description, fmt.Sprintf("INCLUDE (%s)", strings.Join(index.IncludedColumns, ", ")))
+	}
+	if index.Filter != "" {
+		description = append(description, "WHERE "+index.Filter)
+	}
+	return strings.Join(description, " ")
+}
+
+// describeForeignKey summarizes a foreign key's columns, references and
+// referential actions
+func describeForeignKey(fk interfaces.ForeignKeyInfo) string {
+	description := fmt.Sprintf("(%s) REFERENCES %s (%s)", strings.Join(fk.Columns, ", "), fk.ReferencedTable, strings.Join(fk.ReferencedColumns, ", "))
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		description += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		description += " ON UPDATE " + fk.OnUpdate
+	}
+	return description
+}
+
+// alterScript returns the statements, in engine's dialect, that make the
+// target schema match the source. Foreign keys and indexes are dropped
+// first and created last so that the columns they use can change in
+// between. Changes engine cannot make with ALTER TABLE are left as
+// comments.
+func alterScript(changes []schemaChange, engine string) string {
+	var statements []string
+	add := func(format string, args ...any) {
+		statements = append(statements, fmt.Sprintf(format, args...))
+	}
+
+	// Drop the foreign keys and indexes that are in the way
+	for _, change := range changes {
+		table := quoteTableName(change.table.Schema, change.table.TableName, engine)
+		switch {
+		case change.Kind == diffForeignKey && change.Change != diffRemoved:
+			switch {
+			case engine == engineSQLite || change.foreignKey.Name == "":
+				add("-- Rebuild %s to drop its foreign key %s", table, describeForeignKey(change.foreignKey))
+			case engine == engineMySQL:
+				add("ALTER TABLE %s DROP FOREIGN KEY %s;", table, quoteIdentifier(change.foreignKey.Name, engine))
+			default:
+				add("ALTER TABLE %s DROP CONSTRAINT %s;", table, quoteIdentifier(change.foreignKey.Name, engine))
+			}
+		case change.Kind == diffIndex && change.Change != diffRemoved:
+			switch engine {
+			case engineSQLServer, engineMySQL:
+				add("DROP INDEX %s ON %s;", quoteIdentifier(change.index.Name, engine), table)
+			default:
+				add("DROP INDEX %s;", quoteTableName(change.table.Schema, change.index.Name, engine))
+			}
+		}
+	}
+
+	// Drop and create tables, then add, alter and drop columns
+	for _, change := range changes {
+		if change.Kind != diffTable {
+			continue
+		}
+		if change.Change == diffAdded {
+			add("DROP TABLE %s;", quoteTableName(change.table.Schema, change.table.TableName, engine))
+		} else {
+			add("%s", strings.TrimSpace(formatTableDDL(change.table, engine)))
+		}
+	}
+	for _, change := range changes {
+		if change.Kind != diffColumn {
+			continue
+		}
+		table := quoteTableName(change.table.Schema, change.table.TableName, engine)
+		column := quoteIdentifier(change.column.Name, engine)
+
+		switch change.Change {
+		case diffAdded:
+			add("ALTER TABLE %s DROP COLUMN %s;", table, column)
+		case diffRemoved:
+			if engine == engineSQLServer {
+				add("ALTER TABLE %s ADD %s;", table, columnDefinition(change.column, engine))
+			} else {
+				add("ALTER TABLE %s ADD COLUMN %s;", table, columnDefinition(change.column, engine))
+			}
+		case diffChanged:
+			nullability := " NOT NULL"
+			if change.column.Nullable {
+				nullability = " NULL"
+			}
+			switch engine {
+			case engineSQLServer:
+				add("ALTER TABLE %s ALTER COLUMN %s %s%s;", table, column, change.column.Type, nullability)
+			case engineMySQL:
+				add("ALTER TABLE %s MODIFY COLUMN %s;", table, columnDefinition(change.column, engine))
+			case enginePostgres:
+				add("ALTER TABLE %s ALTER COLUMN %s TYPE %s;", table, column, change.column.Type)
+				if change.column.Nullable {
+					add("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;", table, column)
+				} else {
+					add("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;", table, column)
+				}
+			default:
+				add("-- Rebuild %s to change column %s from %s to %s", table, column, change.Target, change.Source)
+			}
+		}
+	}
+
+	// Create the indexes and foreign keys of the source
+	for _, change := range changes {
+		table := quoteTableName(change.table.Schema, change.table.TableName, engine)
+		switch {
+		case change.Kind == diffIndex && change.Change != diffAdded:
+			add("%s", indexDefinition(change.table, change.index, engine))
+		case change.Kind == diffForeignKey && change.Change != diffAdded:
+			definition := foreignKeyDefinition(change.table, change.foreignKey, engine)
+			if engine == engineSQLite {
+				add("-- Rebuild %s to add %s", table, definition)
+			} else {
+				add("ALTER TABLE %s ADD %s%s;", table, constraintPrefix(change.foreignKey.Name, engine), definition)
+			}
+		}
+	}
+
+	return strings.Join(statements, "\n") + "\n"
+}
+
+// formatSchemaDiff renders the changes found between two schemas,
+// followed by the ALTER script when script is set
+func formatSchemaDiff(changes []schemaChange, sourceName string, targetName string, tables int, script string, format string) (string, error) {
+	if format == formatJSON {
+		document := struct {
+			Source  string         `json:"source"`
+			Target  string         `json:"target"`
+			Changes []schemaChange `json:"changes"`
+			Script  string         `json:"script,omitempty"`
+		}{
+			Source:  sourceName,
+			Target:  targetName,
+			Changes: changes,
+			Script:  script,
+		}
+		if document.Changes == nil {
+			document.Changes = []schemaChange{}
+		}
+
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if len(changes) == 0 {
+		return fmt.Sprintf("The schema of %s matches %s: %d tables compared.\n", targetName, sourceName, tables), nil
+	}
+
+	added, removed := 0, 0
+	changed := make(map[string]bool)
+	for _, change := range changes {
+		switch {
+		case change.Kind != diffTable:
+			changed[change.Table] = true
+		case change.Change == diffAdded:
+			added++
+		default:
+			removed++
+		}
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Compared %s (target) with %s (source): %d tables added, %d removed and %d changed in the target.\n\n",
+		targetName, sourceName, added, removed, len(changed)))
+	resultText.WriteString("CHANGE\tKIND\tTABLE\tNAME\tSOURCE\tTARGET\n")
+	resultText.WriteString("----------\t----------\t----------\t----------\t----------\t----------\n")
+	for _, change := range changes {
+		resultText.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\n", change.Change, change.Kind, change.Table, change.Name, change.Source, change.Target))
+	}
+
+	if script != "" {
+		resultText.WriteString(fmt.Sprintf("\nScript bringing %s in line with %s:\n\n%s", targetName, sourceName, script))
+	}
+	return resultText.String(), nil
+}
+
+// snapshotDirVariable names the directory schema snapshots are read from
+// and saved to. Snapshots are disabled when it is not set.
+const snapshotDirVariable = "SQL_SCHEMA_SNAPSHOT_DIR"
+
+// snapshotPath resolves the name of a schema snapshot to a path inside the
+// snapshot directory. Names are relative to the directory and may not leave
+// it, neither through ".." nor through symbolic links.
+func snapshotPath(dir, name string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("schema snapshots are disabled: set %s to the directory holding them", snapshotDirVariable)
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid snapshot %q: snapshots are named by a path relative to the snapshot directory, without ..", name)
+	}
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("error opening the snapshot directory: %w", err)
+	}
+
+	// A snapshot not saved yet is resolved through its directory
+	path, err := filepath.EvalSymlinks(filepath.Join(root, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		var parent string
+		if parent, err = filepath.EvalSymlinks(filepath.Dir(filepath.Join(root, name))); err == nil {
+			path = filepath.Join(parent, filepath.Base(name))
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("error resolving snapshot %s: %w", name, err)
+	}
+
+	if relative, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(relative) {
+		return "", fmt.Errorf("invalid snapshot %q: it resolves to a file outside the snapshot directory", name)
+	}
+	return path, nil
+}
+
+// readSchemaSnapshot reads a schema saved as the JSON encoding of
+// interfaces.SchemaInfo
+func readSchemaSnapshot(dir, name string) (interfaces.SchemaInfo, error) {
+	path, err := snapshotPath(dir, name)
+	if err != nil {
+		return interfaces.SchemaInfo{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error reading schema snapshot %s: %w", name, errors.Unwrap(err))
+	}
+
+	var schema interfaces.SchemaInfo
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error decoding schema snapshot %s: %w", name, err)
+	}
+	return schema, nil
+}
+
+// writeSchemaSnapshot saves a schema as the JSON encoding of
+// interfaces.SchemaInfo, readable only by the server's user. An existing
+// snapshot is only replaced when overwrite is set.
+func writeSchemaSnapshot(dir, name string, schema interfaces.SchemaInfo, overwrite bool) error {
+	path, err := snapshotPath(dir, name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding schema snapshot: %w", err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("schema snapshot %s already exists: set overwrite_snapshot to replace it", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing schema snapshot %s: %w", name, errors.Unwrap(err))
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing schema snapshot %s: %w", name, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing schema snapshot %s: %w", name, err)
+	}
+	return nil
+}
+
+// registerSchemaDiffTool registers the tool that compares the schema of a
+// connection with another connection or a snapshot saved in snapshotDir
+func registerSchemaDiffTool(server *server.MCPServer, connections *connectionRegistry, snapshotDir string) {
+	diffSchemaTool := mcp.NewTool("sql_diff_schema",
+		mcp.WithDescription("Compare the tables, columns, types, nullability, indexes and foreign keys of a target database with a "+
+			"source database or a saved schema snapshot, for example to see how staging drifted from production. Optionally "+
+			"returns the script that would bring the target in line with the source"),
+		mcp.WithString("source",
+			mcp.Description("Name of the connection to compare against (see sql_list_connections). Either source or source_snapshot is required"),
+		),
+		mcp.WithString("source_snapshot",
+			mcp.Description("Name of a schema snapshot to compare against instead of a connection, as saved by save_snapshot"),
+		),
+		mcp.WithString("target",
+			mcp.Description(fmt.Sprintf("Name of the connection to compare. Defaults to %q", connections.defaultName)),
+		),
+		mcp.WithBoolean("script",
+			mcp.Description("Also return the ALTER script, in the target's dialect, that makes the target match the source"),
+		),
+		mcp.WithString("save_snapshot",
+			mcp.Description("Name of a file in the snapshot directory to save the target's schema to as JSON, for later comparisons"),
+		),
+		mcp.WithBoolean("overwrite_snapshot",
+			mcp.Description("Replace the snapshot named by save_snapshot if it already exists"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: text (tab-separated, default) or json"),
+			mcp.Enum(formatText, formatJSON),
+		),
+	)
+
+	server.AddTool(diffSchemaTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sourceConnection, _ := request.Params.Arguments["source"].(string)
+		sourceSnapshot, _ := request.Params.Arguments["source_snapshot"].(string)
+		targetConnection, _ := request.Params.Arguments["target"].(string)
+		savePath, _ := request.Params.Arguments["save_snapshot"].(string)
+		overwrite, _ := request.Params.Arguments["overwrite_snapshot"].(bool)
+		withScript, _ := request.Params.Arguments["script"].(bool)
+
+		sourceConnection, sourceSnapshot = strings.TrimSpace(sourceConnection), strings.TrimSpace(sourceSnapshot)
+		savePath = strings.TrimSpace(savePath)
+		if sourceConnection != "" && sourceSnapshot != "" {
+			return mcp.NewToolResultError("source and source_snapshot cannot be combined"), nil
+		}
+		if sourceConnection == "" && sourceSnapshot == "" && savePath == "" {
+			return mcp.NewToolResultError("either source or source_snapshot is required"), nil
+		}
+
+		// Snapshot names are checked before any schema is read
+		for _, name := range []string{sourceSnapshot, savePath} {
+			if name == "" {
+				continue
+			}
+			if _, err := snapshotPath(snapshotDir, name); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		format, err := parseResultFormat(request.Params.Arguments["format"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if format != formatText && format != formatJSON {
+			return mcp.NewToolResultError("format must be text or json"), nil
+		}
+
+		target, err := connections.get(strings.TrimSpace(targetConnection))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// readSchema reads the schema of a connection within its own
+		// statement timeout
+		readSchema := func(conn *namedConnection) (interfaces.SchemaInfo, error) {
+			queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+			if err != nil {
+				return interfaces.SchemaInfo{}, err
+			}
+			defer cancel()
+
+			schema, err := conn.db.GetSchema(queryCtx)
+			if err != nil {
+				return interfaces.SchemaInfo{}, contextError(queryCtx, fmt.Errorf("error reading the schema of connection %q: %w", conn.config.Name, err))
+			}
+			return schema, nil
+		}
+
+		targetSchema, err := readSchema(target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		targetName := fmt.Sprintf("connection %q", target.config.Name)
+
+		var saved string
+		if savePath != "" {
+			if err := writeSchemaSnapshot(snapshotDir, savePath, targetSchema, overwrite); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			saved = fmt.Sprintf("Saved the schema of %s to %s: %d tables.\n", targetName, savePath, len(targetSchema.Tables))
+			if sourceConnection == "" && sourceSnapshot == "" {
+				return mcp.NewToolResultText(saved), nil
+			}
+		}
+
+		var (
+			sourceSchema interfaces.SchemaInfo
+			sourceName   string
+		)
+		if sourceSnapshot != "" {
+			if sourceSchema, err = readSchemaSnapshot(snapshotDir, sourceSnapshot); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sourceName = "snapshot " + sourceSnapshot
+		} else {
+			source, err := connections.get(sourceConnection)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if sourceSchema, err = readSchema(source); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sourceName = fmt.Sprintf("connection %q", source.config.Name)
+		}
+
+		changes := diffSchemas(sourceSchema, targetSchema)
+
+		var script string
+		if withScript && len(changes) > 0 {
+			script = alterScript(changes, target.config.Engine)
+		}
+
+		output, err := formatSchemaDiff(changes, sourceName, targetName, len(sourceSchema.Tables), script, format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if format == formatText {
+			output = saved + output
+		}
+
+		return mcp.NewToolResultText(output), nil
+	})
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+)
+
+func TestSnapshotPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "prod"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(os.TempDir(), filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir   string
+		name  string
+		valid bool
+	}{
+		{dir, "orders.json", true},
+		{dir, "prod/orders.json", true},
+		{dir, "prod/../orders.json", true},
+		{dir, "../orders.json", false},
+		{dir, "prod/../../orders.json", false},
+		{dir, "/etc/passwd", false},
+		{dir, "", false},
+		{dir, "escape/orders.json", false},
+		{dir, "missing/orders.json", false},
+		{"", "orders.json", false},
+	}
+
+	for _, test := range tests {
+		_, err := snapshotPath(test.dir, test.name)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("snapshotPath(%q, %q) returned %v, want valid %v", test.dir, test.name, err, test.valid)
+		}
+	}
+}
+
+func TestWriteSchemaSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	schema := interfaces.SchemaInfo{DatabaseName: "Orders", Tables: []interfaces.TableSchema{{Schema: "dbo", TableName: "Customers"}}}
+
+	if err := writeSchemaSnapshot(dir, "orders.json", schema, false); err != nil {
+		t.Fatalf("writeSchemaSnapshot failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "orders.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("snapshot saved with mode %v, want 0600", mode)
+	}
+
+	if err := writeSchemaSnapshot(dir, "orders.json", schema, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("writeSchemaSnapshot replaced an existing snapshot: %v", err)
+	}
+	schema.DatabaseName = "Orders2"
+	if err := writeSchemaSnapshot(dir, "orders.json", schema, true); err != nil {
+		t.Fatalf("writeSchemaSnapshot with overwrite failed: %v", err)
+	}
+
+	read, err := readSchemaSnapshot(dir, "orders.json")
+	if err != nil {
+		t.Fatalf("readSchemaSnapshot failed: %v", err)
+	}
+	if read.DatabaseName != "Orders2" || len(read.Tables) != 1 || read.Tables[0].TableName != "Customers" {
+		t.Errorf("readSchemaSnapshot returned %+v", read)
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	column := func(name, typeName string, nullable bool) interfaces.ColumnInfo {
+		return interfaces.ColumnInfo{Name: name, Type: typeName, Nullable: nullable}
+	}
+	source := interfaces.SchemaInfo{Tables: []interfaces.TableSchema{
+		{Schema: "dbo", TableName: "Customers", Columns: []interfaces.ColumnInfo{
+			column("Id", "int", false),
+			column("Name", "nvarchar(200)", false),
+			column("Email", "nvarchar(320)", true),
+		}, Indexes: []interfaces.IndexInfo{{Name: "IX_Customers_Name", Type: "NONCLUSTERED", Columns: []string{"Name"}}}},
+		{Schema: "dbo", TableName: "Orders", Columns: []interfaces.ColumnInfo{column("Id", "int", false)}},
+	}}
+	target := interfaces.SchemaInfo{Tables: []interfaces.TableSchema{
+		{Schema: "dbo", TableName: "Customers", Columns: []interfaces.ColumnInfo{
+			column("Id", "int", false),
+			column("Name", "NVARCHAR(100)", true),
+			column("Legacy", "int", true),
+		}},
+		{Schema: "dbo", TableName: "Scratch", Columns: []interfaces.ColumnInfo{column("Id", "int", false)}},
+	}}
+
+	changes := diffSchemas(source, target)
+	var got []string
+	for _, change := range changes {
+		got = append(got, strings.TrimSpace(change.Change+" "+change.Kind+" "+change.Table+" "+change.Name))
+	}
+	for _, want := range []string{
+		"removed table dbo.Orders",
+		"added table dbo.Scratch",
+		"changed column dbo.Customers Name",
+		"removed column dbo.Customers Email",
+		"added column dbo.Customers Legacy",
+		"removed index dbo.Customers IX_Customers_Name",
+	} {
+		if !slices.Contains(got, want) {
+			t.Errorf("diffSchemas is missing %q in %q", want, got)
+		}
+	}
+	if len(changes) != 6 {
+		t.Errorf("diffSchemas returned %d changes, want 6: %q", len(changes), got)
+	}
+	if changes := diffSchemas(source, source); len(changes) != 0 {
+		t.Errorf("diffSchemas of a schema with itself returned %d changes", len(changes))
+	}
+
+	script := alterScript(changes, engineSQLServer)
+	for _, want := range []string{
+		"DROP TABLE [dbo].[Scratch];",
+		"CREATE TABLE [dbo].[Orders]",
+		"ALTER TABLE [dbo].[Customers] ALTER COLUMN [Name] nvarchar(200) NOT NULL;",
+		"ALTER TABLE [dbo].[Customers] ADD [Email] nvarchar(320) NULL;",
+		"ALTER TABLE [dbo].[Customers] DROP COLUMN [Legacy];",
+		"CREATE INDEX [IX_Customers_Name] ON [dbo].[Customers] ([Name]);",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("alterScript is missing %q in:\n%s", want, script)
+		}
+	}
+
+	script = alterScript(changes, engineSQLite)
+	if !strings.Contains(script, `-- Rebuild "dbo"."Customers" to change column "Name"`) {
+		t.Errorf("the SQLite script alters a column in place:\n%s", script)
+	}
+}
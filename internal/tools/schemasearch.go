@@ -0,0 +1,420 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Limits of the schema search tool
+const (
+	defaultSearchResults = 50
+	maxSearchResults     = 500
+
+	// searchContextLength is the number of characters of a matching
+	// definition line shown with a match
+	searchContextLength = 160
+)
+
+// Kinds of objects the schema search tool finds
+const (
+	searchKindTable     = "table"
+	searchKindView      = "view"
+	searchKindColumn    = "column"
+	searchKindProcedure = "procedure"
+	searchKindFunction  = "function"
+)
+
+// searchKinds lists the kinds of objects in the order matches of equal
+// quality are ranked
+var searchKinds = []string{searchKindTable, searchKindView, searchKindColumn, searchKindProcedure, searchKindFunction}
+
+// How a name or definition matched a search pattern, from best to worst
+const (
+	matchExact      = "exact"
+	matchPattern    = "pattern"
+	matchPrefix     = "prefix"
+	matchContains   = "contains"
+	matchDefinition = "definition"
+)
+
+// matchScores ranks the match qualities
+var matchScores = map[string]int{
+	matchExact:      100,
+	matchPattern:    80,
+	matchPrefix:     70,
+	matchContains:   50,
+	matchDefinition: 20,
+}
+
+// normalizedExactScore ranks names equal to the pattern once case and
+// underscores are ignored, such as CustomerID for customer_id, just below
+// exact matches
+const normalizedExactScore = 90
+
+// schemaPattern is a compiled search pattern. Patterns containing * or %
+// match whole names, with those characters standing for any characters
+// and ? for one; other patterns match any part of a name. Patterns with a
+// dot are matched against schema-qualified names.
+type schemaPattern struct {
+	text       string
+	normalized string
+	qualified  bool
+
+	// glob matches whole names and lines matches definition lines; both are
+	// nil for plain patterns
+	glob  *regexp.Regexp
+	lines *regexp.Regexp
+}
+
+// newSchemaPattern compiles a search pattern
+func newSchemaPattern(pattern string) (*schemaPattern, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+
+	compiled := &schemaPattern{
+		text:       strings.ToLower(pattern),
+		normalized: normalizeSearchName(pattern),
+		qualified:  strings.Contains(pattern, "."),
+	}
+
+	if strings.ContainsAny(pattern, "*%?") {
+		var expression strings.Builder
+		for _, r := range pattern {
+			switch r {
+			case '*', '%':
+				expression.WriteString(".*")
+			case '?':
+				expression.WriteString(".")
+			default:
+				expression.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		compiled.glob = regexp.MustCompile("(?i)^" + expression.String() + "$")
+		compiled.lines = regexp.MustCompile("(?i)" + expression.String())
+	}
+
+	return compiled, nil
+}
+
+// normalizeSearchName lowercases a name and drops its underscores, so that
+// customer_id and CustomerID compare equal
+func normalizeSearchName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "")
+}
+
+// matchName returns how a name matched the pattern, or an empty string.
+// qualifiedName is used instead of name for patterns with a dot.
+func (p *schemaPattern) matchName(name string, qualifiedName string) (string, int) {
+	if p.qualified {
+		name = qualifiedName
+	}
+
+	if p.glob != nil {
+		if p.glob.MatchString(name) {
+			return matchPattern, matchScores[matchPattern]
+		}
+		return "", 0
+	}
+
+	lower := strings.ToLower(name)
+	normalized := normalizeSearchName(name)
+	switch {
+	case lower == p.text:
+		return matchExact, matchScores[matchExact]
+	case normalized == p.normalized:
+		return matchExact, normalizedExactScore
+	case strings.HasPrefix(lower, p.text) || strings.HasPrefix(normalized, p.normalized):
+		return matchPrefix, matchScores[matchPrefix]
+	case strings.Contains(lower, p.text) || strings.Contains(normalized, p.normalized):
+		return matchContains, matchScores[matchContains]
+	}
+	return "", 0
+}
+
+// matchDefinition returns the first line of a definition that contains the
+// pattern, and whether there is one
+func (p *schemaPattern) matchDefinition(definition string) (string, bool) {
+	for _, line := range strings.Split(definition, "\n") {
+		var found bool
+		if p.lines != nil {
+			found = p.lines.MatchString(line)
+		} else {
+			found = strings.Contains(strings.ToLower(line), p.text)
+		}
+		if found {
+			return abbreviate(line, searchContextLength), true
+		}
+	}
+	return "", false
+}
+
+// schemaMatch is one object found by the schema search tool
+type schemaMatch struct {
+	kind string
+
+	// name is the schema-qualified name of the object, or of the table and
+	// column for columns
+	name string
+
+	// dataType is the type of a column or the return type of a function
+	dataType string
+
+	// match is the match quality and score ranks it
+	match string
+	score int
+
+	// context is the matching line of a definition
+	context string
+}
+
+// searchSchema returns the objects of a snapshot matching the pattern, best
+// matches first. kinds limits the search to some kinds of objects.
+func searchSchema(snapshot *schemaSnapshot, pattern *schemaPattern, kinds map[string]bool) []schemaMatch {
+	var matches []schemaMatch
+
+	searchTables := func(kind string, tables []interfaces.TableSchema) {
+		for _, table := range tables {
+			qualifiedName := table.QualifiedName()
+			if kinds[kind] {
+				if match, score := pattern.matchName(table.TableName, qualifiedName); match != "" {
+					matches = append(matches, schemaMatch{kind: kind, name: qualifiedName, match: match, score: score})
+				}
+			}
+
+			if kinds[searchKindColumn] {
+				for _, column := range table.Columns {
+					columnName := qualifiedName + "." + column.Name
+					if match, score := pattern.matchName(column.Name, columnName); match != "" {
+						matches = append(matches, schemaMatch{kind: searchKindColumn, name: columnName, dataType: column.Type, match: match, score: score})
+					}
+				}
+			}
+		}
+	}
+	searchTables(searchKindTable, snapshot.tables)
+	searchTables(searchKindView, snapshot.views)
+
+	// Views are matched by name above when the snapshot has their columns
+	viewsByName := len(snapshot.views) > 0
+	for _, module := range snapshot.modules {
+		kind := strings.ToLower(module.Type)
+		if !kinds[kind] {
+			continue
+		}
+
+		qualifiedName := qualifyTableName(module.Schema, module.Name)
+		match, score := pattern.matchName(module.Name, qualifiedName)
+		if match != "" && kind == searchKindView && viewsByName {
+			continue
+		}
+
+		var context string
+		if match == "" {
+			var ok bool
+			if context, ok = pattern.matchDefinition(module.Definition); !ok {
+				continue
+			}
+			match, score = matchDefinition, matchScores[matchDefinition]
+		}
+
+		matches = append(matches, schemaMatch{kind: kind, name: qualifiedName, dataType: module.ReturnType, match: match, score: score, context: context})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		orderA, _ := matchKindOrder(a.kind)
+		orderB, _ := matchKindOrder(b.kind)
+		if orderA != orderB {
+			return orderA < orderB
+		}
+		if len(a.name) != len(b.name) {
+			return len(a.name) < len(b.name)
+		}
+		return a.name < b.name
+	})
+
+	return matches
+}
+
+// jsonSchemaMatch is one match in the json output of the search tool
+type jsonSchemaMatch struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Match   string `json:"match"`
+	Context string `json:"context,omitempty"`
+}
+
+// formatSchemaMatches renders the best limit matches of a search
+func formatSchemaMatches(pattern string, matches []schemaMatch, limit int, snapshot *schemaSnapshot, format string) (string, error) {
+	shown := matches
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	if format == formatJSON {
+		document := struct {
+			Pattern      string            `json:"pattern"`
+			Matches      []jsonSchemaMatch `json:"matches"`
+			MatchCount   int               `json:"match_count"`
+			Truncated    bool              `json:"truncated"`
+			SnapshotTime string            `json:"snapshot_time"`
+		}{
+			Pattern:      pattern,
+			Matches:      make([]jsonSchemaMatch, 0, len(shown)),
+			MatchCount:   len(matches),
+			Truncated:    len(shown) < len(matches),
+			SnapshotTime: snapshot.loadedAt.UTC().Format(time.RFC3339),
+		}
+		for _, match := range shown {
+			document.Matches = append(document.Matches, jsonSchemaMatch{
+				Kind:    match.kind,
+				Name:    match.name,
+				Type:    match.dataType,
+				Match:   match.match,
+				Context: match.context,
+			})
+		}
+
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding results as JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if len(matches) == 0 {
+		return fmt.Sprintf("No tables, columns, views or routines match %q.\n", pattern), nil
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Found %d matches for %q", len(matches), pattern))
+	if len(shown) < len(matches) {
+		resultText.WriteString(fmt.Sprintf(", showing the best %d", len(shown)))
+	}
+	resultText.WriteString(":\n\n")
+
+	resultText.WriteString("KIND\tNAME\tTYPE\tMATCH\tCONTEXT\n")
+	resultText.WriteString("----------\t----------\t----------\t----------\t----------\n")
+	for _, match := range shown {
+		resultText.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", match.kind, match.name, match.dataType, match.match, match.context))
+	}
+
+	resultText.WriteString(fmt.Sprintf("\nSearched the schema as read at %s.\n", snapshot.loadedAt.UTC().Format(time.RFC3339)))
+	return resultText.String(), nil
+}
+
+// registerSchemaSearchTool registers the tool that searches the cached
+// schema snapshot of a connection
+func registerSchemaSearchTool(server *server.MCPServer, connections *connectionRegistry) {
+	searchSchemaTool := mcp.NewTool("sql_search_schema", append([]mcp.ToolOption{
+		mcp.WithDescription("Search the tables, columns (with their types), views, procedures and functions of a database by name, " +
+			"and the source of views, procedures and functions, ranked by how well they match. Use this to find where a " +
+			"column or table lives instead of listing every table. The schema is cached; see sql_refresh_schema"),
+		mcp.WithString("pattern",
+			mcp.Required(),
+			mcp.Description("Text to look for, such as customer_id. Case and underscores are ignored. Use * or % for any characters "+
+				"and ? for one to match whole names, and schema.name to match qualified names"),
+		),
+		mcp.WithArray("kinds",
+			mcp.Description("Kinds of objects to search: table, view, column, procedure, function. Defaults to all"),
+			mcp.Items(map[string]any{"type": "string", "enum": searchKinds}),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description(fmt.Sprintf("Maximum number of matches to return (1 to %d, default %d)", maxSearchResults, defaultSearchResults)),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: text (tab-separated, default) or json"),
+			mcp.Enum(formatText, formatJSON),
+		),
+	}, connections.toolOptions()...)...)
+
+	server.AddTool(searchSchemaTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		conn, err := connections.fromRequest(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		text, _ := request.Params.Arguments["pattern"].(string)
+		pattern, err := newSchemaPattern(text)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		kinds := make(map[string]bool, len(searchKinds))
+		if value, ok := request.Params.Arguments["kinds"].([]any); ok && len(value) > 0 {
+			for _, item := range value {
+				kind, _ := item.(string)
+				kind = strings.ToLower(strings.TrimSpace(kind))
+				if _, known := matchKindOrder(kind); !known {
+					return mcp.NewToolResultError(fmt.Sprintf("unknown kind %q, expected one of: %s", item, strings.Join(searchKinds, ", "))), nil
+				}
+				kinds[kind] = true
+			}
+		} else {
+			for _, kind := range searchKinds {
+				kinds[kind] = true
+			}
+		}
+
+		limit := defaultSearchResults
+		if value, ok := request.Params.Arguments["max_results"].(float64); ok {
+			if value < 1 || value > maxSearchResults {
+				return mcp.NewToolResultError(fmt.Sprintf("max_results must be between 1 and %d", maxSearchResults)), nil
+			}
+			limit = int(value)
+		}
+
+		format, err := parseResultFormat(request.Params.Arguments["format"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if format != formatText && format != formatJSON {
+			return mcp.NewToolResultError("format must be text or json"), nil
+		}
+
+		queryCtx, cancel, err := statementContext(ctx, conn.config, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer cancel()
+
+		snapshot, err := conn.schema.get(queryCtx)
+		if err != nil {
+			return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+		}
+
+		output, err := formatSchemaMatches(strings.TrimSpace(text), searchSchema(snapshot, pattern, kinds), limit, snapshot, format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	})
+}
+
+// matchKindOrder returns the rank of a kind of object among searchKinds,
+// and whether it is one
+func matchKindOrder(kind string) (int, bool) {
+	for i, known := range searchKinds {
+		if kind == known {
+			return i, true
+		}
+	}
+	return 0, false
+}
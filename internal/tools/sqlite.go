@@ -0,0 +1,811 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteImpl implements the interfaces.Database interface for SQLite files
+type sqliteImpl struct {
+	config connectionConfig
+	db     *sql.DB
+}
+
+// Connect opens the database file
+func (s *sqliteImpl) Connect() error {
+	var err error
+	s.db, err = createSQLiteConnection(s.config)
+	return err
+}
+
+// Disconnect closes the database file
+func (s *sqliteImpl) Disconnect() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Query executes a query and returns the rows of its first result set
+func (s *sqliteImpl) Query(ctx context.Context, query string, params ...any) ([]map[string]any, error) {
+	return queryDatabase(ctx, s.db, s.config, convertSQLiteValue, query, params...)
+}
+
+// QueryResultSets executes a query and returns the rows of every result set
+func (s *sqliteImpl) QueryResultSets(ctx context.Context, query string, params ...any) ([][]map[string]any, error) {
+	return queryDatabaseResultSets(ctx, s.db, s.config, convertSQLiteValue, query, params...)
+}
+
+// Execute runs a query that doesn't return results (INSERT, UPDATE, DELETE)
+func (s *sqliteImpl) Execute(ctx context.Context, query string, params ...any) error {
+	if err := checkWritable(s.config); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, params...); err != nil {
+		return fmt.Errorf("error executing statement: %w", err)
+	}
+
+	return nil
+}
+
+// queryPage executes a query and returns at most limit rows of its result
+// sets after skipping offset rows
+func (s *sqliteImpl) queryPage(ctx context.Context, query string, offset int, limit int, params ...any) ([]queryResult, error) {
+	return queryDatabasePage(ctx, s.db, s.config, convertSQLiteValue, query, offset, limit, params...)
+}
+
+// executeStatement runs a write statement in a transaction, committing it
+// only when commit is set, and returns the number of rows affected
+func (s *sqliteImpl) executeStatement(ctx context.Context, statement string, commit bool) (int64, error) {
+	return executeInTransaction(ctx, s.db, s.config, statement, commit)
+}
+
+// GetSchema returns database schema information
+func (s *sqliteImpl) GetSchema(ctx context.Context) (interfaces.SchemaInfo, error) {
+	tables, err := s.getDBTables(ctx)
+	if err != nil {
+		return interfaces.SchemaInfo{}, fmt.Errorf("error getting tables: %w", err)
+	}
+
+	result := interfaces.SchemaInfo{
+		DatabaseName: s.config.Database,
+		Tables:       make([]interfaces.TableSchema, 0, len(tables)),
+	}
+
+	// Collect schema information for each table
+	for _, tableName := range tables {
+		tableSchema, err := s.GetTableSchema(ctx, tableName)
+		if err != nil {
+			return interfaces.SchemaInfo{}, fmt.Errorf("error getting schema for table %s: %w", tableName, err)
+		}
+		result.Tables = append(result.Tables, tableSchema)
+	}
+
+	return result, nil
+}
+
+// GetTables returns all table names
+func (s *sqliteImpl) GetTables(ctx context.Context) ([]string, error) {
+	return s.getDBTables(ctx)
+}
+
+// GetTableSchema returns column information for a specific table.
+// tableName may be qualified with an attached database name ("aux.items").
+func (s *sqliteImpl) GetTableSchema(ctx context.Context, tableName string) (interfaces.TableSchema, error) {
+	schemaName, relName := splitTableName(tableName)
+	if schemaName == "" {
+		schemaName = "main"
+	}
+
+	// pk is the 1-based position of the column in the primary key, 0 otherwise
+	query := `
+		SELECT name, type, "notnull", dflt_value, pk
+		FROM pragma_table_info(?, ?)
+		ORDER BY cid
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, relName, schemaName)
+	if err != nil {
+		return interfaces.TableSchema{}, fmt.Errorf("error getting table schema: %w", err)
+	}
+	defer rows.Close()
+
+	result := interfaces.TableSchema{
+		Schema:    schemaName,
+		TableName: relName,
+	}
+
+	// Primary key columns by their position in the key
+	keyColumns := make(map[int]string)
+
+	for rows.Next() {
+		var (
+			name, columnType string
+			notNull, pk      int
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return interfaces.TableSchema{}, fmt.Errorf("error scanning column: %w", err)
+		}
+		if pk > 0 {
+			keyColumns[pk] = name
+		}
+
+		columnInfo := interfaces.ColumnInfo{
+			Name:         name,
+			Type:         columnType,
+			Nullable:     notNull == 0,
+			IsPrimaryKey: pk > 0,
+		}
+
+		// Handle default value if present
+		if defaultValue.Valid {
+			columnInfo.DefaultValue = defaultValue.String
+		}
+
+		result.Columns = append(result.Columns, columnInfo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return interfaces.TableSchema{}, fmt.Errorf("error iterating columns: %w", err)
+	}
+
+	if len(result.Columns) == 0 {
+		return interfaces.TableSchema{}, fmt.Errorf("table %s not found", tableName)
+	}
+
+	if len(keyColumns) > 0 {
+		result.PrimaryKey = &interfaces.KeyInfo{}
+		for i := 1; i <= len(keyColumns); i++ {
+			result.PrimaryKey.Columns = append(result.PrimaryKey.Columns, keyColumns[i])
+		}
+
+		// SQLite lets primary key columns hold NULL, except the INTEGER
+		// PRIMARY KEY that aliases the rowid and the keys of WITHOUT ROWID
+		// tables
+		var withoutRowid bool
+		err := s.db.QueryRowContext(ctx, `SELECT wr FROM pragma_table_list(?) WHERE schema = ?`, relName, schemaName).Scan(&withoutRowid)
+		if err != nil && err != sql.ErrNoRows {
+			return interfaces.TableSchema{}, fmt.Errorf("error getting table type: %w", err)
+		}
+		for i, column := range result.Columns {
+			rowidAlias := len(keyColumns) == 1 && strings.EqualFold(strings.TrimSpace(column.Type), "INTEGER")
+			if column.IsPrimaryKey && (withoutRowid || rowidAlias) {
+				result.Columns[i].Nullable = false
+			}
+		}
+	}
+
+	definitions, err := s.getTableDefinitions(ctx, schemaName, relName)
+	if err != nil {
+		return interfaces.TableSchema{}, err
+	}
+	result.Constraints = parseSQLiteCheckConstraints(definitions[relName])
+
+	if err := s.getTableIndexes(ctx, schemaName, relName, definitions, &result); err != nil {
+		return interfaces.TableSchema{}, err
+	}
+
+	if err := s.getTableForeignKeys(ctx, schemaName, relName, &result); err != nil {
+		return interfaces.TableSchema{}, err
+	}
+
+	return result, nil
+}
+
+// getTableDefinitions returns the CREATE statements of a table and its
+// indexes by object name
+func (s *sqliteImpl) getTableDefinitions(ctx context.Context, schemaName string, relName string) (map[string]string, error) {
+	// Each attached database has its own sqlite_master
+	query := fmt.Sprintf(`
+		SELECT name, sql
+		FROM "%s".sqlite_master
+		WHERE tbl_name = ?
+			AND sql IS NOT NULL
+	`, strings.ReplaceAll(schemaName, `"`, `""`))
+
+	rows, err := s.db.QueryContext(ctx, query, relName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting table definition: %w", err)
+	}
+	defer rows.Close()
+
+	definitions := make(map[string]string)
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return nil, fmt.Errorf("error scanning table definition: %w", err)
+		}
+		definitions[name] = definition
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table definitions: %w", err)
+	}
+
+	return definitions, nil
+}
+
+// getTableIndexes adds the indexes of a table to schema, along with the
+// unique constraints they implement. SQLite does not list partial index
+// predicates, so they are read from the CREATE INDEX statements.
+func (s *sqliteImpl) getTableIndexes(ctx context.Context, schemaName string, relName string, definitions map[string]string, schema *interfaces.TableSchema) error {
+	// origin is "c" for CREATE INDEX, "u" for UNIQUE constraints and "pk"
+	// for the primary key; key is 0 for the columns appended after the key
+	query := `
+		SELECT il.name, il."unique", il.origin, COALESCE(ii.name, '(expression)')
+		FROM pragma_index_list(?, ?) il
+		JOIN pragma_index_xinfo(il.name, ?) ii
+		WHERE ii.key = 1
+		ORDER BY il.name, ii.seqno
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, relName, schemaName, schemaName)
+	if err != nil {
+		return fmt.Errorf("error getting indexes: %w", err)
+	}
+	defer rows.Close()
+
+	uniqueConstraints := make(map[string]bool)
+	for rows.Next() {
+		var (
+			index          interfaces.IndexInfo
+			origin, column string
+		)
+		if err := rows.Scan(&index.Name, &index.Unique, &origin, &column); err != nil {
+			return fmt.Errorf("error scanning index: %w", err)
+		}
+
+		// Rows are ordered by index, one per column
+		if n := len(schema.Indexes); n == 0 || schema.Indexes[n-1].Name != index.Name {
+			index.Type = "btree"
+			index.Primary = origin == "pk"
+			index.Filter = sqliteIndexFilter(definitions[index.Name])
+			schema.Indexes = append(schema.Indexes, index)
+		}
+		current := &schema.Indexes[len(schema.Indexes)-1]
+		current.Columns = append(current.Columns, column)
+
+		if origin == "u" {
+			uniqueConstraints[index.Name] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating indexes: %w", err)
+	}
+
+	// The primary key is already known from the table's columns
+	for _, index := range schema.Indexes {
+		if uniqueConstraints[index.Name] {
+			schema.Constraints = append(schema.Constraints, interfaces.ConstraintInfo{
+				Name:    index.Name,
+				Type:    constraintUnique,
+				Columns: index.Columns,
+			})
+		}
+	}
+
+	return nil
+}
+
+// getTableForeignKeys adds the foreign keys declared on a table to schema.
+// SQLite does not report constraint names, so the keys are left unnamed.
+func (s *sqliteImpl) getTableForeignKeys(ctx context.Context, schemaName string, relName string, schema *interfaces.TableSchema) error {
+	// "to" is NULL when the foreign key references the parent's primary key
+	query := `
+		SELECT id, "table", "from", COALESCE("to", ''), on_update, on_delete
+		FROM pragma_foreign_key_list(?, ?)
+		ORDER BY id, seq
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, relName, schemaName)
+	if err != nil {
+		return fmt.Errorf("error getting foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	lastID := -1
+	for rows.Next() {
+		var (
+			id                       int
+			fk                       interfaces.ForeignKeyInfo
+			column, referencedColumn string
+		)
+		if err := rows.Scan(&id, &fk.ReferencedTable, &column, &referencedColumn, &fk.OnUpdate, &fk.OnDelete); err != nil {
+			return fmt.Errorf("error scanning foreign key: %w", err)
+		}
+
+		// Rows are ordered by foreign key, one per column
+		if id != lastID {
+			schema.ForeignKeys = append(schema.ForeignKeys, fk)
+			lastID = id
+		}
+		current := &schema.ForeignKeys[len(schema.ForeignKeys)-1]
+		current.Columns = append(current.Columns, column)
+		if referencedColumn != "" {
+			current.ReferencedColumns = append(current.ReferencedColumns, referencedColumn)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	return nil
+}
+
+// parseSQLiteCheckConstraints finds the CHECK constraints in a CREATE TABLE
+// statement, which SQLite keeps nowhere else. Column constraints report
+// their column; unnamed constraints have an empty name.
+func parseSQLiteCheckConstraints(createTable string) []interfaces.ConstraintInfo {
+	tokens, err := lexSQL(engineSQLite, createTable)
+	if err != nil {
+		return nil
+	}
+
+	var constraints []interfaces.ConstraintInfo
+	depth := 0
+	// definitionStart is the first token of the column or table constraint
+	// definition being read
+	definitionStart := -1
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.text == "(":
+			depth++
+			if depth == 1 {
+				definitionStart = i + 1
+			}
+			continue
+		case token.text == ")":
+			depth--
+			continue
+		case token.text == "," && depth == 1:
+			definitionStart = i + 1
+			continue
+		}
+
+		if depth != 1 || !token.isKeyword("CHECK") || i+1 >= len(tokens) || tokens[i+1].text != "(" {
+			continue
+		}
+
+		// Find the parenthesis closing the check expression
+		end := i + 1
+		for level := 0; end < len(tokens); end++ {
+			if tokens[end].text == "(" {
+				level++
+			} else if tokens[end].text == ")" {
+				if level--; level == 0 {
+					break
+				}
+			}
+		}
+		if end == len(tokens) {
+			return constraints
+		}
+
+		constraint := interfaces.ConstraintInfo{
+			Type:       constraintCheck,
+			Definition: createTable[tokens[i+1].offset : tokens[end].offset+1],
+		}
+		if i >= 2 && tokens[i-2].isKeyword("CONSTRAINT") {
+			_, constraint.Name = splitTableName(tokens[i-1].text)
+		}
+		if first := tokens[definitionStart]; definitionStart < i && !first.isKeyword("CONSTRAINT") && !first.isKeyword("CHECK") {
+			_, column := splitTableName(first.text)
+			constraint.Columns = []string{column}
+		}
+		constraints = append(constraints, constraint)
+
+		i = end
+	}
+
+	return constraints
+}
+
+// sqliteIndexFilter returns the WHERE predicate of a partial index
+func sqliteIndexFilter(createIndex string) string {
+	tokens, err := lexSQL(engineSQLite, createIndex)
+	if err != nil {
+		return ""
+	}
+
+	for _, token := range tokens {
+		if token.isKeyword("WHERE") {
+			return strings.TrimSpace(createIndex[token.offset+len(token.text):])
+		}
+	}
+
+	return ""
+}
+
+// getDBTables returns a list of all user tables in the main database
+func (s *sqliteImpl) getDBTables(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT name
+		FROM sqlite_master
+		WHERE type = 'table'
+			AND name NOT LIKE 'sqlite_%'
+		ORDER BY name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tables: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// GetViews returns a list of all views in the main database
+func (s *sqliteImpl) GetViews(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT name
+		FROM sqlite_master
+		WHERE type = 'view'
+		ORDER BY name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting views: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// GetRoutines returns no routines, since SQLite has no stored procedures
+// or SQL-defined functions
+func (s *sqliteImpl) GetRoutines(ctx context.Context) ([]interfaces.RoutineInfo, error) {
+	return nil, nil
+}
+
+// GetObjectDefinition returns the CREATE VIEW statement of a view. name may
+// be qualified with an attached database name.
+func (s *sqliteImpl) GetObjectDefinition(ctx context.Context, name string) (interfaces.ObjectDefinition, error) {
+	schemaName, viewName := splitTableName(name)
+	if schemaName == "" {
+		schemaName = "main"
+	}
+
+	// Each attached database has its own sqlite_master
+	query := fmt.Sprintf(`
+		SELECT sql
+		FROM "%s".sqlite_master
+		WHERE type = 'view'
+			AND name = ?
+	`, strings.ReplaceAll(schemaName, `"`, `""`))
+
+	result := interfaces.ObjectDefinition{
+		Schema: schemaName,
+		Name:   viewName,
+		Type:   objectView,
+	}
+	err := s.db.QueryRowContext(ctx, query, viewName).Scan(&result.Definition)
+	if err == sql.ErrNoRows {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("view %s not found", name)
+	}
+	if err != nil {
+		return interfaces.ObjectDefinition{}, fmt.Errorf("error getting definition of %s: %w", name, err)
+	}
+
+	return result, nil
+}
+
+// getDBSchemas returns the names of the main database and every attached
+// database, which act as schemas in qualified table names
+func (s *sqliteImpl) getDBSchemas(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name FROM pragma_database_list ORDER BY seq")
+	if err != nil {
+		return nil, fmt.Errorf("error getting schemas: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// schemaVersion returns the schema cookies of the main and attached
+// databases, which SQLite increments on every schema change
+func (s *sqliteImpl) schemaVersion(ctx context.Context) (string, error) {
+	schemas, err := s.getDBSchemas(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	versions := make([]string, len(schemas))
+	for i, schemaName := range schemas {
+		var version int64
+		query := fmt.Sprintf(`PRAGMA "%s".schema_version`, strings.ReplaceAll(schemaName, `"`, `""`))
+		if err := s.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+			return "", fmt.Errorf("error getting schema version: %w", err)
+		}
+		versions[i] = fmt.Sprintf("%s:%d", schemaName, version)
+	}
+
+	return strings.Join(versions, ","), nil
+}
+
+// scriptTable returns the CREATE TABLE statement of a table as SQLite
+// stores it, followed by the CREATE INDEX statements of its indexes.
+// Indexes created for PRIMARY KEY and UNIQUE constraints have no statement
+// of their own.
+func (s *sqliteImpl) scriptTable(ctx context.Context, tableName string) (string, error) {
+	schemaName, relName := splitTableName(tableName)
+	if schemaName == "" {
+		schemaName = "main"
+	}
+
+	// Each attached database has its own sqlite_master
+	query := fmt.Sprintf(`
+		SELECT sql
+		FROM "%s".sqlite_master
+		WHERE tbl_name = ?
+			AND type IN ('table', 'index')
+			AND sql IS NOT NULL
+		ORDER BY CASE type WHEN 'table' THEN 0 ELSE 1 END, name
+	`, strings.ReplaceAll(schemaName, `"`, `""`))
+
+	rows, err := s.db.QueryContext(ctx, query, relName)
+	if err != nil {
+		return "", fmt.Errorf("error getting table definition: %w", err)
+	}
+	defer rows.Close()
+
+	statements, err := scanStrings(rows)
+	if err != nil {
+		return "", fmt.Errorf("error getting table definition: %w", err)
+	}
+	if len(statements) == 0 || !strings.HasPrefix(strings.ToUpper(statements[0]), "CREATE TABLE") {
+		return "", fmt.Errorf("table %s not found", tableName)
+	}
+
+	return strings.Join(statements, ";\n\n") + ";\n", nil
+}
+
+// convertSQLiteValue is the valueConverter for SQLite results. The driver
+// already returns integers, reals and text with their Go types, so only
+// BLOB values need converting; they are rendered as hex.
+func convertSQLiteValue(columnType *sql.ColumnType, value any) any {
+	if b, ok := value.([]byte); ok {
+		return "0x" + strings.ToUpper(hex.EncodeToString(b))
+	}
+	return value
+}
+
+// sqliteConfigFromEnv reads the SQLite database configured through SQLITE_PATH
+func sqliteConfigFromEnv() connectionConfig {
+	return connectionConfig{
+		Name:     defaultConnectionName,
+		Engine:   engineSQLite,
+		Database: os.Getenv("SQLITE_PATH"),
+		ReadOnly: readOnlyFromEnv("SQLITE_READ_ONLY"),
+
+		MaxAffectedRows: intFromEnv("SQLITE_MAX_AFFECTED_ROWS", defaultMaxAffectedRows),
+		MaxRows:         intFromEnv("SQLITE_MAX_ROWS", defaultMaxRows),
+		MaxResultBytes:  intFromEnv("SQLITE_MAX_RESULT_BYTES", defaultMaxResultBytes),
+
+		StatementTimeout: secondsFromEnv("SQLITE_STATEMENT_TIMEOUT", defaultStatementTimeout),
+
+		Masking: maskingConfigFromEnv("SQLITE_"),
+	}
+}
+
+// sqliteDSN returns the DSN opening a SQLite database path, which may be a
+// file: URI with a query string of its own, and the file the database is
+// stored in. Read-only connections get the query_only pragma, which makes
+// SQLite itself refuse writes.
+func sqliteDSN(path string, readOnly bool) (dsn string, file string, err error) {
+	name, query, hasQuery := strings.Cut(path, "?")
+	if _, err := url.ParseQuery(query); err != nil {
+		return "", "", fmt.Errorf("invalid SQLite database path %q: %w", path, err)
+	}
+
+	file = name
+	if strings.HasPrefix(name, "file:") {
+		uri, err := url.Parse(name)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid SQLite database URI %q: %w", path, err)
+		}
+		if file = uri.Path; uri.Opaque != "" {
+			if file, err = url.PathUnescape(uri.Opaque); err != nil {
+				return "", "", fmt.Errorf("invalid SQLite database URI %q: %w", path, err)
+			}
+		}
+	}
+
+	dsn = path
+	if readOnly {
+		separator := "?"
+		if hasQuery {
+			separator = "&"
+		}
+		dsn += separator + url.Values{"_pragma": {"query_only(1)"}}.Encode()
+	}
+	return dsn, file, nil
+}
+
+// createSQLiteConnection opens the SQLite database file
+func createSQLiteConnection(config connectionConfig) (*sql.DB, error) {
+	path := config.Database
+	if path == "" {
+		return nil, fmt.Errorf("no database file configured for connection %q", config.Name)
+	}
+
+	dsn, file, err := sqliteDSN(path, config.ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse to silently create an empty database for a mistyped path
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("error opening SQLite database: %w", err)
+	}
+
+	log.Printf("Opening SQLite database %s", path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening SQLite database: %w", err)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error opening SQLite database: %w", err)
+	}
+
+	return db, nil
+}
+
+// NewSQLiteTool creates a new instance of the SQLite tool
+func NewSQLiteTool(server *server.MCPServer) interfaces.Database {
+	// Create a new SQLite implementation
+	config := sqliteConfigFromEnv()
+	sqliteTool := &sqliteImpl{config: config}
+
+	// Open the database
+	if err := sqliteTool.Connect(); err != nil {
+		// Log the error but continue without the tool
+		log.Printf("Failed to initialize SQLite tool: %v", err)
+		return nil
+	}
+
+	log.Println("SQLite tool initialized successfully")
+
+	// Add the SQLite tools to the MCP server
+	if server != nil {
+		// Register the query and table tools shared with the other backends
+		connections := newConnectionRegistry(false)
+		connections.add(config, sqliteTool)
+		registerDatabaseTools(server, "sqlite", "SQLite", connections)
+
+		// Register tool for getting foreign keys
+		getForeignKeysTool := mcp.NewTool("sqlite_get_foreign_keys", withTableArguments(
+			"The name of the table to get the foreign keys for",
+			mcp.WithDescription("Get the foreign keys declared on a SQLite table"),
+		)...)
+
+		server.AddTool(getForeignKeysTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tableName, err := tableNameFromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			queryCtx, cancel, err := statementContext(ctx, config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			schemaName, relName := splitTableName(tableName)
+			if schemaName == "" {
+				schemaName = "main"
+			}
+
+			var schema interfaces.TableSchema
+			if err := sqliteTool.getTableForeignKeys(queryCtx, schemaName, relName, &schema); err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			var resultText strings.Builder
+			resultText.WriteString(fmt.Sprintf("Foreign keys for table %s:\n\n", tableName))
+			resultText.WriteString("ID\tCOLUMN\tREFERENCES\tON_UPDATE\tON_DELETE\n")
+			resultText.WriteString("----------\t----------\t----------\t----------\t----------\n")
+
+			// One row per referencing column; the referenced columns are
+			// empty when the key references the parent's primary key
+			for id, fk := range schema.ForeignKeys {
+				for i, column := range fk.Columns {
+					var referencedColumn string
+					if i < len(fk.ReferencedColumns) {
+						referencedColumn = fk.ReferencedColumns[i]
+					}
+					resultText.WriteString(fmt.Sprintf("%d\t%s\t%s(%s)\t%s\t%s\n",
+						id, column, fk.ReferencedTable, referencedColumn, fk.OnUpdate, fk.OnDelete))
+				}
+			}
+
+			return mcp.NewToolResultText(resultText.String()), nil
+		})
+
+		// Register tool for getting indexes
+		getIndexesTool := mcp.NewTool("sqlite_get_indexes", withTableArguments(
+			"The name of the table to get the indexes for",
+			mcp.WithDescription("Get the indexes of a SQLite table"),
+		)...)
+
+		server.AddTool(getIndexesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tableName, err := tableNameFromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			queryCtx, cancel, err := statementContext(ctx, config, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer cancel()
+
+			schemaName, relName := splitTableName(tableName)
+			if schemaName == "" {
+				schemaName = "main"
+			}
+
+			definitions, err := sqliteTool.getTableDefinitions(queryCtx, schemaName, relName)
+			if err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+			var schema interfaces.TableSchema
+			if err := sqliteTool.getTableIndexes(queryCtx, schemaName, relName, definitions, &schema); err != nil {
+				return mcp.NewToolResultError(contextError(queryCtx, err).Error()), nil
+			}
+
+			// origin is "pk" for the primary key, "u" for UNIQUE constraints
+			// and "c" for CREATE INDEX, as SQLite reports it
+			uniqueConstraints := make(map[string]bool)
+			for _, constraint := range schema.Constraints {
+				if constraint.Type == constraintUnique {
+					uniqueConstraints[constraint.Name] = true
+				}
+			}
+
+			var resultText strings.Builder
+			resultText.WriteString(fmt.Sprintf("Indexes for table %s:\n\n", tableName))
+			resultText.WriteString("INDEX_NAME\tCOLUMNS\tUNIQUE\tORIGIN\tPARTIAL\n")
+			resultText.WriteString("----------\t----------\t----------\t----------\t----------\n")
+
+			for _, index := range schema.Indexes {
+				origin := "c"
+				switch {
+				case index.Primary:
+					origin = "pk"
+				case uniqueConstraints[index.Name]:
+					origin = "u"
+				}
+				resultText.WriteString(fmt.Sprintf("%s\t%s\t%v\t%s\t%v\n",
+					index.Name, strings.Join(index.Columns, ", "), index.Unique, origin, index.Filter != ""))
+			}
+
+			return mcp.NewToolResultText(resultText.String()), nil
+		})
+	}
+
+	return sqliteTool
+}
@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		path     string
+		readOnly bool
+		dsn      string
+		file     string
+	}{
+		{"/data/app.db", false, "/data/app.db", "/data/app.db"},
+		{"/data/app.db", true, "/data/app.db?_pragma=query_only%281%29", "/data/app.db"},
+		{"file:app.db?mode=ro", true, "file:app.db?mode=ro&_pragma=query_only%281%29", "app.db"},
+		{"file:///data/my%20app.db?cache=shared", true, "file:///data/my%20app.db?cache=shared&_pragma=query_only%281%29", "/data/my app.db"},
+		{"file:my%20app.db", false, "file:my%20app.db", "my app.db"},
+	}
+
+	for _, test := range tests {
+		dsn, file, err := sqliteDSN(test.path, test.readOnly)
+		if err != nil {
+			t.Errorf("sqliteDSN(%q) failed: %v", test.path, err)
+			continue
+		}
+		if dsn != test.dsn || file != test.file {
+			t.Errorf("sqliteDSN(%q, %v) = %q, %q, want %q, %q", test.path, test.readOnly, dsn, file, test.dsn, test.file)
+		}
+	}
+}
+
+func TestCreateSQLiteConnectionURI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	setup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = setup.Exec("CREATE TABLE t (x INTEGER)")
+	setup.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := connectionConfig{Name: defaultConnectionName, Engine: engineSQLite, Database: "file:" + path + "?cache=private", ReadOnly: true}
+	db, err := createSQLiteConnection(config)
+	if err != nil {
+		t.Fatalf("opening %s failed: %v", config.Database, err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM t").Scan(&count); err != nil {
+		t.Errorf("reading through the URI failed: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO t VALUES (1)"); err == nil || !strings.Contains(err.Error(), "readonly") {
+		t.Errorf("writing to a read-only connection returned %v, want a read-only error", err)
+	}
+}
+
+func TestSQLitePrimaryKeyNullability(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.db")
+	setup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = setup.Exec(`CREATE TABLE rowid_alias (id INTEGER PRIMARY KEY, note TEXT);
+		CREATE TABLE text_key (code TEXT PRIMARY KEY, note TEXT NOT NULL);
+		CREATE TABLE pair_key (a INTEGER, b INTEGER, PRIMARY KEY (a, b));
+		CREATE TABLE no_rowid (code TEXT PRIMARY KEY, note TEXT) WITHOUT ROWID`)
+	setup.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlite := &sqliteImpl{config: connectionConfig{Name: defaultConnectionName, Engine: engineSQLite, Database: path}}
+	if err := sqlite.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Disconnect()
+
+	tests := []struct {
+		table    string
+		column   string
+		nullable bool
+	}{
+		{"rowid_alias", "id", false},
+		{"rowid_alias", "note", true},
+		{"text_key", "code", true},
+		{"text_key", "note", false},
+		{"pair_key", "a", true},
+		{"no_rowid", "code", false},
+	}
+
+	for _, test := range tests {
+		schema, err := sqlite.GetTableSchema(context.Background(), test.table)
+		if err != nil {
+			t.Fatalf("GetTableSchema(%s) failed: %v", test.table, err)
+		}
+		for _, column := range schema.Columns {
+			if column.Name == test.column && column.Nullable != test.nullable {
+				t.Errorf("%s.%s nullable = %v, want %v", test.table, test.column, column.Nullable, test.nullable)
+			}
+		}
+	}
+}
+
+func TestSQLiteKeyAndIndexTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.db")
+	setup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = setup.Exec(`CREATE TABLE customers (id INTEGER PRIMARY KEY, email TEXT UNIQUE);
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES customers (id) ON DELETE CASCADE, status TEXT);
+		CREATE INDEX ix_orders_open ON orders (customer_id) WHERE status = 'open'`)
+	setup.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SQLITE_PATH", path)
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	sqlite := NewSQLiteTool(mcpServer)
+	if sqlite == nil {
+		t.Fatal("NewSQLiteTool failed")
+	}
+	defer sqlite.Disconnect()
+
+	result := callTool(t, mcpServer, "sqlite_get_foreign_keys", map[string]any{"table_name": "orders"})
+	if result.IsError || !strings.Contains(result.Text, "0\tcustomer_id\tcustomers(id)\tNO ACTION\tCASCADE\n") {
+		t.Errorf("sqlite_get_foreign_keys returned %s", result.Text)
+	}
+
+	result = callTool(t, mcpServer, "sqlite_get_indexes", map[string]any{"table_name": "orders"})
+	if result.IsError || !strings.Contains(result.Text, "ix_orders_open\tcustomer_id\tfalse\tc\ttrue\n") {
+		t.Errorf("sqlite_get_indexes on orders returned %s", result.Text)
+	}
+	result = callTool(t, mcpServer, "sqlite_get_indexes", map[string]any{"table_name": "customers"})
+	if result.IsError || !strings.Contains(result.Text, "\temail\ttrue\tu\tfalse\n") {
+		t.Errorf("sqlite_get_indexes on customers returned %s", result.Text)
+	}
+}
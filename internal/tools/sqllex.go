@@ -0,0 +1,317 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// sqlTokenKind classifies the tokens produced by lexSQL
+type sqlTokenKind int
+
+const (
+	// tokenWord is a keyword or unquoted identifier
+	tokenWord sqlTokenKind = iota
+	// tokenQuotedIdent is an identifier in "quotes", `backticks` or [brackets]
+	tokenQuotedIdent
+	// tokenString is a string literal, including PostgreSQL dollar-quoted strings
+	tokenString
+	// tokenNumber is a numeric literal
+	tokenNumber
+	// tokenParam is a placeholder or variable such as ?, $1, @p1 or :name
+	tokenParam
+	// tokenPunct is any other single character: operators, parentheses, commas
+	tokenPunct
+	// tokenSemicolon separates statements
+	tokenSemicolon
+)
+
+// sqlToken is one lexical element of a SQL text
+type sqlToken struct {
+	kind sqlTokenKind
+
+	// text is the token as written in the source
+	text string
+
+	// upper is text in upper case, set for tokenWord only
+	upper string
+
+	// offset is the byte offset of the token in the source
+	offset int
+}
+
+// isKeyword reports whether the token is the unquoted word keyword
+func (t sqlToken) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && t.upper == keyword
+}
+
+// sqlStatement is a run of tokens between top-level semicolons
+type sqlStatement struct {
+	tokens []sqlToken
+
+	// text is the statement's source text, trimmed of surrounding space
+	text string
+}
+
+// lexSQL splits a SQL text into tokens, skipping whitespace and comments.
+// Quoting and comment rules follow the given engine, so text hidden inside
+// a string or comment in that dialect is never mistaken for code: MySQL
+// strings honour backslash escapes and "#" comments, PostgreSQL supports
+// E'...' strings and $tag$ quoting, and block comments nest on PostgreSQL and
+// SQL Server but not on MySQL.
+func lexSQL(engine string, text string) ([]sqlToken, error) {
+	var tokens []sqlToken
+
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+
+		case c == '-' && strings.HasPrefix(text[i:], "--"),
+			c == '#' && engine == engineMySQL:
+			// Line comment
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				i = len(text)
+			} else {
+				i += end + 1
+			}
+
+		case c == '/' && strings.HasPrefix(text[i:], "/*"):
+			// MySQL runs the body of /*! ... */ comments as code
+			if engine == engineMySQL && strings.HasPrefix(text[i:], "/*!") {
+				return nil, fmt.Errorf("executable comments (/*! ... */) are not supported")
+			}
+
+			end, err := skipBlockComment(engine, text, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '\'':
+			end, err := skipQuoted(text, i, '\'', engine == engineMySQL)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: text[start:end], offset: start})
+			i = end
+
+		case (c == 'E' || c == 'e') && engine == enginePostgres && i+1 < len(text) && text[i+1] == '\'':
+			// PostgreSQL escape string constant
+			end, err := skipQuoted(text, i+1, '\'', true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: text[start:end], offset: start})
+			i = end
+
+		case (c == 'N' || c == 'n') && engine == engineSQLServer && i+1 < len(text) && text[i+1] == '\'':
+			// SQL Server Unicode string constant
+			end, err := skipQuoted(text, i+1, '\'', false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: text[start:end], offset: start})
+			i = end
+
+		case c == '"':
+			// MySQL treats double quotes as strings unless ANSI_QUOTES is set
+			kind := tokenQuotedIdent
+			if engine == engineMySQL {
+				kind = tokenString
+			}
+			end, err := skipQuoted(text, i, '"', engine == engineMySQL)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: text[start:end], offset: start})
+			i = end
+
+		case c == '`' && (engine == engineMySQL || engine == engineSQLite):
+			end, err := skipQuoted(text, i, '`', false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdent, text: text[start:end], offset: start})
+			i = end
+
+		case c == '[' && (engine == engineSQLServer || engine == engineSQLite):
+			end, err := skipQuoted(text, i, ']', false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdent, text: text[start:end], offset: start})
+			i = end
+
+		case c == '$' && engine == enginePostgres:
+			if i+1 < len(text) && isDigit(text[i+1]) {
+				// Positional parameter $1
+				i++
+				for i < len(text) && isDigit(text[i]) {
+					i++
+				}
+				tokens = append(tokens, sqlToken{kind: tokenParam, text: text[start:i], offset: start})
+				continue
+			}
+
+			end, err := skipDollarQuoted(text, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: text[start:end], offset: start})
+			i = end
+
+		case c == '?' || ((c == '@' || c == ':' || c == '$') && i+1 < len(text) && isWordChar(text[i+1])) ||
+			(c == '@' && strings.HasPrefix(text[i:], "@@")):
+			// Placeholders and variables: ?, @p1, @@ROWCOUNT, :name
+			i++
+			for i < len(text) && (isWordChar(text[i]) || text[i] == '@') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenParam, text: text[start:i], offset: start})
+
+		case isDigit(c) || (c == '.' && i+1 < len(text) && isDigit(text[i+1])):
+			for i < len(text) && (isWordChar(text[i]) || text[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: text[start:i], offset: start})
+
+		case isWordStart(c):
+			for i < len(text) && (isWordChar(text[i]) || text[i] == '$' || text[i] >= 0x80) {
+				i++
+			}
+			word := text[start:i]
+			tokens = append(tokens, sqlToken{kind: tokenWord, text: word, upper: strings.ToUpper(word), offset: start})
+
+		case c == ';':
+			i++
+			tokens = append(tokens, sqlToken{kind: tokenSemicolon, text: ";", offset: start})
+
+		default:
+			i++
+			tokens = append(tokens, sqlToken{kind: tokenPunct, text: text[start:i], offset: start})
+		}
+	}
+
+	return tokens, nil
+}
+
+// splitSQLStatements lexes text and groups the tokens into statements
+// separated by top-level semicolons. Empty statements are dropped.
+func splitSQLStatements(engine string, text string) ([]sqlStatement, error) {
+	tokens, err := lexSQL(engine, text)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []sqlStatement
+	var current []sqlToken
+	flush := func(end int) {
+		if len(current) == 0 {
+			return
+		}
+		statements = append(statements, sqlStatement{
+			tokens: current,
+			text:   strings.TrimSpace(text[current[0].offset:end]),
+		})
+		current = nil
+	}
+
+	for _, token := range tokens {
+		if token.kind == tokenSemicolon {
+			flush(token.offset)
+			continue
+		}
+		current = append(current, token)
+	}
+	flush(len(text))
+
+	return statements, nil
+}
+
+// skipQuoted returns the offset just past the quoted token starting at
+// start. A doubled closing quote is an escaped quote; backslashEscapes also
+// lets a backslash escape the next character.
+func skipQuoted(text string, start int, closing byte, backslashEscapes bool) (int, error) {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case closing:
+			if i+1 < len(text) && text[i+1] == closing {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated quoted text starting at offset %d", start)
+}
+
+// skipBlockComment returns the offset just past the /* */ comment starting
+// at start. Comments nest on PostgreSQL and SQL Server.
+func skipBlockComment(engine string, text string, start int) (int, error) {
+	nests := engine == enginePostgres || engine == engineSQLServer
+
+	depth := 0
+	for i := start; i+1 < len(text); i++ {
+		switch {
+		case text[i] == '/' && text[i+1] == '*':
+			if depth == 0 || nests {
+				depth++
+			}
+			i++
+		case text[i] == '*' && text[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated comment starting at offset %d", start)
+}
+
+// skipDollarQuoted returns the offset just past the PostgreSQL $tag$...$tag$
+// string starting at start
+func skipDollarQuoted(text string, start int) (int, error) {
+	end := start + 1
+	for end < len(text) && isWordChar(text[end]) {
+		end++
+	}
+	if end >= len(text) || text[end] != '$' {
+		return 0, fmt.Errorf("unexpected '$' at offset %d", start)
+	}
+
+	tag := text[start : end+1]
+	closing := strings.Index(text[end+1:], tag)
+	if closing < 0 {
+		return 0, fmt.Errorf("unterminated dollar-quoted string starting at offset %d", start)
+	}
+
+	return end + 1 + closing + len(tag), nil
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isWordStart reports whether c can start an unquoted identifier
+func isWordStart(c byte) bool {
+	return c == '_' || c >= 0x80 || unicode.IsLetter(rune(c))
+}
+
+// isWordChar reports whether c can continue an unquoted identifier
+func isWordChar(c byte) bool {
+	return isWordStart(c) || isDigit(c)
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLexSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		text   string
+		want   []string
+	}{
+		{"words and punctuation", engineSQLServer, "SELECT a, b FROM t", []string{"SELECT", "a", ",", "b", "FROM", "t"}},
+		{"line comment", engineSQLServer, "SELECT 1 -- DROP TABLE t\n", []string{"SELECT", "1"}},
+		{"block comment", engineSQLServer, "SELECT /* DROP */ 1", []string{"SELECT", "1"}},
+		{"nested block comment", enginePostgres, "SELECT /* a /* b */ DROP */ 1", []string{"SELECT", "1"}},
+		{"unnested block comment", engineMySQL, "SELECT /* a /* b */ 1", []string{"SELECT", "1"}},
+		{"hash comment on MySQL", engineMySQL, "SELECT 1 # DROP\n", []string{"SELECT", "1"}},
+		{"doubled quote", engineSQLServer, "SELECT 'it''s'", []string{"SELECT", "'it''s'"}},
+		{"backslash escape on MySQL", engineMySQL, `SELECT 'a\'; DROP'`, []string{"SELECT", `'a\'; DROP'`}},
+		{"unicode string", engineSQLServer, "SELECT N'x'", []string{"SELECT", "N'x'"}},
+		{"escape string", enginePostgres, `SELECT E'a\'b'`, []string{"SELECT", `E'a\'b'`}},
+		{"dollar quoting", enginePostgres, "SELECT $tag$ ; DROP $tag$", []string{"SELECT", "$tag$ ; DROP $tag$"}},
+		{"positional parameter", enginePostgres, "SELECT $1", []string{"SELECT", "$1"}},
+		{"bracket identifier", engineSQLServer, "SELECT [DROP] FROM t", []string{"SELECT", "[DROP]", "FROM", "t"}},
+		{"backtick identifier", engineMySQL, "SELECT `DROP`", []string{"SELECT", "`DROP`"}},
+		{"double quotes are strings on MySQL", engineMySQL, `SELECT "x"`, []string{"SELECT", `"x"`}},
+		{"variables", engineSQLServer, "SELECT @p1, @@ROWCOUNT", []string{"SELECT", "@p1", ",", "@@ROWCOUNT"}},
+		{"semicolon", engineSQLite, "SELECT 1; SELECT 2", []string{"SELECT", "1", ";", "SELECT", "2"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := lexSQL(test.engine, test.text)
+			if err != nil {
+				t.Fatalf("lexSQL(%q) failed: %v", test.text, err)
+			}
+			var got []string
+			for _, token := range tokens {
+				got = append(got, token.text)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("lexSQL(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLexSQLErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		text   string
+	}{
+		{"unterminated string", engineSQLServer, "SELECT 'abc"},
+		{"unterminated identifier", engineSQLServer, "SELECT [abc"},
+		{"unterminated comment", enginePostgres, "SELECT /* /* */ 1"},
+		{"unterminated dollar quoting", enginePostgres, "SELECT $x$ abc"},
+		{"executable comment", engineMySQL, "SELECT /*! DROP TABLE t */ 1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := lexSQL(test.engine, test.text); err == nil {
+				t.Errorf("lexSQL(%q) succeeded, want an error", test.text)
+			}
+		})
+	}
+}
+
+func TestSplitSQLStatements(t *testing.T) {
+	statements, err := splitSQLStatements(engineSQLite, "SELECT 1;; SELECT ';' ;\n")
+	if err != nil {
+		t.Fatalf("splitSQLStatements failed: %v", err)
+	}
+
+	var got []string
+	for _, statement := range statements {
+		got = append(got, statement.text)
+	}
+	want := []string{"SELECT 1", "SELECT ';'"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitSQLStatements = %q, want %q", got, want)
+	}
+}
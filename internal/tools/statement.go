@@ -0,0 +1,205 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// confirmTokenTTL is how long the confirm token of a dry-run stays valid
+const confirmTokenTTL = 10 * time.Minute
+
+// defaultMaxAffectedRows caps the rows a single write statement may affect
+// when the connection does not configure a limit
+const defaultMaxAffectedRows = 1000
+
+// statementExecutor is implemented by backends that can run a write
+// statement inside a transaction for the execute_statement tools
+type statementExecutor interface {
+	executeStatement(ctx context.Context, statement string, commit bool) (int64, error)
+}
+
+// pendingStatement is a dry-run waiting to be confirmed
+type pendingStatement struct {
+	connection   string
+	statement    string
+	rowsAffected int64
+	expires      time.Time
+}
+
+// confirmTokens hands out and redeems the single-use tokens that link a
+// committed statement to the dry-run it was reviewed in
+type confirmTokens struct {
+	mu      sync.Mutex
+	pending map[string]pendingStatement
+}
+
+// newConfirmTokens creates an empty token store
+func newConfirmTokens() *confirmTokens {
+	return &confirmTokens{
+		pending: make(map[string]pendingStatement),
+	}
+}
+
+// issue returns a new token for a dry-run of statement on connection
+func (c *confirmTokens) issue(connection string, statement string, rowsAffected int64) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating confirm token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop expired dry-runs so abandoned tokens do not pile up
+	now := time.Now()
+	for key, pending := range c.pending {
+		if now.After(pending.expires) {
+			delete(c.pending, key)
+		}
+	}
+
+	c.pending[token] = pendingStatement{
+		connection:   connection,
+		statement:    statement,
+		rowsAffected: rowsAffected,
+		expires:      now.Add(confirmTokenTTL),
+	}
+
+	return token, nil
+}
+
+// redeem consumes token, checking that it was issued for the same
+// statement on the same connection, and returns the dry-run it belongs to
+func (c *confirmTokens) redeem(token string, connection string, statement string) (pendingStatement, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.pending[token]
+	if !ok || time.Now().After(pending.expires) {
+		delete(c.pending, token)
+		return pendingStatement{}, fmt.Errorf("confirm token is unknown or has expired; run the statement without confirm_token first")
+	}
+
+	if pending.connection != connection || pending.statement != statement {
+		return pendingStatement{}, fmt.Errorf("confirm token was issued for a different statement or connection; run the statement without confirm_token first")
+	}
+
+	delete(c.pending, token)
+	return pending, nil
+}
+
+// executeInTransaction runs a write statement for a backend's
+// executeStatement method. The statement runs in a transaction that is
+// committed only when commit is set and the rows affected stay within the
+// connection's limit; otherwise it is rolled back.
+func executeInTransaction(ctx context.Context, db *sql.DB, config connectionConfig, statement string, commit bool) (int64, error) {
+	if err := checkWritable(config); err != nil {
+		return 0, err
+	}
+
+	if err := checkTransactionalStatement(config.Engine, statement); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	// Rolls back unless the transaction was committed
+	defer tx.Rollback()
+
+	if config.Engine == engineMySQL {
+		stop, err := killMySQLQueryOnCancel(ctx, db, tx)
+		if err != nil {
+			return 0, err
+		}
+		defer stop()
+	}
+
+	result, err := tx.ExecContext(ctx, statement)
+	if err != nil {
+		return 0, fmt.Errorf("error executing statement: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if config.MaxAffectedRows > 0 && rowsAffected > config.MaxAffectedRows {
+		return rowsAffected, fmt.Errorf("statement affected %d rows, more than the limit of %d for connection %q; the transaction was rolled back",
+			rowsAffected, config.MaxAffectedRows, config.Name)
+	}
+
+	if !commit {
+		return rowsAffected, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// checkTransactionalStatement rejects statements that would end the
+// transaction a write statement runs in, since a dry-run could then not be
+// rolled back
+func checkTransactionalStatement(engine string, statement string) error {
+	statements, err := splitSQLStatements(engine, statement)
+	if err != nil {
+		return fmt.Errorf("statement could not be parsed: %w", err)
+	}
+
+	for _, s := range statements {
+		if reason := transactionViolation(engine, s.tokens); reason != "" {
+			return fmt.Errorf("%s is not allowed, the statement already runs in a transaction: %s", reason, abbreviate(s.text, 200))
+		}
+	}
+
+	return nil
+}
+
+// transactionViolation returns the transaction control or implicitly
+// committing statement found in tokens, or an empty string
+func transactionViolation(engine string, tokens []sqlToken) string {
+	if engine == engineSQLServer {
+		// T-SQL batches need no semicolons and BEGIN also opens blocks, so
+		// look at every word rather than the statement's first one
+		for i, token := range tokens {
+			switch {
+			case token.isKeyword("COMMIT"), token.isKeyword("ROLLBACK"):
+				return token.upper
+			case (token.isKeyword("BEGIN") || token.isKeyword("SAVE")) && i+1 < len(tokens) &&
+				(tokens[i+1].isKeyword("TRAN") || tokens[i+1].isKeyword("TRANSACTION") || tokens[i+1].isKeyword("DISTRIBUTED")):
+				return token.upper + " " + tokens[i+1].upper
+			}
+		}
+		return ""
+	}
+
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return ""
+	}
+
+	switch lead := tokens[0].upper; lead {
+	case "BEGIN", "START", "COMMIT", "ROLLBACK", "END", "ABORT", "SAVEPOINT", "RELEASE":
+		return lead
+	case "CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME", "GRANT", "REVOKE", "LOCK", "UNLOCK":
+		// MySQL commits the open transaction before running DDL
+		if engine == engineMySQL {
+			return lead + " (implicitly commits on MySQL)"
+		}
+	case "VACUUM":
+		// SQLite and PostgreSQL cannot vacuum inside a transaction
+		return lead
+	}
+
+	return ""
+}
@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anhnt2003/mcp-tool-kit/internal/interfaces"
+)
+
+// Types of the constraints in interfaces.ConstraintInfo
+const (
+	constraintUnique = "UNIQUE"
+	constraintCheck  = "CHECK"
+)
+
+// markPrimaryKeyColumns sets IsPrimaryKey on the columns of the primary key
+func markPrimaryKeyColumns(schema *interfaces.TableSchema) {
+	if schema.PrimaryKey == nil {
+		return
+	}
+
+	keyColumns := make(map[string]bool, len(schema.PrimaryKey.Columns))
+	for _, column := range schema.PrimaryKey.Columns {
+		keyColumns[column] = true
+	}
+	for i := range schema.Columns {
+		if keyColumns[schema.Columns[i].Name] {
+			schema.Columns[i].IsPrimaryKey = true
+		}
+	}
+}
+
+// keysFromIndexes derives the primary key and unique constraints from the
+// indexes backing them, for servers that do not list them separately
+func keysFromIndexes(schema *interfaces.TableSchema, uniqueConstraints map[string]bool) {
+	for _, index := range schema.Indexes {
+		switch {
+		case index.Primary:
+			schema.PrimaryKey = &interfaces.KeyInfo{Name: index.Name, Columns: index.Columns}
+		case uniqueConstraints[index.Name]:
+			schema.Constraints = append(schema.Constraints, interfaces.ConstraintInfo{
+				Name:    index.Name,
+				Type:    constraintUnique,
+				Columns: index.Columns,
+			})
+		}
+	}
+}
+
+// referentialAction normalizes a referential action such as SET_NULL or
+// "NO ACTION" to upper case words
+func referentialAction(action string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(action), "_", " "))
+}
+
+// formatTableSchema renders a table schema for the get_table_schema tools
+func formatTableSchema(schema interfaces.TableSchema) string {
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Schema for table %s:\n\n", schema.QualifiedName()))
+
+	// Print headers
+	resultText.WriteString("COLUMN_NAME\tDATA_TYPE\tIS_NULLABLE\tDEFAULT_VALUE\tIS_PRIMARY_KEY\n")
+	resultText.WriteString("----------\t----------\t----------\t----------\t----------\n")
+
+	// Print data
+	for _, col := range schema.Columns {
+		defaultValue := ""
+		if col.DefaultValue != nil {
+			defaultValue = fmt.Sprintf("%v", col.DefaultValue)
+		}
+
+		resultText.WriteString(fmt.Sprintf("%s\t%s\t%v\t%s\t%v\n",
+			col.Name, col.Type, col.Nullable, defaultValue, col.IsPrimaryKey))
+	}
+
+	if schema.PrimaryKey != nil {
+		resultText.WriteString(fmt.Sprintf("\nPrimary key: %s", strings.Join(schema.PrimaryKey.Columns, ", ")))
+		if schema.PrimaryKey.Name != "" {
+			resultText.WriteString(fmt.Sprintf(" (%s)", schema.PrimaryKey.Name))
+		}
+		resultText.WriteString("\n")
+	}
+
+	if len(schema.ForeignKeys) > 0 {
+		resultText.WriteString("\nForeign keys:\n\n")
+		resultText.WriteString("NAME\tCOLUMNS\tREFERENCED_TABLE\tREFERENCED_COLUMNS\tON_DELETE\tON_UPDATE\n")
+		resultText.WriteString("----------\t----------\t----------\t----------\t----------\t----------\n")
+		for _, fk := range schema.ForeignKeys {
+			resultText.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\n",
+				fk.Name, strings.Join(fk.Columns, ", "), fk.ReferencedTable,
+				strings.Join(fk.ReferencedColumns, ", "), fk.OnDelete, fk.OnUpdate))
+		}
+	}
+
+	if len(schema.Indexes) > 0 {
+		resultText.WriteString("\nIndexes:\n\n")
+		resultText.WriteString("NAME\tTYPE\tCOLUMNS\tINCLUDED_COLUMNS\tIS_UNIQUE\tIS_PRIMARY_KEY\tFILTER\n")
+		resultText.WriteString("----------\t----------\t----------\t----------\t----------\t----------\t----------\n")
+		for _, index := range schema.Indexes {
+			resultText.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%v\t%v\t%s\n",
+				index.Name, index.Type, strings.Join(index.Columns, ", "),
+				strings.Join(index.IncludedColumns, ", "), index.Unique, index.Primary, index.Filter))
+		}
+	}
+
+	if len(schema.Constraints) > 0 {
+		resultText.WriteString("\nConstraints:\n\n")
+		resultText.WriteString("NAME\tTYPE\tCOLUMNS\tDEFINITION\n")
+		resultText.WriteString("----------\t----------\t----------\t----------\n")
+		for _, constraint := range schema.Constraints {
+			resultText.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\n",
+				constraint.Name, constraint.Type, strings.Join(constraint.Columns, ", "), constraint.Definition))
+		}
+	}
+
+	return resultText.String()
+}
+
+// formatTableDDL renders a table schema as a CREATE TABLE statement followed
+// by the table's other indexes, with identifiers quoted for engine. Types and
+// defaults are shown as the catalog reports them, so the statement describes
+// the table rather than recreating it exactly.
+func formatTableDDL(schema interfaces.TableSchema, engine string) string {
+	var definitions []string
+	for _, column := range schema.Columns {
+		definitions = append(definitions, columnDefinition(column, engine))
+	}
+
+	if key := schema.PrimaryKey; key != nil {
+		definitions = append(definitions, constraintPrefix(key.Name, engine)+
+			fmt.Sprintf("PRIMARY KEY (%s)", quoteColumns(schema, key.Columns, engine)))
+	}
+
+	constraintIndexes := make(map[string]bool)
+	for _, constraint := range schema.Constraints {
+		switch constraint.Type {
+		case constraintUnique:
+			constraintIndexes[constraint.Name] = true
+			definitions = append(definitions, constraintPrefix(constraint.Name, engine)+
+				fmt.Sprintf("UNIQUE (%s)", quoteColumns(schema, constraint.Columns, engine)))
+		case constraintCheck:
+			check := strings.TrimSpace(constraint.Definition)
+			if !strings.HasPrefix(strings.ToUpper(check), "CHECK") {
+				if !strings.HasPrefix(check, "(") {
+					check = "(" + check + ")"
+				}
+				check = "CHECK " + check
+			}
+			definitions = append(definitions, constraintPrefix(constraint.Name, engine)+check)
+		}
+	}
+
+	for _, fk := range schema.ForeignKeys {
+		definitions = append(definitions, constraintPrefix(fk.Name, engine)+foreignKeyDefinition(schema, fk, engine))
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("CREATE TABLE %s (\n    %s\n);\n", quoteTableName(schema.Schema, schema.TableName, engine),
+		strings.Join(definitions, ",\n    ")))
+
+	for _, index := range schema.Indexes {
+		if index.Primary || constraintIndexes[index.Name] {
+			continue
+		}
+		resultText.WriteString("\n" + indexDefinition(schema, index, engine) + "\n")
+	}
+
+	return resultText.String()
+}
+
+// columnDefinition renders a column as declared in CREATE TABLE
+func columnDefinition(column interfaces.ColumnInfo, engine string) string {
+	definition := fmt.Sprintf("%s %s", quoteIdentifier(column.Name, engine), column.Type)
+	if column.Nullable {
+		definition += " NULL"
+	} else {
+		definition += " NOT NULL"
+	}
+	if column.DefaultValue != nil {
+		definition += fmt.Sprintf(" DEFAULT %v", column.DefaultValue)
+	}
+	return definition
+}
+
+// foreignKeyDefinition renders a foreign key constraint without its name.
+// Referential actions are left out when they are the default NO ACTION.
+func foreignKeyDefinition(schema interfaces.TableSchema, fk interfaces.ForeignKeyInfo, engine string) string {
+	referencedSchema, referencedTable := splitTableName(fk.ReferencedTable)
+	definition := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)", quoteColumns(schema, fk.Columns, engine),
+		quoteTableName(referencedSchema, referencedTable, engine), quoteNames(fk.ReferencedColumns, engine))
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		definition += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		definition += " ON UPDATE " + fk.OnUpdate
+	}
+	return definition
+}
+
+// indexDefinition renders the CREATE INDEX statement of an index, noting
+// its type in a comment
+func indexDefinition(schema interfaces.TableSchema, index interfaces.IndexInfo, engine string) string {
+	var definition strings.Builder
+	definition.WriteString("CREATE ")
+	if index.Unique {
+		definition.WriteString("UNIQUE ")
+	}
+	definition.WriteString(fmt.Sprintf("INDEX %s ON %s (%s)", quoteIdentifier(index.Name, engine),
+		quoteTableName(schema.Schema, schema.TableName, engine), quoteColumns(schema, index.Columns, engine)))
+	if len(index.IncludedColumns) > 0 {
+		definition.WriteString(fmt.Sprintf(" INCLUDE (%s)", quoteColumns(schema, index.IncludedColumns, engine)))
+	}
+	if index.Filter != "" {
+		definition.WriteString(" WHERE " + index.Filter)
+	}
+	definition.WriteString(";")
+	if index.Type != "" {
+		definition.WriteString(" -- " + index.Type)
+	}
+	return definition.String()
+}
+
+// constraintPrefix returns the CONSTRAINT clause naming a table constraint,
+// or nothing for constraints without a name
+func constraintPrefix(name string, engine string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("CONSTRAINT %s ", quoteIdentifier(name, engine))
+}
+
+// quoteIdentifier quotes an identifier the way engine does: with brackets
+// on SQL Server, backticks on MySQL and double quotes elsewhere
+func quoteIdentifier(identifier string, engine string) string {
+	switch engine {
+	case engineSQLServer:
+		return quoteSQLServerIdentifier(identifier)
+	case engineMySQL:
+		return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// quoteTableName quotes a table name and its schema, if any
+func quoteTableName(schemaName string, relName string, engine string) string {
+	if schemaName == "" {
+		return quoteIdentifier(relName, engine)
+	}
+	return quoteIdentifier(schemaName, engine) + "." + quoteIdentifier(relName, engine)
+}
+
+// quoteNames quotes and joins a list of column names
+func quoteNames(names []string, engine string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name, engine)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteColumns joins the columns of a key or index, quoting those that name
+// a column of the table. Index entries can also be expressions, which are
+// kept as they are.
+func quoteColumns(schema interfaces.TableSchema, columns []string, engine string) string {
+	names := make(map[string]bool, len(schema.Columns))
+	for _, column := range schema.Columns {
+		names[column.Name] = true
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		if names[column] {
+			quoted[i] = quoteIdentifier(column, engine)
+		} else {
+			quoted[i] = column
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,165 @@
+package transport
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Notifications about resources sent to clients
+const (
+	resourceUpdated     = "notifications/resources/updated"
+	resourceListChanged = "notifications/resources/list_changed"
+)
+
+// subscriptions records the resources each client session subscribed to,
+// and the sessions that listed resources and so care about the list
+// changing. mcp-go does not implement resources/subscribe, so the
+// transports answer it themselves, see rewriteSubscription.
+var subscriptions = struct {
+	sync.Mutex
+	uris    map[string]map[string]bool
+	listing map[string]bool
+}{
+	uris:    make(map[string]map[string]bool),
+	listing: make(map[string]bool),
+}
+
+// resourceMessage holds the fields of the resource requests the transports
+// look at
+type resourceMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  struct {
+		URI string `json:"uri"`
+	} `json:"params"`
+}
+
+// rewriteSubscription records resources/subscribe and resources/unsubscribe
+// requests and turns them into a ping with the same id, which mcp-go
+// answers with the empty result the client expects. Other messages are
+// returned unchanged.
+func rewriteSubscription(session string, raw []byte) []byte {
+	var msg resourceMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return raw
+	}
+
+	// Messages of sessions that already ended are not recorded
+	senders.Lock()
+	_, connected := senders.sessions[session]
+	senders.Unlock()
+	if !connected {
+		return raw
+	}
+
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+
+	switch msg.Method {
+	case "resources/list":
+		subscriptions.listing[session] = true
+		return raw
+	case "resources/subscribe":
+		if subscriptions.uris[session] == nil {
+			subscriptions.uris[session] = make(map[string]bool)
+		}
+		subscriptions.uris[session][msg.Params.URI] = true
+	case "resources/unsubscribe":
+		delete(subscriptions.uris[session], msg.Params.URI)
+	default:
+		return raw
+	}
+
+	ping, err := json.Marshal(map[string]any{"jsonrpc": msg.JSONRPC, "id": msg.ID, "method": "ping"})
+	if err != nil {
+		return raw
+	}
+	return ping
+}
+
+// senders holds a function per connected client session that writes a
+// notification straight to the session's transport. Notifications are not
+// sent through mcp-go, which delivers them to whichever client made the
+// latest request.
+var senders = struct {
+	sync.Mutex
+	sessions map[string]func(notification mcp.JSONRPCNotification) error
+}{sessions: make(map[string]func(notification mcp.JSONRPCNotification) error)}
+
+// startSession registers the notification sender of a client session
+func startSession(session string, send func(notification mcp.JSONRPCNotification) error) {
+	senders.Lock()
+	defer senders.Unlock()
+	senders.sessions[session] = send
+}
+
+// endSession forgets a client session that disconnected, together with its
+// resource subscriptions
+func endSession(session string) {
+	senders.Lock()
+	delete(senders.sessions, session)
+	senders.Unlock()
+
+	subscriptions.Lock()
+	delete(subscriptions.uris, session)
+	delete(subscriptions.listing, session)
+	subscriptions.Unlock()
+}
+
+// notify sends a notification to one client session, if it is still
+// connected
+func notify(session string, method string, params map[string]any) {
+	senders.Lock()
+	send, ok := senders.sessions[session]
+	senders.Unlock()
+	if !ok {
+		return
+	}
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: method,
+			Params: mcp.NotificationParams{AdditionalFields: params},
+		},
+	}
+	if err := send(notification); err != nil {
+		log.Printf("Failed to send %s to session %s: %v", method, session, err)
+	}
+}
+
+// NotifyResourceUpdated tells the sessions subscribed to a resource that it
+// changed
+func NotifyResourceUpdated(uri string) {
+	subscriptions.Lock()
+	var sessions []string
+	for session, uris := range subscriptions.uris {
+		if uris[uri] {
+			sessions = append(sessions, session)
+		}
+	}
+	subscriptions.Unlock()
+
+	for _, session := range sessions {
+		notify(session, resourceUpdated, map[string]any{"uri": uri})
+	}
+}
+
+// NotifyResourceListChanged tells the sessions that listed resources that
+// resources were added or removed
+func NotifyResourceListChanged() {
+	subscriptions.Lock()
+	var sessions []string
+	for session := range subscriptions.listing {
+		sessions = append(sessions, session)
+	}
+	subscriptions.Unlock()
+
+	for _, session := range sessions {
+		notify(session, resourceListChanged, nil)
+	}
+}
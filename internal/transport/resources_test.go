@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSSESubscriptionNotifications(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(true, true))
+	sseServer := NewSSEServer(mcpServer)
+	httpServer := httptest.NewServer(sseServer)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/sse", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("opening the event stream failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	events := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				events <- data
+			}
+		}
+		close(events)
+	}()
+	next := func() string {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for an event")
+			return ""
+		}
+	}
+
+	endpoint, err := url.Parse(strings.TrimSpace(next()))
+	if err != nil {
+		t.Fatalf("invalid endpoint: %v", err)
+	}
+	session := endpoint.Query().Get("sessionId")
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"db://main"}}`
+	post, err := http.Post(httpServer.URL+endpoint.RequestURI(), "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("subscribing failed: %v", err)
+	}
+	post.Body.Close()
+	if event := next(); !strings.Contains(event, `"id":1`) || !strings.Contains(event, `"result"`) {
+		t.Fatalf("subscribe answered with %s", event)
+	}
+
+	NotifyResourceUpdated("db://other")
+	NotifyResourceUpdated("db://main")
+	if event := next(); !strings.Contains(event, resourceUpdated) || !strings.Contains(event, `"uri":"db://main"`) {
+		t.Errorf("got %s, want an update of db://main", event)
+	}
+
+	// Closing the stream ends the session and drops its subscriptions
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		subscriptions.Lock()
+		_, subscribed := subscriptions.uris[session]
+		subscriptions.Unlock()
+		if !subscribed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriptions of session %s were kept after it disconnected", session)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
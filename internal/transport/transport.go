@@ -0,0 +1,374 @@
+// Package transport serves the MCP server over stdio or SSE. Unlike the
+// plain mcp-go transports it honours notifications/cancelled: the context
+// of the tools/call request named by the notification is cancelled, so
+// database drivers abort the statement the tool is running. It also keeps
+// track of resource subscriptions, which mcp-go does not implement.
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioSession is the session name of the single stdio client
+const stdioSession = "stdio"
+
+// stdioKey marks contexts of the stdio transport, see RequestContext
+type stdioKey struct{}
+
+// requestKey identifies an in-flight request of a client session
+type requestKey struct {
+	session string
+	id      string
+}
+
+// inFlight holds the cancel functions of the tools/call requests being served
+var inFlight = struct {
+	sync.Mutex
+	cancels map[requestKey]context.CancelFunc
+}{cancels: make(map[requestKey]context.CancelFunc)}
+
+// message holds the fields of a JSON-RPC message the transports look at
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	} `json:"params"`
+}
+
+// inspect acts on a cancellation notification, reporting that raw was one,
+// and returns the id of a tools/call request, or an empty string for any
+// other message
+func inspect(session string, raw []byte) (id string, cancellation bool) {
+	var msg message
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return "", false
+	}
+
+	switch msg.Method {
+	case "notifications/cancelled":
+		cancelRequest(session, normalizeID(msg.Params.RequestID))
+		return "", true
+	case "tools/call":
+		return normalizeID(msg.ID), false
+	}
+
+	return "", false
+}
+
+// normalizeID renders a JSON-RPC id as compact JSON, keeping 1 and "1" apart
+func normalizeID(id json.RawMessage) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, id); err != nil {
+		return ""
+	}
+	return compact.String()
+}
+
+// track returns a context for a request that cancelRequest can cancel,
+// and a function that stops tracking it
+func track(parent context.Context, session string, id string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	key := requestKey{session: session, id: id}
+
+	inFlight.Lock()
+	inFlight.cancels[key] = cancel
+	inFlight.Unlock()
+
+	return ctx, func() {
+		inFlight.Lock()
+		delete(inFlight.cancels, key)
+		inFlight.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest cancels the context of an in-flight request, if any
+func cancelRequest(session string, id string) {
+	inFlight.Lock()
+	cancel, ok := inFlight.cancels[requestKey{session: session, id: id}]
+	inFlight.Unlock()
+
+	if ok {
+		log.Printf("Cancelling request %s of session %s", id, session)
+		cancel()
+	}
+}
+
+// RequestContext returns the context a tool handler should run its work
+// in. The stdio transport hands every handler the same context, so the
+// cancellable context of the request being served is looked up instead.
+func RequestContext(ctx context.Context) context.Context {
+	if reader, ok := ctx.Value(stdioKey{}).(*stdioReader); ok {
+		if current := reader.currentContext(); current != nil {
+			return current
+		}
+	}
+	return ctx
+}
+
+// stdioReader feeds stdin to the mcp-go stdio server one message at a
+// time. Stdin itself is read ahead in the background so that cancellation
+// notifications are seen while the server is busy with a request.
+type stdioReader struct {
+	ctx   context.Context
+	lines chan []byte
+	err   error
+
+	// pending is the unread rest of the message being delivered
+	pending []byte
+
+	mu      sync.Mutex
+	current context.Context
+	done    context.CancelFunc
+}
+
+// newStdioReader starts reading messages from in
+func newStdioReader(ctx context.Context, in io.Reader) *stdioReader {
+	r := &stdioReader{
+		ctx:   ctx,
+		lines: make(chan []byte, 64),
+	}
+
+	go func() {
+		defer close(r.lines)
+
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				// Cancellations are acted on here, while the server may be
+				// busy, and not passed on
+				if _, cancellation := inspect(stdioSession, line); !cancellation {
+					line = rewriteSubscription(stdioSession, line)
+					if line[len(line)-1] != '\n' {
+						line = append(line, '\n')
+					}
+					r.lines <- line
+				}
+			}
+			if err != nil {
+				r.err = err
+				return
+			}
+		}
+	}()
+
+	return r
+}
+
+// Read implements io.Reader. The stdio server reads the next message only
+// after it has answered the previous one, so a read that starts a new
+// message also marks the request being served.
+func (r *stdioReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		r.setCurrent(nil, nil)
+
+		line, ok := <-r.lines
+		if !ok {
+			if r.err != nil && r.err != io.EOF {
+				return 0, r.err
+			}
+			return 0, io.EOF
+		}
+
+		if id, _ := inspect(stdioSession, line); id != "" {
+			r.setCurrent(track(r.ctx, stdioSession, id))
+		}
+		r.pending = line
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// setCurrent replaces the context of the request being served
+func (r *stdioReader) setCurrent(ctx context.Context, done context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done != nil {
+		r.done()
+	}
+	r.current, r.done = ctx, done
+}
+
+// currentContext returns the context of the request being served
+func (r *stdioReader) currentContext() context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// ServeStdio serves mcpServer on stdin and stdout until stdin is closed or
+// the process receives SIGTERM or SIGINT
+func ServeStdio(mcpServer *server.MCPServer) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Set up signal handling
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	reader := newStdioReader(ctx, os.Stdin)
+
+	// Notifications are written to stdout between the server's responses
+	stdout := &lockedWriter{w: os.Stdout}
+	startSession(stdioSession, func(notification mcp.JSONRPCNotification) error {
+		data, err := json.Marshal(notification)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(append(data, '\n'))
+		return err
+	})
+	defer endSession(stdioSession)
+
+	stdioServer := server.NewStdioServer(mcpServer)
+	stdioServer.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+	stdioServer.SetContextFunc(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, stdioKey{}, reader)
+	})
+
+	return stdioServer.Listen(ctx, reader, stdout)
+}
+
+// lockedWriter serializes writes to w, so that messages written with a
+// single Write each are never interleaved
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write implements io.Writer
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// SSEServer serves an MCP server over SSE. It wraps the mcp-go SSE server
+// to keep track of the client sessions it serves, so that notifications
+// can be sent to a single session and its resource subscriptions dropped
+// when it disconnects.
+type SSEServer struct {
+	*server.SSEServer
+
+	httpServer *http.Server
+}
+
+// NewSSEServer creates an SSE server for mcpServer whose tools/call
+// requests can be cancelled by the client
+func NewSSEServer(mcpServer *server.MCPServer, opts ...server.SSEOption) *SSEServer {
+	opts = append(opts, server.WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			return ctx
+		}
+
+		// Put the body back for the SSE server to decode, with resource
+		// subscriptions turned into pings
+		session := r.URL.Query().Get("sessionId")
+		body = rewriteSubscription(session, body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if id, _ := inspect(session, body); id != "" {
+			// Every POST is served by its own handler, whose context ends
+			// when the response has been written
+			ctx, done := track(ctx, session, id)
+			context.AfterFunc(ctx, done)
+			return ctx
+		}
+
+		return ctx
+	}))
+
+	return &SSEServer{SSEServer: server.NewSSEServer(mcpServer, opts...)}
+}
+
+// ServeHTTP implements http.Handler. The event stream of a session opens
+// with an endpoint event naming the session; the session is registered
+// once that event is written and forgotten when the stream ends.
+func (s *SSEServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if r.Method != http.MethodGet || !ok {
+		s.SSEServer.ServeHTTP(w, r)
+		return
+	}
+
+	stream := &sseStream{ResponseWriter: w, flusher: flusher, server: s.SSEServer}
+	defer func() {
+		if stream.session != "" {
+			endSession(stream.session)
+		}
+	}()
+	s.SSEServer.ServeHTTP(stream, r)
+}
+
+// Start serves SSE connections on addr
+func (s *SSEServer) Start(addr string) error {
+	s.httpServer = &http.Server{Addr: addr, Handler: s}
+	return s.httpServer.ListenAndServe()
+}
+
+// Shutdown stops the server, closing the event streams of all sessions
+func (s *SSEServer) Shutdown(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+// sseStream is the response writer of a session's event stream. It picks
+// the session ID out of the endpoint event that starts the stream.
+type sseStream struct {
+	http.ResponseWriter
+	flusher http.Flusher
+	server  *server.SSEServer
+
+	session string
+}
+
+// Write implements http.ResponseWriter
+func (s *sseStream) Write(p []byte) (int, error) {
+	if s.session == "" && bytes.HasPrefix(p, []byte("event: endpoint\n")) {
+		_, endpoint, _ := strings.Cut(string(p), "data: ")
+		if endpointURL, err := url.Parse(strings.TrimSpace(endpoint)); err == nil {
+			if session := endpointURL.Query().Get("sessionId"); session != "" {
+				s.session = session
+				startSession(session, func(notification mcp.JSONRPCNotification) error {
+					return s.server.SendEventToSession(session, notification)
+				})
+			}
+		}
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher
+func (s *sseStream) Flush() {
+	s.flusher.Flush()
+}
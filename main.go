@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/anhnt2003/mcp-tool-kit/internal/tools"
+	"github.com/anhnt2003/mcp-tool-kit/internal/transport"
 	"github.com/joho/godotenv"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -34,6 +35,12 @@ func registerTools(mcpServer *server.MCPServer) {
 			tools.NewJiraTool(mcpServer)
 		case "sql-server":
 			tools.NewSQLServerTool(mcpServer)
+		case "postgres":
+			tools.NewPostgresTool(mcpServer)
+		case "mysql":
+			tools.NewMySQLTool(mcpServer)
+		case "sqlite":
+			tools.NewSQLiteTool(mcpServer)
 		default:
 			log.Printf("Unknown tool configuration: %s", tool)
 		}
@@ -71,13 +78,13 @@ func main() {
 	case "stdio":
 		// Run in stdio mode
 		log.Println("Starting in stdio mode")
-		err := server.ServeStdio(mcpServer)
+		err := transport.ServeStdio(mcpServer)
 		if err != nil {
 			log.Fatalf("Failed to start stdio server: %v", err)
 		}
 	case "sse":
 		// Create a new SSE server instance
-		sseServer := server.NewSSEServer(
+		sseServer := transport.NewSSEServer(
 			mcpServer, 
 			server.WithBaseURL("http://localhost:8080"),
 			server.WithMessageEndpoint("/message"),